@@ -0,0 +1,194 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Shape is a filled 2D shape for Arrange and Flatten.
+type Shape struct {
+	// Contours holds x, y pairs for each contour of the shape.
+	Contours [][]float32
+	// WindingRule decides which parts of the plane the contours fill.
+	WindingRule WindingRule
+}
+
+// Region is a part of the plane covered by the same shapes.
+type Region struct {
+	// Shapes lists the indices of the covering shapes in ascending order.
+	// Later shapes are stacked on top of earlier ones.
+	Shapes []int
+	// Outlines are the boundary contours of the region as x, y pairs, with
+	// outer contours counter-clockwise and holes clockwise.
+	Outlines [][]float32
+	// Vertices holds the 2D vertices of the region's triangles.
+	Vertices []float32
+	// Indices holds three vertex indices per triangle.
+	Indices []int
+}
+
+// Top returns the topmost shape covering the region.
+func (r *Region) Top() int {
+	return r.Shapes[len(r.Shapes)-1]
+}
+
+// Arrange splits overlapping shapes into non-overlapping regions, one for
+// each set of shapes that covers some part of the plane. Each shape is
+// first resolved with its own winding rule, and all shapes are then swept
+// together so that every output triangle lies inside or outside each shape.
+// Regions are sorted by their Shapes.
+func Arrange(shapes []Shape) ([]Region, error) {
+	return arrange(shapes, false)
+}
+
+// Flatten splits overlapping shapes like Arrange, but keeps only the
+// topmost shape covering each part of the plane, as when flattening a stack
+// of opaque fills. Shapes holds that single shape, so a shape hidden
+// entirely by later ones has no region.
+func Flatten(shapes []Shape) ([]Region, error) {
+	return arrange(shapes, true)
+}
+
+// arrangeShape is a shape resolved to boundary rings with winding 1 inside.
+type arrangeShape struct {
+	rings  [][]vec2
+	bounds bounds64
+}
+
+// covers reports whether p lies inside the shape.
+func (s *arrangeShape) covers(p vec2) bool {
+	if p.x < s.bounds.minX || p.x > s.bounds.maxX || p.y < s.bounds.minY || p.y > s.bounds.maxY {
+		return false
+	}
+	w := 0
+	for _, ring := range s.rings {
+		w += ringWinding(ring, p)
+	}
+	return w > 0
+}
+
+func arrange(shapes []Shape, topmost bool) ([]Region, error) {
+	resolved := make([]arrangeShape, len(shapes))
+	var all [][]float32
+	for i, s := range shapes {
+		for k, c := range s.Contours {
+			if len(c)%2 != 0 {
+				return nil, fmt.Errorf("shape %d: contour %d has an odd number of coordinates", i, k)
+			}
+		}
+		rings, err := boundaryRings(s.Contours, s.WindingRule)
+		if err != nil {
+			return nil, fmt.Errorf("shape %d: %w", i, err)
+		}
+		b := bounds64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		for _, ring := range rings {
+			pts := polylinePoints(ring, true)
+			for _, p := range pts {
+				b.minX, b.minY = math.Min(b.minX, p.x), math.Min(b.minY, p.y)
+				b.maxX, b.maxY = math.Max(b.maxX, p.x), math.Max(b.maxY, p.y)
+			}
+			resolved[i].rings = append(resolved[i].rings, pts)
+		}
+		resolved[i].bounds = b
+		all = append(all, rings...)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	t := NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	// The resolved rings have winding 1 inside their shape, so
+	// WindingPositive keeps every part of the plane covered by some shape.
+	for _, ring := range all {
+		if err := t.AddContour(2, ring); err != nil {
+			return nil, err
+		}
+	}
+	vertices, indices, err := t.Tessellate(WindingPositive, ElementPolygons, 3, 2, []float32{0, 0, 1})
+	if err != nil {
+		return nil, err
+	}
+
+	// Group triangles by the shapes covering their centroids.
+	var regions []Region
+	byKey := make(map[string]int)
+	var remaps []map[int]int
+	for e := 0; e+3 <= len(indices); e += 3 {
+		tri := indices[e : e+3]
+		var c vec2
+		for _, v := range tri {
+			c = c.add(vec2{float64(vertices[2*v]), float64(vertices[2*v+1])})
+		}
+		c = c.scale(1.0 / 3)
+
+		var covering []int
+		for i := len(resolved) - 1; i >= 0; i-- {
+			if resolved[i].covers(c) {
+				covering = append(covering, i)
+				if topmost {
+					break
+				}
+			}
+		}
+		if len(covering) == 0 {
+			// A sliver whose centroid falls outside every shape.
+			continue
+		}
+		slices.Reverse(covering)
+
+		key := fmt.Sprint(covering)
+		r, ok := byKey[key]
+		if !ok {
+			r = len(regions)
+			byKey[key] = r
+			regions = append(regions, Region{Shapes: covering})
+			remaps = append(remaps, make(map[int]int))
+		}
+		for _, v := range tri {
+			n, ok := remaps[r][v]
+			if !ok {
+				n = len(regions[r].Vertices) / 2
+				remaps[r][v] = n
+				regions[r].Vertices = append(regions[r].Vertices, vertices[2*v], vertices[2*v+1])
+			}
+			regions[r].Indices = append(regions[r].Indices, n)
+		}
+	}
+
+	// The triangles of a region do not overlap, so their union under
+	// WindingNonZero traces the region's outline.
+	for i := range regions {
+		r := &regions[i]
+		for e := 0; e+3 <= len(r.Indices); e += 3 {
+			tri := make([]float32, 0, 6)
+			for _, v := range r.Indices[e : e+3] {
+				tri = append(tri, r.Vertices[2*v], r.Vertices[2*v+1])
+			}
+			if err := t.AddContour(2, tri); err != nil {
+				return nil, err
+			}
+		}
+		outVerts, elements, err := t.Tessellate(WindingNonZero, ElementBoundaryContours, 0, 2, []float32{0, 0, 1})
+		if err != nil {
+			return nil, fmt.Errorf("region %v: %w", r.Shapes, err)
+		}
+		for k := 0; k+1 < len(elements); k += 2 {
+			base, count := elements[k], elements[k+1]
+			if count < 3 {
+				continue
+			}
+			r.Outlines = append(r.Outlines, outVerts[2*base:2*(base+count)])
+		}
+	}
+
+	slices.SortFunc(regions, func(a, b Region) int {
+		return slices.Compare(a.Shapes, b.Shapes)
+	})
+	return regions, nil
+}
@@ -0,0 +1,151 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// arrangeFixture returns three overlapping shapes on an integer grid and a
+// function reporting whether shape i covers the unit cell at (x, y).
+func arrangeFixture() ([]Shape, func(i, x, y int) bool) {
+	shapes := []Shape{
+		// A square with a hole, resolved with the odd rule.
+		{Contours: [][]float32{{0, 0, 4, 0, 4, 4, 0, 4}, {1, 1, 2, 1, 2, 2, 1, 2}}, WindingRule: WindingOdd},
+		// A clockwise square.
+		{Contours: [][]float32{{2, 2, 2, 6, 6, 6, 6, 2}}, WindingRule: WindingNonZero},
+		{Contours: [][]float32{{3, -1, 5, -1, 5, 3, 3, 3}}, WindingRule: WindingPositive},
+	}
+	rects := [][4]int{{0, 0, 4, 4}, {2, 2, 6, 6}, {3, -1, 5, 3}}
+	covers := func(i, x, y int) bool {
+		b := rects[i]
+		if i == 0 && x == 1 && y == 1 {
+			return false
+		}
+		return x >= b[0] && x < b[2] && y >= b[1] && y < b[3]
+	}
+	return shapes, covers
+}
+
+// TestArrange tests regions against the covering shapes of every grid cell
+func TestArrange(t *testing.T) {
+	shapes, covers := arrangeFixture()
+	regions, err := Arrange(shapes)
+	if err != nil {
+		t.Fatalf("Arrange failed: %v", err)
+	}
+
+	// Count the cells covered by each set of shapes.
+	want := make(map[string]float64)
+	for x := -1; x < 7; x++ {
+		for y := -2; y < 7; y++ {
+			var set []int
+			for i := range shapes {
+				if covers(i, x, y) {
+					set = append(set, i)
+				}
+			}
+			if set != nil {
+				want[fmt.Sprint(set)]++
+			}
+		}
+	}
+
+	if len(regions) != len(want) {
+		t.Errorf("Expected %d regions, got %d", len(want), len(regions))
+	}
+	for i, r := range regions {
+		if i > 0 && slices.Compare(regions[i-1].Shapes, r.Shapes) >= 0 {
+			t.Errorf("Regions %v and %v are not sorted", regions[i-1].Shapes, r.Shapes)
+		}
+		area := math.Abs(triangleArea(r.Vertices, r.Indices))
+		if w := want[fmt.Sprint(r.Shapes)]; math.Abs(area-w) > 1e-4 {
+			t.Errorf("Region %v: expected area %v, got %v", r.Shapes, w, area)
+		}
+		if outline := totalArea(r.Outlines); math.Abs(outline-area) > 1e-4 {
+			t.Errorf("Region %v: outline area %v differs from triangle area %v", r.Shapes, outline, area)
+		}
+		if r.Top() != r.Shapes[len(r.Shapes)-1] {
+			t.Errorf("Region %v: unexpected top %d", r.Shapes, r.Top())
+		}
+	}
+}
+
+// TestFlatten tests regions keyed by the topmost covering shape
+func TestFlatten(t *testing.T) {
+	shapes, covers := arrangeFixture()
+	regions, err := Flatten(shapes)
+	if err != nil {
+		t.Fatalf("Flatten failed: %v", err)
+	}
+	want := make([]float64, len(shapes))
+	for x := -1; x < 7; x++ {
+		for y := -2; y < 7; y++ {
+			for i := len(shapes) - 1; i >= 0; i-- {
+				if covers(i, x, y) {
+					want[i]++
+					break
+				}
+			}
+		}
+	}
+	if len(regions) != len(shapes) {
+		t.Fatalf("Expected %d regions, got %d", len(shapes), len(regions))
+	}
+	for i, r := range regions {
+		if !reflect.DeepEqual(r.Shapes, []int{i}) {
+			t.Errorf("Region %d: expected shapes [%d], got %v", i, i, r.Shapes)
+		}
+		if area := math.Abs(triangleArea(r.Vertices, r.Indices)); math.Abs(area-want[i]) > 1e-4 {
+			t.Errorf("Region %d: expected area %v, got %v", i, want[i], area)
+		}
+	}
+
+	// A shape covering everything hides the others.
+	shapes = append(shapes, Shape{Contours: [][]float32{{-5, -5, 10, -5, 10, 10, -5, 10}}})
+	regions, err = Flatten(shapes)
+	if err != nil {
+		t.Fatalf("Flatten failed: %v", err)
+	}
+	if len(regions) != 1 || regions[0].Top() != 3 || len(regions[0].Outlines) != 1 {
+		t.Fatalf("Expected a single region for the top shape, got %v", regions)
+	}
+	if area := totalArea(regions[0].Outlines); math.Abs(area-225) > 1e-4 {
+		t.Errorf("Expected area 225, got %v", area)
+	}
+}
+
+// TestArrangeEdgeCases tests empty input, holes and invalid contours
+func TestArrangeEdgeCases(t *testing.T) {
+	regions, err := Arrange(nil)
+	if err != nil || regions != nil {
+		t.Errorf("Expected no regions for no shapes, got %v, %v", regions, err)
+	}
+	regions, err = Arrange([]Shape{{Contours: [][]float32{{0, 0, 1, 1, 2, 2}}}})
+	if err != nil || regions != nil {
+		t.Errorf("Expected no regions for a degenerate shape, got %v, %v", regions, err)
+	}
+
+	// A square around a smaller one leaves a region with a hole.
+	regions, err = Arrange([]Shape{
+		{Contours: [][]float32{{0, 0, 10, 0, 10, 10, 0, 10}}},
+		{Contours: [][]float32{{4, 4, 6, 4, 6, 6, 4, 6}}},
+	})
+	if err != nil {
+		t.Fatalf("Arrange failed: %v", err)
+	}
+	if len(regions) != 2 || len(regions[0].Outlines) != 2 || len(regions[1].Outlines) != 1 {
+		t.Fatalf("Expected a ring and a square, got %v", regions)
+	}
+	for _, ring := range regions[0].Outlines {
+		if a := ringArea(ring); a != 100 && a != -4 {
+			t.Errorf("Expected a counter-clockwise outer and clockwise hole, got area %v", a)
+		}
+	}
+
+	if _, err := Arrange([]Shape{{Contours: [][]float32{{0, 0, 1}}}}); err == nil {
+		t.Error("Expected error for an odd number of coordinates")
+	}
+}
@@ -0,0 +1,107 @@
+package tess
+
+import "fmt"
+
+// attribContour is a copy of an input contour kept for
+// OptionContourAttribution.
+type attribContour struct {
+	coords [][3]float32
+	// sign is -1 if the contour was added with OptionReverseContours.
+	sign int
+}
+
+func (t *Tessellator) recordContour(size int, vertices []float32) {
+	c := attribContour{coords: make([][3]float32, len(vertices)/size), sign: 1}
+	for i := range c.coords {
+		copy(c.coords[i][:], vertices[i*size:(i+1)*size])
+	}
+	if t.reverseContours {
+		c.sign = -1
+	}
+	t.attribContours = append(t.attribContours, c)
+}
+
+// attribute computes the contours enclosing each output polygon and its
+// winding number. The winding of every contour is measured around an
+// interior point of the polygon in the sweep plane, where libtess2 computes
+// windings, so the sum is the winding the rule was applied to.
+func (t *Tessellator) attribute(vertices []float32, vertexSize int, indices []int, elementType ElementType, polySize int) error {
+	if vertexSize < 3 {
+		for _, c := range t.attribContours {
+			for _, p := range c.coords {
+				if p[2] != 0 {
+					return fmt.Errorf("OptionContourAttribution needs vertexSize 3 for input with z coordinates")
+				}
+			}
+		}
+	}
+
+	r := &Result{Vertices: vertices, VertexSize: vertexSize, Projection: t.projection}
+	rings := make([][]vec2, len(t.attribContours))
+	for i, c := range t.attribContours {
+		rings[i] = make([]vec2, len(c.coords))
+		for k, p := range c.coords {
+			x, y := r.project(p[:])
+			rings[i][k] = vec2{x, y}
+		}
+	}
+
+	stride := polySize
+	if elementType == ElementConnectedPolygons {
+		stride = 2 * polySize
+	}
+	count := len(indices) / stride
+	t.contourIDs = make([][]int, count)
+	t.windings = make([]int, count)
+	for e := 0; e < count; e++ {
+		p, ok := r.polygonCentroid(indices[e*stride : e*stride+polySize])
+		if !ok {
+			continue
+		}
+		ids := []int{}
+		for i, ring := range rings {
+			if w := t.attribContours[i].sign * ringWinding(ring, p); w != 0 {
+				ids = append(ids, i)
+				t.windings[e] += w
+			}
+		}
+		t.contourIDs[e] = ids
+	}
+	return nil
+}
+
+// polygonCentroid returns the average of a polygon's vertices in the sweep
+// plane, which lies inside it since output polygons are convex.
+func (r *Result) polygonCentroid(poly []int) (vec2, bool) {
+	var c vec2
+	n := 0
+	for _, v := range poly {
+		if v == Undef {
+			break
+		}
+		x, y := r.project(r.Vertices[v*r.VertexSize : (v+1)*r.VertexSize])
+		c = c.add(vec2{x, y})
+		n++
+	}
+	if n == 0 {
+		return c, false
+	}
+	return c.scale(1 / float64(n)), true
+}
+
+// ringWinding returns the winding number of a closed ring around p.
+func ringWinding(ring []vec2, p vec2) int {
+	w := 0
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		side := b.sub(a).cross(p.sub(a))
+		if a.y <= p.y {
+			if b.y > p.y && side > 0 {
+				w++
+			}
+		} else if b.y <= p.y && side < 0 {
+			w--
+		}
+	}
+	return w
+}
@@ -0,0 +1,165 @@
+package tess
+
+import (
+	"reflect"
+	"testing"
+)
+
+func attributed(t *testing.T, size int, contours [][]float32, rule WindingRule, polySize, vertexSize int, normal []float32, options ...Option) *Result {
+	t.Helper()
+	tess := NewTessellator()
+	defer tess.Delete()
+	for _, o := range append([]Option{OptionContourAttribution}, options...) {
+		if err := tess.SetOption(o, true); err != nil {
+			t.Fatalf("SetOption failed: %v", err)
+		}
+	}
+	for _, c := range contours {
+		if err := tess.AddContour(size, c); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+	}
+	r, err := tess.TessellateResult(rule, ElementPolygons, polySize, vertexSize, normal)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	return r
+}
+
+// TestContourAttribution tests polygon labels against point in rectangle tests
+func TestContourAttribution(t *testing.T) {
+	rects := [][4]float32{{0, 0, 4, 4}, {2, 2, 6, 6}, {3, -1, 5, 3}, {1, 1, 2, 2}}
+	var contours [][]float32
+	for i, b := range rects {
+		c := []float32{b[0], b[1], b[2], b[1], b[2], b[3], b[0], b[3]}
+		if i == 3 {
+			// A clockwise hole in the first square.
+			c = []float32{b[0], b[1], b[0], b[3], b[2], b[3], b[2], b[1]}
+		}
+		contours = append(contours, c)
+	}
+	signs := []int{1, 1, 1, -1}
+
+	for _, rule := range []WindingRule{WindingOdd, WindingNonZero, WindingPositive, WindingAbsGeqTwo} {
+		for _, polySize := range []int{3, 6} {
+			r := attributed(t, 2, contours, rule, polySize, 2, nil)
+			if len(r.ContourIDs) != len(r.Indices)/polySize || len(r.Windings) != len(r.ContourIDs) {
+				t.Fatalf("%s: expected labels for %d polygons, got %d and %d", rule, len(r.Indices)/polySize, len(r.ContourIDs), len(r.Windings))
+			}
+			for e := range r.ContourIDs {
+				p, _ := r.polygonCentroid(r.Indices[e*polySize : (e+1)*polySize])
+				want := []int{}
+				winding := 0
+				for i, b := range rects {
+					if p.x > float64(b[0]) && p.x < float64(b[2]) && p.y > float64(b[1]) && p.y < float64(b[3]) {
+						want = append(want, i)
+						winding += signs[i]
+					}
+				}
+				if !reflect.DeepEqual(r.ContourIDs[e], want) {
+					t.Errorf("%s: polygon %d at (%.2f, %.2f): expected contours %v, got %v", rule, e, p.x, p.y, want, r.ContourIDs[e])
+				}
+				if r.Windings[e] != winding || !selected(rule, winding) {
+					t.Errorf("%s: polygon %d: expected selected winding %d, got %d", rule, e, winding, r.Windings[e])
+				}
+			}
+		}
+	}
+}
+
+// TestContourAttributionOrientation tests windings with reversed contours and 3D input
+func TestContourAttributionOrientation(t *testing.T) {
+	a := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+	b := []float32{2, 2, 6, 2, 6, 6, 2, 6}
+
+	// Reversed with a fixed normal, every winding is negative.
+	r := attributed(t, 2, [][]float32{a, b}, WindingNegative, 3, 2, []float32{0, 0, 1}, OptionReverseContours)
+	if len(r.Windings) == 0 {
+		t.Fatal("Expected polygons")
+	}
+	for e, w := range r.Windings {
+		if w != -1 && w != -2 {
+			t.Errorf("Polygon %d: expected winding -1 or -2, got %d", e, w)
+		}
+	}
+
+	// A computed normal follows the input orientation, so windings are
+	// positive even for clockwise input.
+	cw := []float32{0, 0, 0, 4, 4, 4, 4, 0}
+	r = attributed(t, 2, [][]float32{cw}, WindingPositive, 3, 2, nil)
+	for e, w := range r.Windings {
+		if w != 1 || !reflect.DeepEqual(r.ContourIDs[e], []int{0}) {
+			t.Errorf("Polygon %d: expected winding 1 from contour 0, got %d from %v", e, w, r.ContourIDs[e])
+		}
+	}
+
+	// 3D input in the plane z = y.
+	tilted := [][]float32{
+		{0, 0, 0, 4, 0, 0, 4, 4, 4, 0, 4, 4},
+		{2, 2, 2, 6, 2, 2, 6, 6, 6, 2, 6, 6},
+	}
+	r = attributed(t, 3, tilted, WindingAbsGeqTwo, 3, 3, nil)
+	if len(r.Windings) != 2 {
+		t.Errorf("Expected the 2x2 overlap as two triangles, got %d", len(r.Windings))
+	}
+	for e, w := range r.Windings {
+		if w != 2 || !reflect.DeepEqual(r.ContourIDs[e], []int{0, 1}) {
+			t.Errorf("Polygon %d: expected winding 2 from both contours, got %d from %v", e, w, r.ContourIDs[e])
+		}
+	}
+
+	tess := NewTessellator()
+	defer tess.Delete()
+	tess.SetOption(OptionContourAttribution, true)
+	tess.AddContour(3, tilted[0])
+	if _, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil); err == nil {
+		t.Error("Expected error for 2D output of 3D input")
+	}
+}
+
+// TestContourAttributionOption tests when labels are produced
+func TestContourAttributionOption(t *testing.T) {
+	square := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.AddContour(2, square); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil || r.ContourIDs != nil || r.Windings != nil {
+		t.Fatalf("Expected no labels without the option, got %v, %v", r, err)
+	}
+
+	// Enabling the option after adding contours is an error.
+	if err := tess.AddContour(2, square); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	tess.SetOption(OptionContourAttribution, true)
+	if err := tess.AddContour(2, square); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	if _, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil); err == nil {
+		t.Error("Expected error when the option is enabled after AddContour")
+	}
+
+	// Boundary contours are not labelled; connected polygons are.
+	tess = NewTessellator()
+	defer tess.Delete()
+	tess.SetOption(OptionContourAttribution, true)
+	for _, elementType := range []ElementType{ElementBoundaryContours, ElementConnectedPolygons} {
+		if err := tess.AddContour(2, square); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+		r, err := tess.TessellateResult(WindingOdd, elementType, 3, 2, nil)
+		if err != nil {
+			t.Fatalf("TessellateResult failed: %v", err)
+		}
+		if (r.ContourIDs == nil) != (elementType == ElementBoundaryContours) {
+			t.Errorf("%s: unexpected labels %v", elementType, r.ContourIDs)
+		}
+		if elementType == ElementConnectedPolygons && !reflect.DeepEqual(r.ContourIDs, [][]int{{0}, {0}}) {
+			t.Errorf("Expected both triangles in contour 0, got %v", r.ContourIDs)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package tess
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Cache stores tessellation results keyed by a hash of their input, so that
+// repeated shapes such as icons and map symbols are tessellated once. Entries
+// are evicted least recently used first once their total size exceeds the
+// byte limit. A Cache is safe for concurrent use.
+//
+// Results returned by a Cache are shared between callers and must not be
+// modified; see Tessellate.
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	lru      *list.List // of *cacheEntry, most recently used first
+	entries  map[cacheKey]*list.Element
+
+	hits, misses, evictions uint64
+}
+
+// CacheStats reports the activity of a Cache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Entries and Bytes describe the results currently held.
+	Entries int
+	Bytes   int64
+}
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key    cacheKey
+	result *Result
+	size   int64
+}
+
+// NewCache creates a cache holding results of up to maxBytes in total.
+func NewCache(maxBytes int64) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+// Tessellate returns the cached result for job, or runs it on t and caches
+// the result. The key covers the job and the Tessellator's simplification
+// and planarity settings. Failed tessellations are not cached.
+//
+// The returned Result is shared with every caller of the same job and must
+// not be modified, including by in-place methods such as Canonicalize. Call
+// Clone for a copy that may be modified.
+func (c *Cache) Tessellate(t *Tessellator, job *Job) (*Result, error) {
+	if t == nil || t.tess == nil {
+		return nil, fmt.Errorf("tessellator is nil or deleted")
+	}
+	key := jobKey(t, job)
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.hits++
+		r := e.Value.(*cacheEntry).result
+		c.mu.Unlock()
+		return r, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	r, err := job.Run(t)
+	if err != nil {
+		return nil, err
+	}
+	c.add(key, r)
+	return r, nil
+}
+
+func (c *Cache) add(key cacheKey, r *Result) {
+	size := resultSize(r)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if size > c.maxBytes {
+		return
+	}
+	if _, ok := c.entries[key]; ok {
+		// Another caller stored the same result meanwhile.
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, result: r, size: size})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		e := c.lru.Back().Value.(*cacheEntry)
+		c.lru.Remove(c.lru.Back())
+		delete(c.entries, e.key)
+		c.bytes -= e.size
+		c.evictions++
+	}
+}
+
+// Stats returns the cache statistics.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.lru.Len(),
+		Bytes:     c.bytes,
+	}
+}
+
+// Purge removes all entries. The statistics are kept.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[cacheKey]*list.Element)
+	c.bytes = 0
+}
+
+// resultOverhead approximates the memory used by a Result and its cache
+// entry apart from the slice contents.
+const resultOverhead = 256
+
+// resultSize approximates the memory held by r.
+func resultSize(r *Result) int64 {
+	size := resultOverhead + 4*int64(len(r.Vertices)+len(r.EdgeFlags)) + 8*int64(len(r.Indices)+len(r.VertexIndices)+len(r.Windings))
+	for _, ids := range r.ContourIDs {
+		size += 24 + 8*int64(len(ids))
+	}
+	return size
+}
+
+// jobKey hashes everything that determines the result of running job on t.
+func jobKey(t *Tessellator, job *Job) cacheKey {
+	h := sha256.New()
+	var buf [8]byte
+	putInt := func(v int) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	putFloat := func(f float32) {
+		binary.LittleEndian.PutUint32(buf[:4], math.Float32bits(f))
+		h.Write(buf[:4])
+	}
+
+	putInt(job.Size)
+	putInt(len(job.Contours))
+	for _, contour := range job.Contours {
+		putInt(len(contour))
+		for _, f := range contour {
+			putFloat(f)
+		}
+	}
+	putInt(int(job.WindingRule))
+	putInt(int(job.ElementType))
+	putInt(job.PolySize)
+	putInt(job.VertexSize)
+	if job.Normal == nil {
+		putInt(-1)
+	} else {
+		putInt(len(job.Normal))
+		for _, f := range job.Normal {
+			putFloat(f)
+		}
+	}
+	for _, o := range options {
+		if job.enabled(o) {
+			putInt(int(o))
+		}
+	}
+	putInt(-1)
+
+	putInt(int(t.simplifyMode))
+	putFloat(t.simplifyTolerance)
+	putFloat(t.cleanupEpsilon)
+	putFloat(t.planarityTolerance)
+	if t.strictPlanarity {
+		putInt(1)
+	} else {
+		putInt(0)
+	}
+
+	var key cacheKey
+	h.Sum(key[:0])
+	return key
+}
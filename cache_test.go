@@ -0,0 +1,262 @@
+package tess
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func squareJob(side float32) *Job {
+	return &Job{
+		Size:        2,
+		Contours:    [][]float32{{0, 0, side, 0, side, side, 0, side}},
+		WindingRule: WindingOdd,
+		ElementType: ElementPolygons,
+		PolySize:    3,
+		VertexSize:  2,
+	}
+}
+
+// TestJobRun tests that a job sets every option and parameter
+func TestJobRun(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+
+	job := squareJob(4)
+	job.Contours = append(job.Contours, []float32{1, 1, 3, 1, 3, 3, 1, 3})
+	job.WindingRule = WindingPositive
+	r, err := job.Run(tess)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// Both squares are counterclockwise, so the inner one has winding 2.
+	if len(r.Indices) != 10*3 {
+		t.Errorf("Expected 10 triangles, got %d indices", len(r.Indices))
+	}
+
+	// Reversing makes every winding negative. A computed normal would
+	// follow the reversal, so the normal is given.
+	job.Options = []Option{OptionReverseContours}
+	job.Normal = []float32{0, 0, 1}
+	if r, err = job.Run(tess); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(r.Indices) != 0 {
+		t.Errorf("Expected no triangles, got %d indices", len(r.Indices))
+	}
+
+	// Options not listed are switched off again. Seen from -Z the contours
+	// are clockwise.
+	job.Options = nil
+	job.Normal = []float32{0, 0, -1}
+	if r, err = job.Run(tess); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(r.Indices) != 0 {
+		t.Errorf("Expected no triangles, got %d indices", len(r.Indices))
+	}
+
+	// A nil normal is computed even after an explicit one.
+	job.Normal = nil
+	if r, err = job.Run(tess); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(r.Indices) != 10*3 || !r.Projection.Computed {
+		t.Errorf("Expected 10 triangles with a computed normal, got %d indices", len(r.Indices))
+	}
+
+	job.Size = 4
+	if _, err := job.Run(tess); err == nil {
+		t.Error("Expected error for invalid size")
+	}
+	if _, err := job.Run(nil); err == nil {
+		t.Error("Expected error for nil tessellator")
+	}
+}
+
+// TestCacheHits tests that identical jobs share one result
+func TestCacheHits(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	c := NewCache(1 << 20)
+
+	a, err := c.Tessellate(tess, squareJob(4))
+	if err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	b, err := c.Tessellate(tess, squareJob(4))
+	if err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	if a != b {
+		t.Error("Expected the cached result to be shared")
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 1 || s.Entries != 1 || s.Bytes != resultSize(a) {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	// Every parameter is part of the key.
+	variants := []func(j *Job){
+		func(j *Job) { j.Contours[0][0] = 1 },
+		func(j *Job) { j.Size = 3; j.Contours[0] = []float32{0, 0, 0, 4, 0, 0, 4, 4, 0, 0, 4, 0} },
+		func(j *Job) { j.Contours = append(j.Contours, []float32{1, 1, 2, 1, 2, 2}) },
+		func(j *Job) { j.WindingRule = WindingNonZero },
+		func(j *Job) { j.ElementType = ElementBoundaryContours },
+		func(j *Job) { j.PolySize = 4 },
+		func(j *Job) { j.VertexSize = 3 },
+		func(j *Job) { j.Normal = []float32{0, 0, 1} },
+		func(j *Job) { j.Options = []Option{OptionConstrainedDelaunay} },
+	}
+	for i, vary := range variants {
+		job := squareJob(4)
+		vary(job)
+		r, err := c.Tessellate(tess, job)
+		if err != nil {
+			t.Fatalf("Variant %d: Tessellate failed: %v", i, err)
+		}
+		if r == a {
+			t.Errorf("Variant %d: expected a separate result", i)
+		}
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != uint64(1+len(variants)) {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	// So are the Tessellator's own settings.
+	if err := tess.SetSimplify(SimplifyDouglasPeucker, 0.5); err != nil {
+		t.Fatalf("SetSimplify failed: %v", err)
+	}
+	if r, _ := c.Tessellate(tess, squareJob(4)); r == a {
+		t.Error("Expected simplification to change the key")
+	}
+
+	// Failures are not cached.
+	bad := squareJob(4)
+	bad.VertexSize = 5
+	for i := 0; i < 2; i++ {
+		if _, err := c.Tessellate(tess, bad); err == nil {
+			t.Error("Expected error for invalid vertex size")
+		}
+	}
+	if _, err := c.Tessellate(nil, squareJob(4)); err == nil {
+		t.Error("Expected error for nil tessellator")
+	}
+}
+
+// TestResultClone tests that a clone of a shared result may be modified
+func TestResultClone(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	c := NewCache(1 << 20)
+
+	job := squareJob(4)
+	job.Options = []Option{OptionEdgeFlags, OptionContourAttribution}
+	shared, err := c.Tessellate(tess, job)
+	if err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	vertex, id := shared.Vertices[0], shared.ContourIDs[0][0]
+
+	r := shared.Clone()
+	if r == shared || !reflect.DeepEqual(r, shared) {
+		t.Fatalf("Expected an equal copy, got %+v", r)
+	}
+	r.Canonicalize()
+	r.Vertices[0] = 42
+	r.ContourIDs[0][0] = 42
+	if got, _ := c.Tessellate(tess, job); got != shared || got.Vertices[0] != vertex || got.ContourIDs[0][0] != id {
+		t.Errorf("Expected the cached result to be unchanged, got %+v", got)
+	}
+}
+
+// TestCacheEviction tests least recently used eviction by size
+func TestCacheEviction(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+
+	r, err := squareJob(1).Run(tess)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	size := resultSize(r)
+	c := NewCache(3 * size)
+
+	get := func(side float32) *Result {
+		t.Helper()
+		r, err := c.Tessellate(tess, squareJob(side))
+		if err != nil {
+			t.Fatalf("Tessellate failed: %v", err)
+		}
+		return r
+	}
+	one := get(1)
+	get(2)
+	get(3)
+	get(1) // most recently used
+	get(4) // evicts 2
+	if s := c.Stats(); s.Entries != 3 || s.Evictions != 1 || s.Bytes != 3*size {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+	if get(1) != one {
+		t.Error("Expected the recently used entry to survive")
+	}
+	before := c.Stats().Misses
+	get(2)
+	if c.Stats().Misses != before+1 {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+
+	// Results larger than the whole cache are returned but not stored.
+	small := NewCache(size - 1)
+	if _, err := small.Tessellate(tess, squareJob(1)); err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	if s := small.Stats(); s.Entries != 0 || s.Bytes != 0 {
+		t.Errorf("Expected an empty cache, got %+v", s)
+	}
+
+	c.Purge()
+	if s := c.Stats(); s.Entries != 0 || s.Bytes != 0 || s.Hits == 0 {
+		t.Errorf("Expected an empty cache with statistics kept, got %+v", s)
+	}
+}
+
+// TestCacheConcurrent tests concurrent use of a cache with pooled tessellators
+func TestCacheConcurrent(t *testing.T) {
+	c := NewCache(1 << 20)
+	p := NewPool(4)
+	defer p.Close()
+
+	want, err := squareJob(3).Run(NewTessellator())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				tess, err := p.Get()
+				if err != nil {
+					t.Errorf("Get failed: %v", err)
+					return
+				}
+				r, err := c.Tessellate(tess, squareJob(float32(1+i%5)))
+				p.Put(tess)
+				if err != nil {
+					t.Errorf("Tessellate failed: %v", err)
+					return
+				}
+				if i%5 == 2 && !reflect.DeepEqual(r.Indices, want.Indices) {
+					t.Errorf("Unexpected indices %v", r.Indices)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s := c.Stats(); s.Entries != 5 || s.Hits+s.Misses != 400 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,187 @@
+package tess
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Canonicalize reorders the result so that its layout depends only on its
+// geometry, not on the order libtess2 happened to produce, so results can be
+// compared and hashed reliably:
+//
+//   - vertices are sorted by their coordinates, then by VertexIndices;
+//   - each polygon is rotated to start at its smallest vertex index, keeping
+//     its orientation, and polygons are sorted by their vertex indices;
+//   - boundary contours are rotated to start at their smallest vertex and
+//     sorted by their vertices, which are stored in that order.
+//
+// Neighbours, EdgeFlags, ContourIDs and Windings are reordered to match.
+// OptionCanonicalOrder applies Canonicalize to every tessellation.
+func (r *Result) Canonicalize() {
+	if r.VertexSize == 0 {
+		return
+	}
+	if r.ElementType == ElementBoundaryContours {
+		r.canonicalContours()
+	} else {
+		r.canonicalPolygons()
+	}
+}
+
+// compareVertices orders vertices a and b by coordinates, then by input
+// vertex.
+func (r *Result) compareVertices(a, b int) int {
+	size := r.VertexSize
+	if c := slices.Compare(r.Vertices[a*size:(a+1)*size], r.Vertices[b*size:(b+1)*size]); c != 0 {
+		return c
+	}
+	if a < len(r.VertexIndices) && b < len(r.VertexIndices) {
+		return cmp.Compare(r.VertexIndices[a], r.VertexIndices[b])
+	}
+	return 0
+}
+
+// permuteVertices stores the vertices in the given order.
+func (r *Result) permuteVertices(order []int) {
+	size := r.VertexSize
+	vertices := make([]float32, 0, len(r.Vertices))
+	for _, v := range order {
+		vertices = append(vertices, r.Vertices[v*size:(v+1)*size]...)
+	}
+	r.Vertices = vertices
+	if len(r.VertexIndices) == len(order) {
+		indices := make([]int, len(order))
+		for i, v := range order {
+			indices[i] = r.VertexIndices[v]
+		}
+		r.VertexIndices = indices
+	}
+}
+
+func (r *Result) canonicalPolygons() {
+	n := r.VertexCount()
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, r.compareVertices)
+	newVertex := make([]int, n)
+	for i, v := range order {
+		newVertex[v] = i
+	}
+	r.permuteVertices(order)
+
+	stride := r.PolySize
+	connected := r.ElementType == ElementConnectedPolygons
+	if connected {
+		stride *= 2
+	}
+	if stride == 0 {
+		return
+	}
+	count := len(r.Indices) / stride
+
+	// Renumber and rotate every polygon in place.
+	for e := 0; e < count; e++ {
+		poly := r.Indices[e*stride : e*stride+r.PolySize]
+		m := 0
+		for m < len(poly) && poly[m] != Undef {
+			poly[m] = newVertex[poly[m]]
+			m++
+		}
+		if m == 0 {
+			continue
+		}
+		k := 0
+		for i := 1; i < m; i++ {
+			if poly[i] < poly[k] {
+				k = i
+			}
+		}
+		if k == 0 {
+			continue
+		}
+		copy(poly[:m], slices.Concat(poly[k:m], poly[:k]))
+		if connected {
+			neighbours := r.Indices[e*stride+r.PolySize : (e+1)*stride]
+			copy(neighbours[:m], slices.Concat(neighbours[k:m], neighbours[:k]))
+		}
+		if e < len(r.EdgeFlags) {
+			mask := r.EdgeFlags[e]
+			r.EdgeFlags[e] = (mask>>k | mask<<(m-k)) & (1<<m - 1)
+		}
+	}
+
+	polys := make([]int, count)
+	for i := range polys {
+		polys[i] = i
+	}
+	slices.SortStableFunc(polys, func(a, b int) int {
+		return slices.Compare(r.Indices[a*stride:a*stride+r.PolySize], r.Indices[b*stride:b*stride+r.PolySize])
+	})
+	newPoly := make([]int, count)
+	for i, e := range polys {
+		newPoly[e] = i
+	}
+
+	indices := make([]int, 0, len(r.Indices))
+	for _, e := range polys {
+		indices = append(indices, r.Indices[e*stride:(e+1)*stride]...)
+		if connected {
+			for i := len(indices) - r.PolySize; i < len(indices); i++ {
+				if indices[i] != Undef {
+					indices[i] = newPoly[indices[i]]
+				}
+			}
+		}
+	}
+	r.Indices = indices
+	r.EdgeFlags = permute(r.EdgeFlags, polys)
+	r.ContourIDs = permute(r.ContourIDs, polys)
+	r.Windings = permute(r.Windings, polys)
+}
+
+func (r *Result) canonicalContours() {
+	contours := make([][]int, 0, len(r.Indices)/2)
+	for i := 0; i+1 < len(r.Indices); i += 2 {
+		base, count := r.Indices[i], r.Indices[i+1]
+		c := make([]int, count)
+		k := 0
+		for j := range c {
+			c[j] = base + j
+			if r.compareVertices(base+j, base+k) < 0 {
+				k = j
+			}
+		}
+		contours = append(contours, slices.Concat(c[k:], c[:k]))
+	}
+	slices.SortStableFunc(contours, func(a, b []int) int {
+		for j := 0; j < len(a) && j < len(b); j++ {
+			if c := r.compareVertices(a[j], b[j]); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(len(a), len(b))
+	})
+
+	var order []int
+	indices := make([]int, 0, len(r.Indices))
+	for _, c := range contours {
+		indices = append(indices, len(order), len(c))
+		order = append(order, c...)
+	}
+	r.permuteVertices(order)
+	r.Indices = indices
+}
+
+// permute returns s in the given order, or s itself if it is nil.
+func permute[T any](s []T, order []int) []T {
+	if s == nil {
+		return nil
+	}
+	p := make([]T, len(order))
+	for i, e := range order {
+		p[i] = s[e]
+	}
+	return p
+}
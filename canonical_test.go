@@ -0,0 +1,238 @@
+package tess
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// polygonKeys describes each polygon by its vertex coordinates and edge
+// flags, rotated to start at the smallest vertex, together with a label.
+func polygonKeys(r *Result, label func(e int) string) []string {
+	stride := r.PolySize
+	if r.ElementType == ElementConnectedPolygons {
+		stride *= 2
+	}
+	var keys []string
+	for e := 0; e*stride < len(r.Indices); e++ {
+		var coords []string
+		for k, v := range r.Indices[e*stride : e*stride+r.PolySize] {
+			if v == Undef {
+				break
+			}
+			coords = append(coords, fmt.Sprint(r.Vertices[v*r.VertexSize:(v+1)*r.VertexSize], r.IsBoundaryEdge(e, k)))
+		}
+		k := 0
+		for i := range coords {
+			if coords[i] < coords[k] {
+				k = i
+			}
+		}
+		keys = append(keys, fmt.Sprint(slices.Concat(coords[k:], coords[:k]), label(e)))
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func canonicalContours() [][]float32 {
+	return [][]float32{
+		{0, 0, 10, 0, 10, 10, 0, 10},
+		{4, 4, 6, 4, 6, 6, 4, 6},
+		{0, 3, 6, 3, 1, 0, 3, 5, 5, 0},
+	}
+}
+
+// TestCanonicalize tests the canonical layout and that geometry is preserved
+func TestCanonicalize(t *testing.T) {
+	for _, elementType := range []ElementType{ElementPolygons, ElementConnectedPolygons} {
+		for _, polySize := range []int{3, 6} {
+			tess := NewTessellator()
+			tess.SetOption(OptionEdgeFlags, true)
+			tess.SetOption(OptionContourAttribution, true)
+			for _, c := range canonicalContours() {
+				tess.AddContour(2, c)
+			}
+			r, err := tess.TessellateResult(WindingNonZero, elementType, polySize, 2, nil)
+			tess.Delete()
+			if err != nil {
+				t.Fatalf("TessellateResult failed: %v", err)
+			}
+
+			label := func(r *Result) func(int) string {
+				return func(e int) string {
+					return fmt.Sprint(r.ContourIDs[e], r.Windings[e])
+				}
+			}
+			before := polygonKeys(r, label(r))
+			c := *r
+			c.Indices = slices.Clone(r.Indices)
+			c.EdgeFlags = slices.Clone(r.EdgeFlags)
+			c.Canonicalize()
+			if after := polygonKeys(&c, label(&c)); !reflect.DeepEqual(before, after) {
+				t.Errorf("%s/%d: polygons changed\n%v\n%v", elementType, polySize, before, after)
+			}
+
+			for v := 1; v < c.VertexCount(); v++ {
+				if slices.Compare(c.Vertices[2*v-2:2*v], c.Vertices[2*v:2*v+2]) > 0 {
+					t.Errorf("%s/%d: vertices %d and %d are not sorted", elementType, polySize, v-1, v)
+				}
+			}
+			for v, src := range c.VertexIndices {
+				i := slices.Index(r.VertexIndices, src)
+				if src != Undef && !slices.Equal(c.Vertices[2*v:2*v+2], r.Vertices[2*i:2*i+2]) {
+					t.Errorf("%s/%d: vertex %d moved away from input vertex %d", elementType, polySize, v, src)
+				}
+			}
+
+			stride := polySize
+			if elementType == ElementConnectedPolygons {
+				stride *= 2
+			}
+			for e := 0; e*stride < len(c.Indices); e++ {
+				poly := c.Indices[e*stride : e*stride+polySize]
+				if e > 0 && slices.Compare(c.Indices[(e-1)*stride:(e-1)*stride+polySize], poly) > 0 {
+					t.Errorf("%s/%d: polygons %d and %d are not sorted", elementType, polySize, e-1, e)
+				}
+				for _, v := range poly[1:] {
+					if v != Undef && v < poly[0] {
+						t.Errorf("%s/%d: polygon %v does not start at its smallest vertex", elementType, polySize, poly)
+					}
+				}
+				if elementType != ElementConnectedPolygons {
+					continue
+				}
+				// The neighbour across edge k shares its two vertices.
+				n := slices.Index(poly, Undef)
+				if n < 0 {
+					n = polySize
+				}
+				for k, nb := range c.Indices[e*stride+polySize : e*stride+polySize+n] {
+					if nb == Undef {
+						continue
+					}
+					other := c.Indices[nb*stride : nb*stride+polySize]
+					if !slices.Contains(other, poly[k]) || !slices.Contains(other, poly[(k+1)%n]) {
+						t.Errorf("%s/%d: polygon %d neighbour %d does not share edge %d", elementType, polySize, e, nb, k)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestCanonicalizeInputOrder tests that reordered input gives identical output
+func TestCanonicalizeInputOrder(t *testing.T) {
+	contours := canonicalContours()
+	reordered := [][]float32{
+		contours[2],
+		// The same square, starting at another corner.
+		{10, 10, 0, 10, 0, 0, 10, 0},
+		contours[1],
+	}
+
+	for _, elementType := range []ElementType{ElementPolygons, ElementBoundaryContours} {
+		var results [2]*Result
+		for i, input := range [][][]float32{contours, reordered} {
+			tess := NewTessellator()
+			tess.SetOption(OptionCanonicalOrder, true)
+			for _, c := range input {
+				tess.AddContour(2, c)
+			}
+			r, err := tess.TessellateResult(WindingOdd, elementType, 3, 2, nil)
+			tess.Delete()
+			if err != nil {
+				t.Fatalf("TessellateResult failed: %v", err)
+			}
+			results[i] = r
+		}
+		if !reflect.DeepEqual(results[0].Vertices, results[1].Vertices) || !reflect.DeepEqual(results[0].Indices, results[1].Indices) {
+			t.Errorf("%s: expected identical output\n%v %v\n%v %v", elementType, results[0].Vertices, results[0].Indices, results[1].Vertices, results[1].Indices)
+		}
+	}
+}
+
+// TestCanonicalizeContours tests canonical boundary contours
+func TestCanonicalizeContours(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	for _, c := range canonicalContours() {
+		tess.AddContour(2, c)
+	}
+	r, err := tess.TessellateResult(WindingOdd, ElementBoundaryContours, 0, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	rings := func(r *Result) [][]float32 {
+		var rings [][]float32
+		for i := 0; i < len(r.Indices); i += 2 {
+			rings = append(rings, r.Vertices[2*r.Indices[i]:2*(r.Indices[i]+r.Indices[i+1])])
+		}
+		return rings
+	}
+	area := totalArea(rings(r))
+
+	c := *r
+	c.Canonicalize()
+	got := rings(&c)
+	if len(got) != len(r.Indices)/2 || totalArea(got)-area > 1e-9 || area-totalArea(got) > 1e-9 {
+		t.Fatalf("Expected %d rings with area %v, got %d with %v", len(r.Indices)/2, area, len(got), totalArea(got))
+	}
+	for i, ring := range got {
+		for j := 2; j < len(ring); j += 2 {
+			if slices.Compare(ring[:2], ring[j:j+2]) > 0 {
+				t.Errorf("Ring %d does not start at its smallest vertex", i)
+			}
+		}
+		if i > 0 && slices.Compare(got[i-1][:2], ring[:2]) > 0 {
+			t.Errorf("Rings %d and %d are not sorted", i-1, i)
+		}
+	}
+	for v, src := range c.VertexIndices {
+		i := slices.Index(r.VertexIndices, src)
+		if src != Undef && !slices.Equal(c.Vertices[2*v:2*v+2], r.Vertices[2*i:2*i+2]) {
+			t.Errorf("Vertex %d moved away from input vertex %d", v, src)
+		}
+	}
+}
+
+// TestCanonicalOrderOption tests the option against Canonicalize
+func TestCanonicalOrderOption(t *testing.T) {
+	run := func(canonical bool) *Result {
+		tess := NewTessellator()
+		defer tess.Delete()
+		tess.SetOption(OptionCanonicalOrder, canonical)
+		for _, c := range canonicalContours() {
+			tess.AddContour(2, c)
+		}
+		r, err := tess.TessellateResult(WindingNonZero, ElementConnectedPolygons, 4, 2, nil)
+		if err != nil {
+			t.Fatalf("TessellateResult failed: %v", err)
+		}
+		return r
+	}
+	want := run(false)
+	want.Canonicalize()
+	if got := run(true); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the option to match Canonicalize\n%+v\n%+v", got, want)
+	}
+
+	// The option is part of a Job and reset by a Pool.
+	tess := NewTessellator()
+	defer tess.Delete()
+	job := squareJob(2)
+	job.Options = []Option{OptionCanonicalOrder}
+	if jobKey(tess, job) == jobKey(tess, squareJob(2)) {
+		t.Error("Expected the option to change the cache key")
+	}
+	p := NewPool(1)
+	defer p.Close()
+	pooled, _ := p.Get()
+	job.Run(pooled)
+	p.Put(pooled)
+	pooled, _ = p.Get()
+	defer p.Put(pooled)
+	if pooled.canonical {
+		t.Error("Expected the option to be reset by Put")
+	}
+}
@@ -0,0 +1,219 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+)
+
+// CleanupReport describes the changes made by contour cleanup.
+type CleanupReport struct {
+	// Welded counts vertices moved onto an earlier vertex within epsilon,
+	// in the same or another contour.
+	Welded int
+	// Duplicates counts vertices removed because they coincided with the
+	// previous vertex of their contour after welding.
+	Duplicates int
+	// Collinear counts vertices removed because they lay within epsilon of
+	// the segment between their neighbours, and Spikes those removed
+	// because the contour turned back on itself there.
+	Collinear int
+	Spikes    int
+	// DroppedContours lists the contours, numbered in the order they were
+	// passed, left with fewer than three vertices and dropped.
+	DroppedContours []int
+}
+
+// Changed reports whether cleanup changed anything.
+func (r CleanupReport) Changed() bool {
+	return r.Welded+r.Duplicates+r.Collinear+r.Spikes+len(r.DroppedContours) > 0
+}
+
+// SetCleanup enables cleanup of contours passed to AddContour, or disables
+// it if epsilon is 0. Vertices within epsilon of a vertex added earlier since
+// the last tessellation, in any contour, are welded onto it. Repeated
+// vertices, and vertices within epsilon of the line through their
+// neighbours, which form collinear runs or zero-area spikes, are then
+// removed. Contours left with fewer than three vertices are dropped, though
+// they still count when contours are numbered; if every contour is dropped,
+// tessellation fails with an error saying so. Cleanup runs before
+// simplification; the vertex indices reported for the output still refer to
+// the vertices as passed to AddContour. CleanupReport describes the changes.
+func (t *Tessellator) SetCleanup(epsilon float32) error {
+	if t == nil || t.tess == nil {
+		return fmt.Errorf("tessellator is nil or deleted")
+	}
+	if epsilon < 0 || math.IsNaN(float64(epsilon)) || math.IsInf(float64(epsilon), 0) {
+		return fmt.Errorf("cleanup epsilon must be finite and not negative, got %v", epsilon)
+	}
+	t.cleanupEpsilon = epsilon
+	t.welder = nil
+	return nil
+}
+
+// CleanupReport returns the changes cleanup made to the contours added
+// since the last tessellation, or to those of the last tessellation if no
+// contour was added since.
+func (t *Tessellator) CleanupReport() CleanupReport {
+	r := t.cleanupReport
+	r.DroppedContours = append([]int(nil), r.DroppedContours...)
+	return r
+}
+
+// Cleanup applies the cleanup described at SetCleanup to a set of contours
+// of size-dimensional vertices, returning the contours that were not
+// dropped.
+func Cleanup(size int, contours [][]float32, epsilon float32) ([][]float32, CleanupReport, error) {
+	if size != 2 && size != 3 {
+		return nil, CleanupReport{}, fmt.Errorf("size must be 2 or 3, got %d", size)
+	}
+	if epsilon < 0 || math.IsNaN(float64(epsilon)) || math.IsInf(float64(epsilon), 0) {
+		return nil, CleanupReport{}, fmt.Errorf("cleanup epsilon must be finite and not negative, got %v", epsilon)
+	}
+	var report CleanupReport
+	w := newWelder(float64(epsilon))
+	out := make([][]float32, 0, len(contours))
+	for i, c := range contours {
+		if len(c)%size != 0 {
+			return nil, CleanupReport{}, fmt.Errorf("contour %d: len(vertices)(%d) must be multiple of size (%d)", i, len(c), size)
+		}
+		cleaned, _ := w.clean(size, c, &report)
+		if len(cleaned) < 3*size {
+			report.DroppedContours = append(report.DroppedContours, i)
+			continue
+		}
+		out = append(out, cleaned)
+	}
+	return out, report, nil
+}
+
+// welder snaps vertices onto earlier ones within eps, using a grid of cells
+// of size eps so that only neighbouring cells need to be searched.
+type welder struct {
+	eps    float64
+	cells  map[[3]int64][]int
+	points [][3]float64
+}
+
+func newWelder(eps float64) *welder {
+	return &welder{eps: eps, cells: make(map[[3]int64][]int)}
+}
+
+func (w *welder) cell(p [3]float64) [3]int64 {
+	return [3]int64{int64(math.Floor(p[0] / w.eps)), int64(math.Floor(p[1] / w.eps)), int64(math.Floor(p[2] / w.eps))}
+}
+
+// weld returns the earlier point nearest to p within eps, or p itself after
+// recording it.
+func (w *welder) weld(p [3]float64) ([3]float64, bool) {
+	c := w.cell(p)
+	best, bestDist := -1, w.eps
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				for _, i := range w.cells[[3]int64{c[0] + dx, c[1] + dy, c[2] + dz}] {
+					d := sub3(w.points[i], p)
+					if dist := math.Sqrt(dot3(d, d)); dist <= bestDist {
+						best, bestDist = i, dist
+					}
+				}
+			}
+		}
+	}
+	if best >= 0 {
+		return w.points[best], w.points[best] != p
+	}
+	w.cells[c] = append(w.cells[c], len(w.points))
+	w.points = append(w.points, p)
+	return p, false
+}
+
+// clean welds a contour and removes repeated, collinear and spike vertices.
+// It returns the remaining vertices and their indices in the contour.
+func (w *welder) clean(size int, vertices []float32, report *CleanupReport) ([]float32, []int) {
+	// Repeated vertices are removed first, keeping the first of each run.
+	var points [][3]float64
+	var index []int
+	for i := 0; i < len(vertices)/size; i++ {
+		var p [3]float64
+		for k := 0; k < size; k++ {
+			p[k] = float64(vertices[i*size+k])
+		}
+		if w.eps > 0 {
+			var moved bool
+			if p, moved = w.weld(p); moved {
+				report.Welded++
+			}
+		}
+		if len(points) > 0 && points[len(points)-1] == p {
+			report.Duplicates++
+			continue
+		}
+		points = append(points, p)
+		index = append(index, i)
+	}
+	for len(points) > 1 && points[len(points)-1] == points[0] {
+		report.Duplicates++
+		points, index = points[:len(points)-1], index[:len(index)-1]
+	}
+	n := len(points)
+
+	// The contour is a ring of live vertices; removing one can make its
+	// neighbours removable, so they are checked again.
+	next := make([]int, n)
+	prev := make([]int, n)
+	for i := range next {
+		next[i], prev[i] = (i+1)%n, (i+n-1)%n
+	}
+	live := n
+	remove := func(i int) {
+		next[prev[i]], prev[next[i]] = next[i], prev[i]
+		next[i] = -1
+		live--
+	}
+	// pending is a stack, filled so that vertices are visited in order.
+	pending := make([]int, n)
+	for i := range pending {
+		pending[i] = n - 1 - i
+	}
+	for len(pending) > 0 && live >= 3 {
+		i := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if next[i] < 0 {
+			continue
+		}
+		a, b, c := points[prev[i]], points[i], points[next[i]]
+		switch {
+		case b == a || b == c:
+			report.Duplicates++
+		case a == c:
+			// The contour goes out to b and straight back.
+			report.Spikes++
+		default:
+			ac, ab := sub3(c, a), sub3(b, a)
+			l := math.Sqrt(dot3(ac, ac))
+			cr := cross3(ab, ac)
+			if math.Sqrt(dot3(cr, cr))/l > w.eps {
+				continue
+			}
+			if s := dot3(ab, ac) / (l * l); s >= 0 && s <= 1 {
+				report.Collinear++
+			} else {
+				report.Spikes++
+			}
+		}
+		pending = append(pending, next[i], prev[i])
+		remove(i)
+	}
+	keep := make([]int, 0, live)
+	out := make([]float32, 0, live*size)
+	for i := 0; i < n; i++ {
+		if next[i] < 0 {
+			continue
+		}
+		keep = append(keep, index[i])
+		for k := 0; k < size; k++ {
+			out = append(out, float32(points[i][k]))
+		}
+	}
+	return out, keep
+}
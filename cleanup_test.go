@@ -0,0 +1,240 @@
+package tess
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCleanup tests the removal of degenerate vertices from single contours
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		name    string
+		contour []float32
+		want    []float32
+		report  CleanupReport
+	}{
+		{
+			"clean",
+			[]float32{0, 0, 1, 0, 1, 1, 0, 1},
+			[]float32{0, 0, 1, 0, 1, 1, 0, 1},
+			CleanupReport{},
+		},
+		{
+			"duplicates",
+			[]float32{0, 0, 1, 0, 1, 0, 1, 1, 0, 1, 0, 1, 0, 0},
+			[]float32{0, 0, 1, 0, 1, 1, 0, 1},
+			CleanupReport{Duplicates: 3},
+		},
+		{
+			"welded duplicate",
+			[]float32{0, 0, 1, 0, 1.005, 0.005, 1, 1, 0, 1},
+			[]float32{0, 0, 1, 0, 1, 1, 0, 1},
+			CleanupReport{Welded: 1, Duplicates: 1},
+		},
+		{
+			"collinear",
+			[]float32{0, 0, 0.5, 0.001, 1, 0, 1, 1, 0, 1, 0, 0.5},
+			[]float32{0, 0, 1, 0, 1, 1, 0, 1},
+			CleanupReport{Collinear: 2},
+		},
+		{
+			"spike",
+			[]float32{0, 0, 1, 0, 1, 1, 2, 1, 1, 1, 0, 1},
+			[]float32{0, 0, 1, 0, 1, 1, 0, 1},
+			CleanupReport{Duplicates: 1, Spikes: 1},
+		},
+		{
+			"overshooting spike",
+			[]float32{0, 0, 1, 0, 1, 1, 1, 3, 1, 2, 0, 2},
+			[]float32{0, 0, 1, 0, 1, 2, 0, 2},
+			CleanupReport{Collinear: 1, Spikes: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, report, err := Cleanup(2, [][]float32{tt.contour}, 0.01)
+			if err != nil {
+				t.Fatalf("Cleanup failed: %v", err)
+			}
+			if len(out) != 1 || !reflect.DeepEqual(out[0], tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, out)
+			}
+			if !reflect.DeepEqual(report, tt.report) {
+				t.Errorf("Expected report %+v, got %+v", tt.report, report)
+			}
+			if report.Changed() != !reflect.DeepEqual(tt.report, CleanupReport{}) {
+				t.Errorf("Unexpected Changed %v for %+v", report.Changed(), report)
+			}
+		})
+	}
+}
+
+// TestCleanupContours tests welding across contours and dropped contours
+func TestCleanupContours(t *testing.T) {
+	contours := [][]float32{
+		{0, 0, 0, 2, 0, 0, 2, 2, 0},
+		// Touches the first triangle at a vertex that is off by rounding.
+		{2.001, 0, 0, 4, 0, 0, 4, 2, 0},
+		// Collapses onto a segment.
+		{0, 0, 0, 1, 0, 0, 2, 0.0001, 0},
+		// Collapses onto a point.
+		{5, 5, 5, 5.001, 5, 5, 5, 5.001, 5},
+		{0, 0, 1, 1, 0, 1, 0, 1, 1},
+	}
+	out, report, err := Cleanup(3, contours, 0.01)
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if len(out) != 3 || out[1][0] != 2 {
+		t.Errorf("Expected 3 contours with the second welded onto the first, got %v", out)
+	}
+	if !reflect.DeepEqual(report.DroppedContours, []int{2, 3}) {
+		t.Errorf("Expected contours 2 and 3 dropped, got %v", report.DroppedContours)
+	}
+	if report.Welded != 4 {
+		t.Errorf("Expected 4 welded vertices, got %+v", report)
+	}
+
+	// With epsilon 0 only exact repeats and collinear vertices are removed.
+	out, report, err = Cleanup(3, contours, 0)
+	if err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if len(out) != len(contours) || report.Changed() {
+		t.Errorf("Expected no change, got %d contours and %+v", len(out), report)
+	}
+
+	for _, tt := range []struct {
+		size     int
+		contours [][]float32
+		epsilon  float32
+	}{
+		{4, contours, 0.01},
+		{3, contours, -1},
+		{3, contours, float32(math.NaN())},
+		{3, contours, float32(math.Inf(1))},
+		{2, [][]float32{{0, 0, 1}}, 0.01},
+	} {
+		if _, _, err := Cleanup(tt.size, tt.contours, tt.epsilon); err == nil {
+			t.Errorf("Expected error for size %d, epsilon %v and %v", tt.size, tt.epsilon, tt.contours)
+		}
+	}
+}
+
+// TestTessellatorCleanup tests cleanup of contours added to a Tessellator
+func TestTessellatorCleanup(t *testing.T) {
+	contours := [][]float32{
+		{0, 0, 1, 0, 1, 0, 1, 1, 0, 1},
+		{2, 2, 2, 2.001, 2.001, 2},
+		// Shares an edge with the first square, off by rounding.
+		{1.001, 0, 2, 0, 2, 1, 0.999, 1},
+	}
+	tess := NewTessellator()
+	defer tess.Delete()
+	tess.SetOption(OptionContourAttribution, true)
+	if err := tess.SetCleanup(0.01); err != nil {
+		t.Fatalf("SetCleanup failed: %v", err)
+	}
+	for _, c := range contours {
+		if err := tess.AddContour(2, c); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+	}
+	r, err := tess.TessellateResult(WindingPositive, ElementBoundaryContours, 0, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+
+	// The welded squares merge into a single rectangle.
+	if len(r.Indices) != 2 || ringArea(r.Vertices) != 2 {
+		t.Errorf("Expected a single rectangle, got %v %v", r.Vertices, r.Indices)
+	}
+	want := CleanupReport{Welded: 4, Duplicates: 3, DroppedContours: []int{1}}
+	if report := tess.CleanupReport(); !reflect.DeepEqual(report, want) {
+		t.Errorf("Expected report %+v, got %+v", want, report)
+	}
+
+	// Vertex indices refer to the vertices as added.
+	var input []float32
+	for _, c := range contours {
+		input = append(input, c...)
+	}
+	for v, src := range r.VertexIndices {
+		if src == Undef {
+			continue
+		}
+		d := math.Hypot(float64(r.Vertices[2*v]-input[2*src]), float64(r.Vertices[2*v+1]-input[2*src+1]))
+		if d > 0.01 {
+			t.Errorf("Vertex %d at %v maps to input vertex %d at %v", v, r.Vertices[2*v:2*v+2], src, input[2*src:2*src+2])
+		}
+	}
+
+	// New input resets the report, and cleanup composes with simplification.
+	tess.SetSimplify(SimplifyDouglasPeucker, 0.1)
+	tess.AddContour(2, []float32{0, 0, 0, 0, 1, 0.05, 2, 0, 2, 2, 0, 2})
+	if report := tess.CleanupReport(); report.Duplicates != 1 || report.Welded != 0 {
+		t.Errorf("Expected a fresh report with one duplicate, got %+v", report)
+	}
+	r, err = tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	if len(r.Indices) != 6 || len(r.VertexIndices) != 4 {
+		t.Errorf("Expected two triangles of four vertices, got %v %v", r.Indices, r.VertexIndices)
+	}
+	for _, src := range r.VertexIndices {
+		if src == 1 || src == 2 {
+			t.Errorf("Expected removed vertex %d not to be reported", src)
+		}
+	}
+
+	// Contours that all collapse leave nothing to tessellate.
+	tess.AddContour(2, contours[1])
+	tess.AddContour(2, []float32{0, 0, 1, 0, 2, 0})
+	if _, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil); err == nil || !strings.Contains(err.Error(), "cleanup") {
+		t.Errorf("Expected an error naming cleanup, got %v", err)
+	}
+	if report := tess.CleanupReport(); !reflect.DeepEqual(report.DroppedContours, []int{0, 1}) {
+		t.Errorf("Expected both contours dropped, got %+v", report)
+	}
+	// The tessellator is ready for new input.
+	tess.AddContour(2, contours[0])
+	if _, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil); err != nil {
+		t.Errorf("TessellateResult failed after dropped input: %v", err)
+	}
+
+	if err := tess.SetCleanup(-1); err == nil {
+		t.Error("Expected error for a negative epsilon")
+	}
+	var nilTess *Tessellator
+	if err := nilTess.SetCleanup(0.1); err == nil {
+		t.Error("Expected error for a nil tessellator")
+	}
+}
+
+// TestPoolCleanup tests that cleanup is part of the cache key and reset by a Pool
+func TestPoolCleanup(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	key := jobKey(tess, squareJob(2))
+	tess.SetCleanup(0.01)
+	if jobKey(tess, squareJob(2)) == key {
+		t.Error("Expected cleanup to change the cache key")
+	}
+
+	p := NewPool(1)
+	defer p.Close()
+	pooled, _ := p.Get()
+	pooled.SetCleanup(0.01)
+	pooled.AddContour(2, []float32{0, 0, 1, 0, 1, 0, 0, 1})
+	pooled.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil)
+	p.Put(pooled)
+	pooled, _ = p.Get()
+	defer p.Put(pooled)
+	if pooled.cleanupEpsilon != 0 || pooled.CleanupReport().Changed() {
+		t.Error("Expected cleanup to be reset by Put")
+	}
+}
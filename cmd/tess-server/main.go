@@ -0,0 +1,113 @@
+// Command tess-server exposes tessellation as a JSON-over-HTTP service.
+//
+// Usage:
+//
+//	tess-server [flags]
+//
+// POST /tessellate accepts a JSON body such as
+//
+//	{
+//	  "size": 2,
+//	  "contours": [[0, 0, 4, 0, 4, 4, 0, 4], [1, 1, 1, 3, 3, 3, 3, 1]],
+//	  "windingRule": "NonZero",
+//	  "elementType": "Polygons",
+//	  "polySize": 3,
+//	  "vertexSize": 2,
+//	  "normal": [0, 0, 1],
+//	  "constrainedDelaunay": false,
+//	  "reverseContours": false
+//	}
+//
+// where every field but contours is optional, and responds with the output
+// vertices, indices and vertex indices. Errors are reported as
+// {"error": "..."} with a 4xx or 5xx status. GET /healthz reports whether the
+// server is running, and GET /debug/vars serves expvar metrics, including
+// tessellation counters under "tess".
+//
+// The server shuts down gracefully on SIGINT or SIGTERM, finishing requests
+// in flight.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/mikijov/go-libtess2/tessexpvar"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "maximum number of concurrent tessellations")
+	maxBody := flag.Int64("max-body", 4<<20, "maximum request body size in bytes")
+	maxVertices := flag.Int("max-vertices", 100000, "maximum number of input vertices per request")
+	maxContours := flag.Int("max-contours", 10000, "maximum number of contours per request")
+	maxPolySize := flag.Int("max-polysize", 64, "maximum polySize")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "time to wait for requests in flight on shutdown")
+	flag.Parse()
+
+	if *concurrency < 1 {
+		log.Fatal("-concurrency must be at least 1")
+	}
+
+	s := newServer(*concurrency, limits{
+		maxBodyBytes: *maxBody,
+		maxVertices:  *maxVertices,
+		maxContours:  *maxContours,
+		maxPolySize:  *maxPolySize,
+	})
+	defer s.close()
+	s.pool.SetObserver(tessexpvar.New("tess"))
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := serve(ctx, ln, s.routes(), *shutdownTimeout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serve serves HTTP on ln until ctx is done, then shuts down gracefully,
+// waiting up to timeout for requests in flight.
+func serve(ctx context.Context, ln net.Listener, handler http.Handler, timeout time.Duration) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", ln.Addr())
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Print("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+// limits bounds the work a single request may ask for.
+type limits struct {
+	maxBodyBytes int64
+	maxVertices  int
+	maxContours  int
+	maxPolySize  int
+}
+
+// server handles tessellation requests with pooled Tessellators. At most
+// cap(slots) tessellations run at once; further requests wait for a slot
+// until their context is done.
+type server struct {
+	pool   *tess.Pool
+	slots  chan struct{}
+	limits limits
+}
+
+func newServer(concurrency int, l limits) *server {
+	return &server{
+		pool:   tess.NewPool(concurrency),
+		slots:  make(chan struct{}, concurrency),
+		limits: l,
+	}
+}
+
+func (s *server) close() {
+	s.pool.Close()
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tessellate", s.handleTessellate)
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	return mux
+}
+
+// request is the body of POST /tessellate. Names of winding rules and
+// element types are matched case-insensitively against their String values.
+type request struct {
+	// Size is the number of coordinates per input vertex. Defaults to 2.
+	Size     int         `json:"size"`
+	Contours [][]float32 `json:"contours"`
+	// WindingRule defaults to "Odd".
+	WindingRule tess.WindingRule `json:"windingRule"`
+	// ElementType defaults to "Polygons".
+	ElementType tess.ElementType `json:"elementType"`
+	// PolySize defaults to 3.
+	PolySize int `json:"polySize"`
+	// VertexSize defaults to Size.
+	VertexSize          int       `json:"vertexSize"`
+	Normal              []float32 `json:"normal"`
+	ConstrainedDelaunay bool      `json:"constrainedDelaunay"`
+	ReverseContours     bool      `json:"reverseContours"`
+}
+
+// response is the body of a successful POST /tessellate.
+type response struct {
+	VertexSize    int              `json:"vertexSize"`
+	ElementType   tess.ElementType `json:"elementType"`
+	PolySize      int              `json:"polySize"`
+	Vertices      []float32        `json:"vertices"`
+	Indices       []int            `json:"indices"`
+	VertexIndices []int            `json:"vertexIndices"`
+}
+
+// httpError is an error with the status code to report it with.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func tooLarge(format string, args ...any) error {
+	return &httpError{http.StatusRequestEntityTooLarge, fmt.Sprintf(format, args...)}
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) handleTessellate(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.tessellate(w, r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var herr *httpError
+		if errors.As(err, &herr) {
+			status = herr.status
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *server) tessellate(w http.ResponseWriter, r *http.Request) (*response, error) {
+	req, err := s.decode(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-r.Context().Done():
+		return nil, &httpError{http.StatusServiceUnavailable, "request cancelled while waiting for a tessellator"}
+	}
+
+	t, err := s.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.Put(t)
+
+	if err := t.SetOption(tess.OptionConstrainedDelaunay, req.ConstrainedDelaunay); err != nil {
+		return nil, err
+	}
+	if err := t.SetOption(tess.OptionReverseContours, req.ReverseContours); err != nil {
+		return nil, err
+	}
+	for i, c := range req.Contours {
+		if err := t.AddContour(req.Size, c); err != nil {
+			return nil, badRequest("contour %d: %v", i, err)
+		}
+	}
+
+	result, err := t.TessellateResult(req.WindingRule, req.ElementType, req.PolySize, req.VertexSize, req.Normal)
+	if err != nil {
+		return nil, &httpError{http.StatusUnprocessableEntity, err.Error()}
+	}
+	return &response{
+		VertexSize:    result.VertexSize,
+		ElementType:   result.ElementType,
+		PolySize:      result.PolySize,
+		Vertices:      result.Vertices,
+		Indices:       result.Indices,
+		VertexIndices: result.VertexIndices,
+	}, nil
+}
+
+// decode reads and validates a request, filling in defaults.
+func (s *server) decode(w http.ResponseWriter, r *http.Request) (*request, error) {
+	body := http.MaxBytesReader(w, r.Body, s.limits.maxBodyBytes)
+	req := &request{WindingRule: tess.WindingOdd, ElementType: tess.ElementPolygons}
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, tooLarge("request body exceeds %d bytes", maxErr.Limit)
+		}
+		return nil, badRequest("invalid request: %v", err)
+	}
+
+	if req.Size == 0 {
+		req.Size = 2
+	}
+	if req.Size != 2 && req.Size != 3 {
+		return nil, badRequest("size must be 2 or 3, got %d", req.Size)
+	}
+	if req.VertexSize == 0 {
+		req.VertexSize = req.Size
+	}
+	if req.PolySize == 0 {
+		req.PolySize = 3
+	}
+	if req.PolySize < 3 || req.PolySize > s.limits.maxPolySize {
+		return nil, badRequest("polySize must be between 3 and %d, got %d", s.limits.maxPolySize, req.PolySize)
+	}
+	if len(req.Contours) == 0 {
+		return nil, badRequest("no contours")
+	}
+	if len(req.Contours) > s.limits.maxContours {
+		return nil, tooLarge("%d contours exceed the limit of %d", len(req.Contours), s.limits.maxContours)
+	}
+	vertices := 0
+	for _, c := range req.Contours {
+		vertices += len(c) / req.Size
+	}
+	if vertices > s.limits.maxVertices {
+		return nil, tooLarge("%d vertices exceed the limit of %d", vertices, s.limits.maxVertices)
+	}
+	return req, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
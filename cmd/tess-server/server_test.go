@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikijov/go-libtess2/tessexpvar"
+)
+
+var testLimits = limits{maxBodyBytes: 1 << 16, maxVertices: 100, maxContours: 10, maxPolySize: 8}
+
+func post(t *testing.T, url, body string) (int, map[string]any) {
+	t.Helper()
+	res, err := http.Post(url+"/tessellate", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer res.Body.Close()
+	var doc map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		t.Fatalf("Invalid JSON response: %v", err)
+	}
+	return res.StatusCode, doc
+}
+
+// TestTessellate tests successful requests with and without defaults
+func TestTessellate(t *testing.T) {
+	s := newServer(2, testLimits)
+	defer s.close()
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	status, doc := post(t, ts.URL, `{"contours": [[0, 0, 4, 0, 4, 4, 0, 4]]}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", status, doc)
+	}
+	if doc["elementType"] != "Polygons" || doc["polySize"] != 3.0 || doc["vertexSize"] != 2.0 {
+		t.Errorf("Unexpected defaults in %v", doc)
+	}
+	if n := len(doc["indices"].([]any)); n != 6 {
+		t.Errorf("Expected two triangles, got %d indices", n)
+	}
+
+	status, doc = post(t, ts.URL, `{
+		"size": 3,
+		"contours": [[0, 0, 1, 4, 0, 1, 4, 4, 1, 0, 4, 1], [1, 1, 1, 3, 1, 1, 3, 3, 1, 1, 3, 1]],
+		"windingRule": "nonzero",
+		"elementType": "BoundaryContours",
+		"vertexSize": 2,
+		"normal": [0, 0, 1],
+		"constrainedDelaunay": true,
+		"reverseContours": true
+	}`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", status, doc)
+	}
+	if doc["elementType"] != "BoundaryContours" || doc["vertexSize"] != 2.0 {
+		t.Errorf("Unexpected header in %v", doc)
+	}
+	// Both contours run the same way, so NonZero merges them into one.
+	if n := len(doc["indices"].([]any)); n != 2 {
+		t.Errorf("Expected one boundary contour, got %d indices", n)
+	}
+	if n := len(doc["vertexIndices"].([]any)); n != 4 {
+		t.Errorf("Expected 4 vertex indices, got %d", n)
+	}
+}
+
+// TestTessellateErrors tests that invalid and oversized requests are rejected
+func TestTessellateErrors(t *testing.T) {
+	s := newServer(1, testLimits)
+	defer s.close()
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	square := `[0, 0, 4, 0, 4, 4, 0, 4]`
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"contours": [` + square, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "colour": "red"}`, http.StatusBadRequest},
+		{`{"contours": []}`, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "size": 4}`, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "polySize": 2}`, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "polySize": 9}`, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "windingRule": "even"}`, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "elementType": "triangles"}`, http.StatusBadRequest},
+		{`{"contours": [[0, 0, 1]]}`, http.StatusBadRequest},
+		{`{"contours": [` + square + `], "vertexSize": 4}`, http.StatusUnprocessableEntity},
+		{`{"contours": [` + strings.Repeat(square+",", 10) + square + `]}`, http.StatusRequestEntityTooLarge},
+		{`{"contours": [[` + strings.Repeat("1, ", 2*101-1) + `1]]}`, http.StatusRequestEntityTooLarge},
+		{`{"contours": [[` + strings.Repeat(" ", 1<<16) + `]]}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		status, doc := post(t, ts.URL, tt.body)
+		if status != tt.status {
+			t.Errorf("Expected %d, got %d for %.60s", tt.status, status, tt.body)
+		}
+		if msg, _ := doc["error"].(string); msg == "" {
+			t.Errorf("Expected an error message for %.60s, got %v", tt.body, doc)
+		}
+	}
+
+	// The pool still works after failed requests.
+	if status, doc := post(t, ts.URL, `{"contours": [`+square+`]}`); status != http.StatusOK {
+		t.Errorf("Expected 200 after errors, got %d: %v", status, doc)
+	}
+
+	res, err := http.Get(ts.URL + "/tessellate")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", res.StatusCode)
+	}
+}
+
+// TestHealth tests the health endpoint
+func TestHealth(t *testing.T) {
+	s := newServer(1, testLimits)
+	defer s.close()
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), `"ok"`) {
+		t.Errorf("Unexpected health response %d: %s", res.StatusCode, body)
+	}
+}
+
+// TestMetrics tests that tessellation counters are served with expvar
+func TestMetrics(t *testing.T) {
+	s := newServer(1, testLimits)
+	defer s.close()
+	s.pool.SetObserver(tessexpvar.New("tess"))
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	post(t, ts.URL, `{"contours": [[0, 0, 4, 0, 4, 4, 0, 4]]}`)
+	res, err := http.Get(ts.URL + "/debug/vars")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer res.Body.Close()
+	var vars struct {
+		Tess map[string]any `json:"tess"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&vars); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if vars.Tess["calls"] != 1.0 || vars.Tess["output_elements"] != 2.0 {
+		t.Errorf("Unexpected counters %v", vars.Tess)
+	}
+}
+
+// TestConcurrentRequests tests that concurrent requests share the pool
+func TestConcurrentRequests(t *testing.T) {
+	s := newServer(2, testLimits)
+	defer s.close()
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				body := fmt.Sprintf(`{"contours": [[0, 0, %d, 0, %d, 4, 0, 4, 1, 2]], "windingRule": "positive"}`, g+1, g+1)
+				res, err := http.Post(ts.URL+"/tessellate", "application/json", strings.NewReader(body))
+				if err != nil {
+					t.Errorf("POST failed: %v", err)
+					return
+				}
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+				if res.StatusCode != http.StatusOK {
+					t.Errorf("Expected 200, got %d", res.StatusCode)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if n := s.pool.Idle(); n < 1 || n > 2 {
+		t.Errorf("Expected 1 or 2 idle tessellators, got %d", n)
+	}
+}
+
+// TestServeShutdown tests that serve returns cleanly when its context ends
+func TestServeShutdown(t *testing.T) {
+	s := newServer(1, testLimits)
+	defer s.close()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, ln, s.routes(), time.Second) }()
+
+	res, err := http.Get("http://" + ln.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	res.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after cancellation")
+	}
+	if _, err := http.Get("http://" + ln.Addr().String() + "/healthz"); err == nil {
+		t.Error("Expected the server to be closed")
+	}
+}
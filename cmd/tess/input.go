@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// contourSet is the tessellator input read from a file.
+type contourSet struct {
+	size     int
+	contours [][]float32
+}
+
+func readInput(r io.Reader, format string, flatness float64) (*contourSet, error) {
+	var (
+		cs  *contourSet
+		err error
+	)
+	switch format {
+	case "json":
+		cs, err = readJSON(r)
+	case "geojson":
+		cs, err = readGeoJSON(r)
+	case "svg":
+		cs, err = readSVG(r, flatness)
+	case "text":
+		cs, err = readText(r)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", format, err)
+	}
+	if len(cs.contours) == 0 {
+		return nil, fmt.Errorf("reading %s: no contours", format)
+	}
+	return cs, nil
+}
+
+// readJSON reads {"size": n, "contours": [...]} or a bare array of flat
+// contours. The size defaults to 2.
+func readJSON(r io.Reader) (*contourSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Size     int         `json:"size"`
+		Contours [][]float32 `json:"contours"`
+	}
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &doc.Contours)
+	} else {
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cs := &contourSet{size: doc.Size, contours: doc.Contours}
+	if cs.size == 0 {
+		cs.size = 2
+	}
+	if cs.size != 2 && cs.size != 3 {
+		return nil, fmt.Errorf("size must be 2 or 3, got %d", cs.size)
+	}
+	for i, c := range cs.contours {
+		if len(c)%cs.size != 0 {
+			return nil, fmt.Errorf("contour %d has %d coordinates, not a multiple of %d", i, len(c), cs.size)
+		}
+	}
+	return cs, nil
+}
+
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Geometries  []geoJSON       `json:"geometries"`
+	Features    []geoJSON       `json:"features"`
+}
+
+// readGeoJSON reads the rings of every Polygon and MultiPolygon in a
+// geometry, feature or collection. Other geometry types are ignored. The
+// vertex size is 3 only if every position has an elevation.
+func readGeoJSON(r io.Reader) (*contourSet, error) {
+	var doc geoJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var rings [][][]float64
+	if err := collectRings(&doc, &rings); err != nil {
+		return nil, err
+	}
+
+	cs := &contourSet{size: 3}
+	for _, ring := range rings {
+		for _, p := range ring {
+			if len(p) < 2 {
+				return nil, fmt.Errorf("position with %d coordinates", len(p))
+			}
+			if len(p) < 3 {
+				cs.size = 2
+			}
+		}
+	}
+	for _, ring := range rings {
+		// GeoJSON rings repeat the first position at the end.
+		if n := len(ring); n > 1 && samePosition(ring[0], ring[n-1]) {
+			ring = ring[:n-1]
+		}
+		contour := make([]float32, 0, len(ring)*cs.size)
+		for _, p := range ring {
+			for k := 0; k < cs.size; k++ {
+				contour = append(contour, float32(p[k]))
+			}
+		}
+		cs.contours = append(cs.contours, contour)
+	}
+	return cs, nil
+}
+
+func collectRings(g *geoJSON, rings *[][][]float64) error {
+	switch g.Type {
+	case "FeatureCollection":
+		for i := range g.Features {
+			if err := collectRings(&g.Features[i], rings); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if g.Geometry != nil {
+			return collectRings(g.Geometry, rings)
+		}
+	case "GeometryCollection":
+		for i := range g.Geometries {
+			if err := collectRings(&g.Geometries[i], rings); err != nil {
+				return err
+			}
+		}
+	case "Polygon":
+		var polygon [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return fmt.Errorf("polygon coordinates: %w", err)
+		}
+		*rings = append(*rings, polygon...)
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return fmt.Errorf("multipolygon coordinates: %w", err)
+		}
+		for _, polygon := range polygons {
+			*rings = append(*rings, polygon...)
+		}
+	case "":
+		return fmt.Errorf("missing type")
+	}
+	return nil
+}
+
+func samePosition(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// readText reads one vertex per line as two or three numbers separated by
+// spaces or commas. Blank lines end a contour and '#' starts a comment.
+func readText(r io.Reader) (*contourSet, error) {
+	cs := &contourSet{}
+	var contour []float32
+	flush := func() {
+		if len(contour) > 0 {
+			cs.contours = append(cs.contours, contour)
+			contour = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+		if len(fields) == 0 {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				flush()
+			}
+			continue
+		}
+		if cs.size == 0 {
+			cs.size = len(fields)
+		}
+		if len(fields) != cs.size || (cs.size != 2 && cs.size != 3) {
+			return nil, fmt.Errorf("line %d: expected %d coordinates, got %d", line, max(cs.size, 2), len(fields))
+		}
+		for _, f := range fields {
+			v, err := strconv.ParseFloat(f, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			contour = append(contour, float32(v))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return cs, nil
+}
+
+// readSVG reads the outlines of path, polygon, polyline and rect elements.
+// Transforms are not applied and curves are flattened until they deviate
+// from the true curve by at most flatness.
+func readSVG(r io.Reader, flatness float64) (*contourSet, error) {
+	cs := &contourSet{size: 2}
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attr := func(name string) string {
+			for _, a := range start.Attr {
+				if a.Name.Local == name {
+					return a.Value
+				}
+			}
+			return ""
+		}
+
+		switch start.Name.Local {
+		case "path":
+			contours, err := parsePath(attr("d"), flatness)
+			if err != nil {
+				return nil, fmt.Errorf("path: %w", err)
+			}
+			cs.contours = append(cs.contours, contours...)
+		case "polygon", "polyline":
+			nums, err := parseNumbers(attr("points"))
+			if err != nil || len(nums)%2 != 0 {
+				return nil, fmt.Errorf("%s: invalid points %q", start.Name.Local, attr("points"))
+			}
+			contour := make([]float32, len(nums))
+			for i, v := range nums {
+				contour[i] = float32(v)
+			}
+			cs.contours = append(cs.contours, contour)
+		case "rect":
+			var v [4]float32
+			for i, name := range []string{"x", "y", "width", "height"} {
+				if s := attr(name); s != "" {
+					f, err := strconv.ParseFloat(s, 32)
+					if err != nil {
+						return nil, fmt.Errorf("rect: invalid %s %q", name, s)
+					}
+					v[i] = float32(f)
+				}
+			}
+			x, y, w, h := v[0], v[1], v[2], v[3]
+			cs.contours = append(cs.contours, []float32{x, y, x + w, y, x + w, y + h, x, y + h})
+		}
+	}
+	return cs, nil
+}
+
+// parseNumbers splits an SVG number list separated by spaces and commas.
+func parseNumbers(s string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// pathScanner tokenises SVG path data into commands and numbers.
+type pathScanner struct {
+	s   string
+	pos int
+}
+
+func (p *pathScanner) skip() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ',' || unicode.IsSpace(rune(p.s[p.pos]))) {
+		p.pos++
+	}
+}
+
+// command returns the next command letter, or 0 if a number follows.
+func (p *pathScanner) command() byte {
+	p.skip()
+	if p.pos < len(p.s) && unicode.IsLetter(rune(p.s[p.pos])) && p.s[p.pos] != 'e' && p.s[p.pos] != 'E' {
+		p.pos++
+		return p.s[p.pos-1]
+	}
+	return 0
+}
+
+func (p *pathScanner) done() bool {
+	p.skip()
+	return p.pos >= len(p.s)
+}
+
+// number reads one number. SVG allows numbers to run together, as in
+// "1.5.5" or "1-2", so the end is found by scanning rather than splitting.
+func (p *pathScanner) number() (float64, error) {
+	p.skip()
+	start, i := p.pos, p.pos
+	if i < len(p.s) && (p.s[i] == '+' || p.s[i] == '-') {
+		i++
+	}
+	dot, digits := false, false
+	for ; i < len(p.s); i++ {
+		c := p.s[i]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+	}
+	if digits && i < len(p.s) && (p.s[i] == 'e' || p.s[i] == 'E') {
+		j := i + 1
+		if j < len(p.s) && (p.s[j] == '+' || p.s[j] == '-') {
+			j++
+		}
+		if j < len(p.s) && p.s[j] >= '0' && p.s[j] <= '9' {
+			for i = j; i < len(p.s) && p.s[i] >= '0' && p.s[i] <= '9'; i++ {
+			}
+		}
+	}
+	if !digits {
+		return 0, fmt.Errorf("expected number at offset %d", start)
+	}
+	p.pos = i
+	return strconv.ParseFloat(p.s[start:i], 64)
+}
+
+func (p *pathScanner) numbers(n int) ([]float64, error) {
+	out := make([]float64, n)
+	for i := range out {
+		v, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// parsePath converts SVG path data into contours. Every subpath becomes a
+// contour, closed or not. Elliptical arcs are not supported.
+func parsePath(d string, flatness float64) ([][]float32, error) {
+	var (
+		contours  [][]float32
+		current   []float32
+		x, y      float64 // current point
+		sx, sy    float64 // subpath start
+		cx, cy    float64 // last control point for S and T
+		cmd, prev byte
+	)
+	flush := func() {
+		if len(current) > 2 {
+			contours = append(contours, current)
+		}
+		current = nil
+	}
+	lineTo := func(nx, ny float64) {
+		if len(current) == 0 {
+			current = append(current, float32(x), float32(y))
+		}
+		x, y = nx, ny
+		current = append(current, float32(x), float32(y))
+	}
+
+	p := &pathScanner{s: d}
+	for !p.done() {
+		if c := p.command(); c != 0 {
+			cmd = c
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("path data must start with a command")
+		}
+		rel := cmd >= 'a'
+		upper := cmd &^ 0x20
+		var ox, oy float64
+		if rel {
+			ox, oy = x, y
+		}
+
+		var args []float64
+		var err error
+		switch upper {
+		case 'Z':
+		case 'H', 'V':
+			args, err = p.numbers(1)
+		case 'M', 'L', 'T':
+			args, err = p.numbers(2)
+		case 'S', 'Q':
+			args, err = p.numbers(4)
+		case 'C':
+			args, err = p.numbers(6)
+		case 'A':
+			return nil, fmt.Errorf("elliptical arcs are not supported")
+		default:
+			return nil, fmt.Errorf("unknown command %q", cmd)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Control point reflected for smooth curves, or the current point
+		// if the previous command was not a matching curve.
+		rx, ry := x, y
+		prevUpper := prev &^ 0x20
+		if (upper == 'S' && (prevUpper == 'C' || prevUpper == 'S')) || (upper == 'T' && (prevUpper == 'Q' || prevUpper == 'T')) {
+			rx, ry = 2*x-cx, 2*y-cy
+		}
+
+		switch upper {
+		case 'Z':
+			flush()
+			x, y = sx, sy
+		case 'M':
+			flush()
+			x, y = ox+args[0], oy+args[1]
+			sx, sy = x, y
+			// Further coordinate pairs are implicit line commands.
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L':
+			lineTo(ox+args[0], oy+args[1])
+		case 'H':
+			lineTo(ox+args[0], y)
+		case 'V':
+			lineTo(x, oy+args[0])
+		case 'C', 'S':
+			var c1x, c1y, c2x, c2y, ex, ey float64
+			if upper == 'C' {
+				c1x, c1y, c2x, c2y, ex, ey = ox+args[0], oy+args[1], ox+args[2], oy+args[3], ox+args[4], oy+args[5]
+			} else {
+				c1x, c1y, c2x, c2y, ex, ey = rx, ry, ox+args[0], oy+args[1], ox+args[2], oy+args[3]
+			}
+			x0, y0 := x, y
+			n := curveSegments(flatness, x0, y0, c1x, c1y, c2x, c2y, ex, ey)
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				lineTo(u*u*u*x0+3*u*u*t*c1x+3*u*t*t*c2x+t*t*t*ex, u*u*u*y0+3*u*u*t*c1y+3*u*t*t*c2y+t*t*t*ey)
+			}
+			cx, cy = c2x, c2y
+		case 'Q', 'T':
+			var qx, qy, ex, ey float64
+			if upper == 'Q' {
+				qx, qy, ex, ey = ox+args[0], oy+args[1], ox+args[2], oy+args[3]
+			} else {
+				qx, qy, ex, ey = rx, ry, ox+args[0], oy+args[1]
+			}
+			x0, y0 := x, y
+			n := curveSegments(flatness, x0, y0, qx, qy, ex, ey)
+			for i := 1; i <= n; i++ {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				lineTo(u*u*x0+2*u*t*qx+t*t*ex, u*u*y0+2*u*t*qy+t*t*ey)
+			}
+			cx, cy = qx, qy
+		}
+		prev = cmd
+		if upper == 'M' {
+			prev = 'M'
+		}
+	}
+	flush()
+	return contours, nil
+}
+
+// curveSegments picks a segment count for a Bézier curve from the distance
+// of its control points to the chord, which bounds the curve's deviation.
+func curveSegments(flatness float64, pts ...float64) int {
+	n := len(pts)
+	x0, y0, x1, y1 := pts[0], pts[1], pts[n-2], pts[n-1]
+	dx, dy := x1-x0, y1-y0
+	chord := math.Hypot(dx, dy)
+	var dev float64
+	for i := 2; i < n-2; i += 2 {
+		px, py := pts[i]-x0, pts[i+1]-y0
+		if chord > 0 {
+			dev = math.Max(dev, math.Abs(px*dy-py*dx)/chord)
+		} else {
+			dev = math.Max(dev, math.Hypot(px, py))
+		}
+	}
+	if flatness <= 0 {
+		flatness = 0.1
+	}
+	segments := int(math.Ceil(math.Sqrt(dev / flatness)))
+	return min(max(segments, 1), 256)
+}
@@ -0,0 +1,217 @@
+// Command tess tessellates contours read from a file and writes the result.
+//
+// Usage:
+//
+//	tess [flags] [input]
+//
+// Contours are read from input, or standard input if omitted, in one of these
+// formats (selected with -in or from the file extension):
+//
+//	json     {"size": 2, "contours": [[x, y, ...], ...]} or [[x, y, ...], ...]
+//	geojson  Polygon and MultiPolygon geometries, features and collections
+//	svg      path, polygon, polyline and rect elements
+//	text     one "x y [z]" vertex per line, contours separated by blank lines
+//
+// The result is written as JSON, Wavefront OBJ or SVG (see -out).
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tess: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// config holds the parsed command line.
+type config struct {
+	input, output string
+	inFormat      string
+	outFormat     string
+	windingRule   tess.WindingRule
+	elementType   tess.ElementType
+	polySize      int
+	vertexSize    int
+	normal        []float32
+	cdt, reverse  bool
+	canonical     bool
+	flatness      float64
+}
+
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	fs := flag.NewFlagSet("tess", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: tess [flags] [input]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	c := &config{}
+	winding := fs.String("winding", "odd", "winding rule: odd, nonzero, positive, negative or absgeqtwo")
+	element := fs.String("element", "polygons", "element type: polygons, connectedpolygons or boundarycontours")
+	normal := fs.String("normal", "", "projection normal as x,y,z (computed if empty)")
+	fs.StringVar(&c.inFormat, "in", "", "input format: json, geojson, svg or text (default from the file extension, else json)")
+	fs.StringVar(&c.outFormat, "out", "json", "output format: json, obj or svg")
+	fs.StringVar(&c.output, "o", "", "output file (default standard output)")
+	fs.IntVar(&c.polySize, "polysize", 3, "maximum vertices per polygon")
+	fs.IntVar(&c.vertexSize, "vertexsize", 0, "output coordinates per vertex, 2 or 3 (default the input size)")
+	fs.BoolVar(&c.cdt, "cdt", false, "enable OptionConstrainedDelaunay")
+	fs.BoolVar(&c.reverse, "reverse", false, "enable OptionReverseContours")
+	fs.BoolVar(&c.canonical, "canonical", false, "enable OptionCanonicalOrder for output that can be diffed")
+	fs.Float64Var(&c.flatness, "flatness", 0.1, "maximum deviation when flattening SVG curves")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		c.input = fs.Arg(0)
+	default:
+		return nil, fmt.Errorf("expected at most one input file, got %d", fs.NArg())
+	}
+
+	var err error
+	if err = parseName(*winding, &c.windingRule); err != nil {
+		return nil, err
+	}
+	if err = parseName(*element, &c.elementType); err != nil {
+		return nil, err
+	}
+	if *normal != "" {
+		if c.normal, err = parseFloats(*normal, 3); err != nil {
+			return nil, fmt.Errorf("invalid -normal: %w", err)
+		}
+	}
+	if c.inFormat == "" {
+		c.inFormat = formatFromExtension(c.input)
+	}
+	return c, nil
+}
+
+// parseName parses an enumeration flag with the library's JSON decoding, so
+// that flags accept the same names as JSON documents.
+func parseName(s string, v json.Unmarshaler) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalJSON(data)
+}
+
+// parseFloats parses n comma separated numbers.
+func parseFloats(s string, n int) ([]float32, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma separated numbers, got %q", n, s)
+	}
+	out := make([]float32, n)
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = float32(f)
+	}
+	return out, nil
+}
+
+func formatFromExtension(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".geojson":
+		return "geojson"
+	case ".svg":
+		return "svg"
+	case ".txt":
+		return "text"
+	}
+	return "json"
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	c, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	in := stdin
+	if c.input != "" {
+		f, err := os.Open(c.input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	input, err := readInput(in, c.inFormat, c.flatness)
+	if err != nil {
+		return err
+	}
+	if c.vertexSize == 0 {
+		c.vertexSize = input.size
+	}
+
+	result, err := tessellate(c, input)
+	if err != nil {
+		return err
+	}
+
+	if c.output == "" {
+		return writeOutput(stdout, c.outFormat, input, result)
+	}
+	f, err := os.Create(c.output)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(f, c.outFormat, input, result); err != nil {
+		f.Close()
+		return err
+	}
+	// Close reports write errors the file system deferred.
+	return f.Close()
+}
+
+func tessellate(c *config, input *contourSet) (*tess.Result, error) {
+	t := tess.NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	if err := t.SetOption(tess.OptionConstrainedDelaunay, c.cdt); err != nil {
+		return nil, err
+	}
+	if err := t.SetOption(tess.OptionReverseContours, c.reverse); err != nil {
+		return nil, err
+	}
+	if err := t.SetOption(tess.OptionCanonicalOrder, c.canonical); err != nil {
+		return nil, err
+	}
+	for i, contour := range input.contours {
+		if len(contour) == 0 {
+			continue
+		}
+		if err := t.AddContour(input.size, contour); err != nil {
+			return nil, fmt.Errorf("contour %d: %w", i, err)
+		}
+	}
+	return t.TessellateResult(c.windingRule, c.elementType, c.polySize, c.vertexSize, c.normal)
+}
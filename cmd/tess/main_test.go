@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+const squareWithHole = `# outer
+0 0
+4 0
+4 4
+0 4
+
+# hole
+1 1
+1 3
+3 3
+3 1
+`
+
+func contourArea(c []float32, size int) float64 {
+	var a float64
+	n := len(c) / size
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		a += float64(c[i*size])*float64(c[j*size+1]) - float64(c[j*size])*float64(c[i*size+1])
+	}
+	return a / 2
+}
+
+// TestReadInput tests that every input format yields the expected contours
+func TestReadInput(t *testing.T) {
+	square := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+	tests := []struct {
+		name, format, data string
+		size               int
+		contours           [][]float32
+	}{
+		{"json object", "json", `{"size": 3, "contours": [[0, 0, 1, 4, 0, 1, 4, 4, 1]]}`, 3, [][]float32{{0, 0, 1, 4, 0, 1, 4, 4, 1}}},
+		{"json array", "json", `[[0, 0, 4, 0, 4, 4, 0, 4]]`, 2, [][]float32{square}},
+		{"text", "text", "0 0\n4,0\n4 4 # corner\n0 4\n\n\n1 1\n2 1\n2 2\n", 2, [][]float32{square, {1, 1, 2, 1, 2, 2}}},
+		{"text 3d", "text", "0 0 1\n4 0 1\n4 4 1\n", 3, [][]float32{{0, 0, 1, 4, 0, 1, 4, 4, 1}}},
+		{"geojson polygon", "geojson", `{"type": "Polygon", "coordinates": [[[0, 0], [4, 0], [4, 4], [0, 4], [0, 0]]]}`, 2, [][]float32{square}},
+		{"geojson collection", "geojson", `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "geometry": {"type": "MultiPolygon", "coordinates": [[[[0, 0, 5], [4, 0, 5], [4, 4, 5], [0, 0, 5]]], [[[1, 1, 5], [2, 1, 5], [2, 2, 5]]]]}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [9, 9]}},
+			{"type": "Feature", "geometry": null}]}`, 3, [][]float32{{0, 0, 5, 4, 0, 5, 4, 4, 5}, {1, 1, 5, 2, 1, 5, 2, 2, 5}}},
+		{"svg shapes", "svg", `<svg xmlns="http://www.w3.org/2000/svg">
+			<g><polygon points="0,0 4,0 4,4 0,4"/></g>
+			<polyline points="1 1 2 1 2 2"/>
+			<rect y="1" width="2" height="3"/></svg>`, 2, [][]float32{square, {1, 1, 2, 1, 2, 2}, {0, 1, 2, 1, 2, 4, 0, 4}}},
+		{"svg path", "svg", `<svg><path d="M0,0 H4 V4 L0 4 Z m1 1 l1 0 l0 1z"/></svg>`, 2, [][]float32{{0, 0, 4, 0, 4, 4, 0, 4}, {1, 1, 2, 1, 2, 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := readInput(strings.NewReader(tt.data), tt.format, 0.1)
+			if err != nil {
+				t.Fatalf("readInput failed: %v", err)
+			}
+			if cs.size != tt.size {
+				t.Errorf("Expected size %d, got %d", tt.size, cs.size)
+			}
+			if !reflect.DeepEqual(cs.contours, tt.contours) {
+				t.Errorf("Expected contours %v, got %v", tt.contours, cs.contours)
+			}
+		})
+	}
+}
+
+// TestReadInputErrors tests that malformed input is rejected
+func TestReadInputErrors(t *testing.T) {
+	tests := []struct{ format, data string }{
+		{"json", `{"size": 4, "contours": [[0, 0, 0, 0]]}`},
+		{"json", `{"contours": [[0, 0, 1]]}`},
+		{"json", `{"contours": []}`},
+		{"text", "0 0\n1 1 1\n"},
+		{"text", "0 zero\n"},
+		{"geojson", `{"coordinates": []}`},
+		{"geojson", `{"type": "Polygon", "coordinates": [[[0]]]}`},
+		{"svg", `<svg><path d="M0 0 A 1 1 0 0 1 2 2"/></svg>`},
+		{"svg", `<svg><path d="10 10"/></svg>`},
+		{"svg", `<svg><polygon points="0 0 1"/></svg>`},
+		{"svg", `<svg><rect width="wide"/></svg>`},
+		{"xml", ``},
+	}
+	for _, tt := range tests {
+		if _, err := readInput(strings.NewReader(tt.data), tt.format, 0.1); err == nil {
+			t.Errorf("Expected error for %s input %q", tt.format, tt.data)
+		}
+	}
+}
+
+// TestParsePathCurves tests curve flattening and smooth curve reflection
+func TestParsePathCurves(t *testing.T) {
+	// A circle of radius 10 drawn with four cubic segments.
+	const k = 5.5228475
+	circle := fmt.Sprintf("M10 0 C10 %[1]g %[1]g 10 0 10 S-10 %[1]g -10 0 s%[2]g-10 10-10 S10-%[1]g 10 0z", k, 10-k)
+	for _, flatness := range []float64{1, 0.01} {
+		contours, err := parsePath(circle, flatness)
+		if err != nil {
+			t.Fatalf("parsePath failed: %v", err)
+		}
+		if len(contours) != 1 {
+			t.Fatalf("Expected one contour, got %d", len(contours))
+		}
+		c := contours[0]
+		for i := 0; i < len(c); i += 2 {
+			if r := math.Hypot(float64(c[i]), float64(c[i+1])); math.Abs(r-10) > flatness+0.01 {
+				t.Errorf("Flatness %v: vertex (%v, %v) is %v from the centre", flatness, c[i], c[i+1], r)
+			}
+		}
+		if a := contourArea(c, 2); math.Abs(a-math.Pi*100) > 2*math.Pi*10*flatness {
+			t.Errorf("Flatness %v: expected area near %v, got %v", flatness, math.Pi*100, a)
+		}
+	}
+
+	// Quadratic curves: T reflects the previous control point.
+	contours, err := parsePath("M0 0 Q1 1 2 0 T4 0", 0.001)
+	if err != nil {
+		t.Fatalf("parsePath failed: %v", err)
+	}
+	c := contours[0]
+	if c[len(c)-2] != 4 || c[len(c)-1] != 0 {
+		t.Errorf("Expected the path to end at (4, 0), got (%v, %v)", c[len(c)-2], c[len(c)-1])
+	}
+	var below bool
+	for i := 0; i < len(c); i += 2 {
+		below = below || c[i+1] < -0.1
+	}
+	if !below {
+		t.Error("Expected the smooth quadratic to dip below the x axis")
+	}
+
+	// Numbers without separators.
+	contours, err = parsePath("M0-1.5.5.5L1e1,0", 0.1)
+	if err != nil {
+		t.Fatalf("parsePath failed: %v", err)
+	}
+	if want := []float32{0, -1.5, 0.5, 0.5, 10, 0}; !reflect.DeepEqual(contours[0], want) {
+		t.Errorf("Expected %v, got %v", want, contours[0])
+	}
+}
+
+// TestRunJSON tests an end to end run with JSON output
+func TestRunJSON(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "shape.txt")
+	if err := os.WriteFile(input, []byte(squareWithHole), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"-winding", "nonzero", input}, nil, &out, io.Discard); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	var doc jsonResult
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if doc.ElementType != "Polygons" || doc.PolySize != 3 || doc.VertexSize != 2 {
+		t.Errorf("Unexpected header %+v", doc)
+	}
+	if len(doc.Vertices) != 16 || len(doc.VertexIndices) != 8 {
+		t.Errorf("Expected 8 vertices, got %d coordinates and %d indices", len(doc.Vertices), len(doc.VertexIndices))
+	}
+	var area float64
+	for i := 0; i+2 < len(doc.Indices); i += 3 {
+		tri := []float32{}
+		for _, v := range doc.Indices[i : i+3] {
+			tri = append(tri, doc.Vertices[2*v], doc.Vertices[2*v+1])
+		}
+		area += contourArea(tri, 2)
+	}
+	// The hole is clockwise, so NonZero leaves it empty.
+	if math.Abs(area-12) > 1e-4 {
+		t.Errorf("Expected area 12, got %v", area)
+	}
+
+	// Reversing the contours makes the outer contour clockwise, which is
+	// what Negative selects.
+	out.Reset()
+	if err := run([]string{"-winding", "negative", "-reverse", "-vertexsize", "3", "-normal", "0,0,1", "-in", "text"}, strings.NewReader(squareWithHole), &out, io.Discard); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if doc.VertexSize != 3 || len(doc.Indices) != 8*3 {
+		t.Errorf("Expected eight triangles in 3D, got size %d and %d indices", doc.VertexSize, len(doc.Indices))
+	}
+
+	// Canonical output lists vertices in coordinate order.
+	out.Reset()
+	if err := run([]string{"-canonical", "-in", "text"}, strings.NewReader(squareWithHole), &out, io.Discard); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	doc = jsonResult{}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	for i := 2; i < len(doc.Vertices); i += 2 {
+		if slices.Compare(doc.Vertices[i-2:i], doc.Vertices[i:i+2]) > 0 {
+			t.Errorf("Vertices %v are not sorted", doc.Vertices)
+			break
+		}
+	}
+}
+
+// TestRunFormats tests OBJ and SVG output and writing to a file
+func TestRunFormats(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"-in", "text", "-out", "obj", "-polysize", "4", "-element", "connectedpolygons", "-cdt"}, strings.NewReader(squareWithHole), &out, io.Discard); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	var vertices, faces int
+	for _, line := range strings.Split(out.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "v":
+			vertices++
+			if len(fields) != 4 {
+				t.Errorf("Expected three coordinates in %q", line)
+			}
+		case "f":
+			faces++
+			if len(fields) < 4 || len(fields) > 5 {
+				t.Errorf("Expected a face with 3 or 4 vertices, got %q", line)
+			}
+		}
+	}
+	if vertices != 8 || faces == 0 {
+		t.Errorf("Expected 8 vertices and some faces, got %d and %d", vertices, faces)
+	}
+
+	out.Reset()
+	if err := run([]string{"-in", "text", "-out", "obj", "-element", "boundarycontours"}, strings.NewReader(squareWithHole), &out, io.Discard); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if n := strings.Count(out.String(), "\nl "); n != 2 {
+		t.Errorf("Expected two boundary polylines, got %d:\n%s", n, out.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "out.svg")
+	if err := run([]string{"-in", "json", "-out", "svg", "-o", path}, strings.NewReader(`[[0,0,4,0,4,4,0,4]]`), &out, io.Discard); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("<svg")) || !bytes.Contains(data, []byte("Polygons")) {
+		t.Errorf("Unexpected SVG output:\n%s", data)
+	}
+}
+
+// TestRunErrors tests invalid command lines
+func TestRunErrors(t *testing.T) {
+	tests := [][]string{
+		{"-winding", "even"},
+		{"-element", "triangles"},
+		{"-normal", "0,1"},
+		{"-out", "stl"},
+		{"a.txt", "b.txt"},
+		{filepath.Join(t.TempDir(), "missing.txt")},
+	}
+	for _, args := range tests {
+		if err := run(args, strings.NewReader(squareWithHole), &bytes.Buffer{}, io.Discard); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+
+	// Flag errors and usage go to the given stderr.
+	var stderr bytes.Buffer
+	if err := run([]string{"-bogus"}, strings.NewReader(squareWithHole), &bytes.Buffer{}, &stderr); err == nil {
+		t.Error("Expected error for an unknown flag")
+	}
+	if !strings.Contains(stderr.String(), "-bogus") || !strings.Contains(stderr.String(), "Usage") {
+		t.Errorf("Expected the flag error and usage on stderr, got %q", stderr.String())
+	}
+
+	// Failed writes to the output file are reported.
+	if _, err := os.Stat("/dev/full"); err == nil {
+		if err := run([]string{"-in", "text", "-o", "/dev/full"}, strings.NewReader(squareWithHole), &bytes.Buffer{}, io.Discard); err == nil {
+			t.Error("Expected error writing to /dev/full")
+		}
+	}
+}
+
+// TestParseNames tests that flag values match the library's names
+func TestParseNames(t *testing.T) {
+	var w tess.WindingRule
+	if err := parseName("AbsGeqTwo", &w); err != nil || w != tess.WindingAbsGeqTwo {
+		t.Errorf("Expected WindingAbsGeqTwo, got %v, %v", w, err)
+	}
+	var e tess.ElementType
+	if err := parseName("boundarycontours", &e); err != nil || e != tess.ElementBoundaryContours {
+		t.Errorf("Expected ElementBoundaryContours, got %v, %v", e, err)
+	}
+	if err := parseName("even", &w); err == nil || !strings.Contains(err.Error(), "winding rule") {
+		t.Errorf("Expected an unknown winding rule error, got %v", err)
+	}
+	for name, want := range map[string]string{"a.geojson": "geojson", "A.SVG": "svg", "b.txt": "text", "c.json": "json", "": "json"} {
+		if got := formatFromExtension(name); got != want {
+			t.Errorf("formatFromExtension(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	tess "github.com/mikijov/go-libtess2"
+	"github.com/mikijov/go-libtess2/debugdraw"
+)
+
+func writeOutput(w io.Writer, format string, input *contourSet, r *tess.Result) error {
+	switch format {
+	case "json":
+		return writeJSON(w, r)
+	case "obj":
+		return writeOBJ(w, r)
+	case "svg":
+		scene := &debugdraw.Scene{
+			Contours:    input.contours,
+			ContourSize: input.size,
+			Result:      r,
+			Title:       fmt.Sprintf("%d vertices, %s", r.VertexCount(), r.ElementType),
+		}
+		return scene.WriteSVG(w, nil)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// jsonResult is the JSON output document.
+type jsonResult struct {
+	VertexSize    int       `json:"vertexSize"`
+	ElementType   string    `json:"elementType"`
+	PolySize      int       `json:"polySize"`
+	Vertices      []float32 `json:"vertices"`
+	Indices       []int     `json:"indices"`
+	VertexIndices []int     `json:"vertexIndices"`
+}
+
+func writeJSON(w io.Writer, r *tess.Result) error {
+	doc := jsonResult{
+		VertexSize:    r.VertexSize,
+		ElementType:   r.ElementType.String(),
+		PolySize:      r.PolySize,
+		Vertices:      r.Vertices,
+		Indices:       r.Indices,
+		VertexIndices: r.VertexIndices,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// writeOBJ writes the result as a Wavefront OBJ file. Polygons become faces
+// and boundary contours become closed polylines.
+func writeOBJ(w io.Writer, r *tess.Result) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d vertices, %s\n", r.VertexCount(), r.ElementType)
+	for v := 0; v < r.VertexCount(); v++ {
+		p := r.Vertices[v*r.VertexSize : (v+1)*r.VertexSize]
+		z := float32(0)
+		if r.VertexSize > 2 {
+			z = p[2]
+		}
+		fmt.Fprintf(bw, "v %s %s %s\n", formatFloat(p[0]), formatFloat(p[1]), formatFloat(z))
+	}
+
+	switch r.ElementType {
+	case tess.ElementPolygons, tess.ElementConnectedPolygons:
+		stride := r.PolySize
+		if r.ElementType == tess.ElementConnectedPolygons {
+			stride *= 2
+		}
+		for i := 0; i+r.PolySize <= len(r.Indices); i += stride {
+			bw.WriteString("f")
+			for _, v := range r.Indices[i : i+r.PolySize] {
+				if v == tess.Undef {
+					break
+				}
+				fmt.Fprintf(bw, " %d", v+1)
+			}
+			bw.WriteString("\n")
+		}
+	case tess.ElementBoundaryContours:
+		for i := 0; i+1 < len(r.Indices); i += 2 {
+			base, count := r.Indices[i], r.Indices[i+1]
+			if count == 0 {
+				continue
+			}
+			bw.WriteString("l")
+			for k := 0; k <= count; k++ {
+				fmt.Fprintf(bw, " %d", base+k%count+1)
+			}
+			bw.WriteString("\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func formatFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
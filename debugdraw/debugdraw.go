@@ -0,0 +1,371 @@
+// Package debugdraw renders tessellation input and output for debugging.
+//
+// A Scene holds the contours passed to the tessellator and the Result it
+// produced. It can be written as SVG, with contour orientation arrows and
+// vertex numbers, or rasterized to PNG with the standard image package.
+// DumpOnFailure writes both next to a failing test.
+package debugdraw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+// Scene is the input and output of one tessellation.
+type Scene struct {
+	// Contours are the input contours with ContourSize coordinates per
+	// vertex, as passed to AddContour.
+	Contours    [][]float32
+	ContourSize int
+	// Result is the tessellator output, or nil to draw only the input.
+	Result *tess.Result
+	// Title is shown at the top of the drawing.
+	Title string
+}
+
+// Options controls the rendered output.
+type Options struct {
+	// Width and Height are the size of the drawing in pixels. Defaults to
+	// 800x800.
+	Width, Height int
+	// NoLabels disables vertex numbers in SVG output.
+	NoLabels bool
+}
+
+const margin = 24
+
+func (o *Options) size() (int, int) {
+	w, h := 800, 800
+	if o != nil && o.Width > 0 {
+		w = o.Width
+	}
+	if o != nil && o.Height > 0 {
+		h = o.Height
+	}
+	return w, h
+}
+
+type point struct{ x, y float64 }
+
+// layout holds the scene transformed into drawing coordinates.
+type layout struct {
+	contours [][]point
+	// polygons are output polygons, or boundary contours when boundary is
+	// set, as rings of output vertex indices.
+	polygons [][]int
+	boundary bool
+	vertices []point
+	// created marks output vertices created by the tessellator.
+	created []bool
+}
+
+// project returns a function mapping a vertex to 2D scene coordinates. 3D
+// data is drawn in the plane libtess2 projected it onto.
+func (s *Scene) project(size int) func([]float32) point {
+	if size == 3 && s.Result != nil {
+		p := s.Result.Projection
+		if p.BasisS != ([3]float32{}) && p.BasisT != ([3]float32{}) {
+			return func(v []float32) point {
+				return point{
+					float64(v[0]*p.BasisS[0] + v[1]*p.BasisS[1] + v[2]*p.BasisS[2]),
+					float64(v[0]*p.BasisT[0] + v[1]*p.BasisT[1] + v[2]*p.BasisT[2]),
+				}
+			}
+		}
+	}
+	return func(v []float32) point { return point{float64(v[0]), float64(v[1])} }
+}
+
+// layout maps the scene into a width x height drawing with y pointing down.
+func (s *Scene) layout(width, height int) (*layout, error) {
+	l := &layout{}
+	size := s.ContourSize
+	if size == 0 {
+		size = 2
+	}
+	if size != 2 && size != 3 {
+		return nil, fmt.Errorf("contour size must be 2 or 3, got %d", size)
+	}
+
+	proj := s.project(size)
+	for i, c := range s.Contours {
+		if len(c)%size != 0 {
+			return nil, fmt.Errorf("contour %d: len(vertices)(%d) must be multiple of size (%d)", i, len(c), size)
+		}
+		var ring []point
+		for k := 0; k+size <= len(c); k += size {
+			ring = append(ring, proj(c[k:k+size]))
+		}
+		l.contours = append(l.contours, ring)
+	}
+
+	if r := s.Result; r != nil {
+		if r.VertexSize != 2 && r.VertexSize != 3 {
+			return nil, fmt.Errorf("result vertex size must be 2 or 3, got %d", r.VertexSize)
+		}
+		proj := s.project(r.VertexSize)
+		n := r.VertexCount()
+		for i := 0; i < n; i++ {
+			l.vertices = append(l.vertices, proj(r.Vertices[i*r.VertexSize:(i+1)*r.VertexSize]))
+			l.created = append(l.created, i < len(r.VertexIndices) && r.VertexIndices[i] == tess.Undef)
+		}
+		if err := l.elements(r); err != nil {
+			return nil, err
+		}
+	}
+
+	l.fit(width, height)
+	return l, nil
+}
+
+// elements collects output polygons or boundary contours as vertex rings.
+func (l *layout) elements(r *tess.Result) error {
+	n := len(l.vertices)
+	add := func(ids []int) error {
+		var ring []int
+		for _, v := range ids {
+			if v == tess.Undef {
+				break
+			}
+			if v < 0 || v >= n {
+				return fmt.Errorf("element references vertex %d out of %d", v, n)
+			}
+			ring = append(ring, v)
+		}
+		l.polygons = append(l.polygons, ring)
+		return nil
+	}
+
+	switch r.ElementType {
+	case tess.ElementPolygons, tess.ElementConnectedPolygons:
+		stride := r.PolySize
+		if r.ElementType == tess.ElementConnectedPolygons {
+			stride *= 2
+		}
+		if r.PolySize <= 0 {
+			return fmt.Errorf("polySize must be positive, got %d", r.PolySize)
+		}
+		for e := 0; e+stride <= len(r.Indices); e += stride {
+			if err := add(r.Indices[e : e+r.PolySize]); err != nil {
+				return err
+			}
+		}
+	case tess.ElementBoundaryContours:
+		l.boundary = true
+		for e := 0; e+1 < len(r.Indices); e += 2 {
+			base, count := r.Indices[e], r.Indices[e+1]
+			if base < 0 || count < 0 || base+count > n {
+				return fmt.Errorf("contour %d references vertices %d to %d out of %d", e/2, base, base+count, n)
+			}
+			ids := make([]int, count)
+			for k := range ids {
+				ids[k] = base + k
+			}
+			if err := add(ids); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported element type: %v", r.ElementType)
+	}
+	return nil
+}
+
+// fit scales and translates all points into the drawing area.
+func (l *layout) fit(width, height int) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	l.each(func(p *point) {
+		minX, minY = math.Min(minX, p.x), math.Min(minY, p.y)
+		maxX, maxY = math.Max(maxX, p.x), math.Max(maxY, p.y)
+	})
+	if minX > maxX {
+		return
+	}
+
+	w, h := float64(width-2*margin), float64(height-2*margin)
+	scale := math.Min(w/math.Max(maxX-minX, 1e-9), h/math.Max(maxY-minY, 1e-9))
+	offX := margin + (w-(maxX-minX)*scale)/2
+	offY := margin + (h-(maxY-minY)*scale)/2
+	l.each(func(p *point) {
+		p.x = offX + (p.x-minX)*scale
+		p.y = float64(height) - (offY + (p.y-minY)*scale)
+	})
+}
+
+// each calls fn for every distinct point of the layout.
+func (l *layout) each(fn func(*point)) {
+	for _, c := range l.contours {
+		for i := range c {
+			fn(&c[i])
+		}
+	}
+	for i := range l.vertices {
+		fn(&l.vertices[i])
+	}
+}
+
+// ring returns the points of output polygon i.
+func (l *layout) ring(i int) []point {
+	ring := make([]point, len(l.polygons[i]))
+	for k, v := range l.polygons[i] {
+		ring[k] = l.vertices[v]
+	}
+	return ring
+}
+
+// palette returns a fill colour for element i as r, g, b.
+func palette(i int) (uint8, uint8, uint8) {
+	colors := [][3]uint8{
+		{166, 206, 227}, {178, 223, 138}, {251, 154, 153}, {253, 191, 111},
+		{202, 178, 214}, {255, 255, 153}, {141, 211, 199}, {190, 186, 218},
+	}
+	c := colors[i%len(colors)]
+	return c[0], c[1], c[2]
+}
+
+// WriteSVG writes the scene as an SVG document.
+func (s *Scene) WriteSVG(w io.Writer, opts *Options) error {
+	width, height := opts.size()
+	l, err := s.layout(width, height)
+	if err != nil {
+		return err
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#1f4e9c"/></marker></defs>` + "\n")
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+
+	for i := range l.polygons {
+		ring := l.ring(i)
+		if l.boundary {
+			fmt.Fprintf(b, `<polygon points="%s" fill="none" stroke="#d95f02" stroke-width="2"/>`+"\n", svgPoints(ring))
+			continue
+		}
+		r, g, bl := palette(i)
+		fmt.Fprintf(b, `<polygon points="%s" fill="rgb(%d,%d,%d)" stroke="#555" stroke-width="0.75"/>`+"\n", svgPoints(ring), r, g, bl)
+	}
+
+	for ci, c := range l.contours {
+		fmt.Fprintf(b, `<polygon points="%s" fill="none" stroke="#1f4e9c" stroke-width="1.5" stroke-dasharray="6 3"/>`+"\n", svgPoints(c))
+		// Arrows at the middle of each edge show the contour direction.
+		for i := range c {
+			p, q := c[i], c[(i+1)%len(c)]
+			mx, my := (p.x+q.x)/2, (p.y+q.y)/2
+			dx, dy := q.x-p.x, q.y-p.y
+			if d := math.Hypot(dx, dy); d > 12 {
+				dx, dy = dx/d*6, dy/d*6
+				fmt.Fprintf(b, `<line x1="%.2f" y1="%.2f" x2="%.2f" y2="%.2f" stroke="#1f4e9c" stroke-width="1.5" marker-end="url(#arrow)"/>`+"\n", mx-dx, my-dy, mx+dx, my+dy)
+			}
+		}
+		if opts == nil || !opts.NoLabels {
+			for i, p := range c {
+				fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-family="monospace" font-size="10" fill="#1f4e9c">%d.%d</text>`+"\n", p.x+3, p.y-3, ci, i)
+			}
+		}
+	}
+
+	for i, p := range l.vertices {
+		if l.created[i] {
+			fmt.Fprintf(b, `<circle cx="%.2f" cy="%.2f" r="4" fill="none" stroke="#e31a1c" stroke-width="2"/>`+"\n", p.x, p.y)
+		} else {
+			fmt.Fprintf(b, `<circle cx="%.2f" cy="%.2f" r="2" fill="#333"/>`+"\n", p.x, p.y)
+		}
+		if opts == nil || !opts.NoLabels {
+			fmt.Fprintf(b, `<text x="%.2f" y="%.2f" font-family="monospace" font-size="10" fill="#333">%d</text>`+"\n", p.x+3, p.y+11, i)
+		}
+	}
+
+	if s.Title != "" {
+		fmt.Fprintf(b, `<text x="8" y="16" font-family="sans-serif" font-size="14">%s</text>`+"\n", escapeXML(s.Title))
+	}
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+func svgPoints(ring []point) string {
+	var sb strings.Builder
+	for i, p := range ring {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		fmt.Fprintf(&sb, "%.2f,%.2f", p.x, p.y)
+	}
+	return sb.String()
+}
+
+func escapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// WriteFiles writes the scene to prefix.svg and prefix.png.
+func (s *Scene) WriteFiles(prefix string, opts *Options) error {
+	for _, f := range []struct {
+		ext   string
+		write func(io.Writer, *Options) error
+	}{{".svg", s.WriteSVG}, {".png", s.WritePNG}} {
+		out, err := os.Create(prefix + f.ext)
+		if err != nil {
+			return err
+		}
+		if err := f.write(out, opts); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TB is the part of testing.TB used by DumpOnFailure.
+type TB interface {
+	Helper()
+	Failed() bool
+	Name() string
+	Logf(format string, args ...any)
+}
+
+// DumpOnFailure writes the scene as SVG and PNG if the test has failed and
+// logs where. Files go to the directory named by the TESS_DEBUGDRAW_DIR
+// environment variable, or to a new directory under os.TempDir, which
+// unlike the test's temporary directory is kept after the test ends. Call
+// it deferred or after the assertions of a test.
+func DumpOnFailure(tb TB, s *Scene) {
+	tb.Helper()
+	if !tb.Failed() {
+		return
+	}
+	name := strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(tb.Name())
+	dir := os.Getenv("TESS_DEBUGDRAW_DIR")
+	var err error
+	if dir == "" {
+		dir, err = os.MkdirTemp("", "tess-debugdraw-"+name+"-")
+	} else {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		tb.Logf("debugdraw: %v", err)
+		return
+	}
+	prefix := filepath.Join(dir, name)
+	if s.Title == "" {
+		titled := *s
+		titled.Title = tb.Name()
+		s = &titled
+	}
+	if err := s.WriteFiles(prefix, nil); err != nil {
+		tb.Logf("debugdraw: %v", err)
+		return
+	}
+	tb.Logf("debugdraw: wrote %s.svg and %s.png", prefix, prefix)
+}
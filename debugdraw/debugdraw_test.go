@@ -0,0 +1,211 @@
+package debugdraw
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+// bowtie returns a self-intersecting contour and its tessellation.
+func bowtie(t *testing.T, elementType tess.ElementType) *Scene {
+	t.Helper()
+	contour := []float32{0, 0, 10, 10, 10, 0, 0, 10}
+
+	tr := tess.NewTessellator()
+	defer tr.Delete()
+	if err := tr.AddContour(2, contour); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	r, err := tr.TessellateResult(tess.WindingOdd, elementType, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	return &Scene{Contours: [][]float32{contour}, ContourSize: 2, Result: r}
+}
+
+// TestWriteSVG tests the SVG elements for input, output and intersections
+func TestWriteSVG(t *testing.T) {
+	s := bowtie(t, tess.ElementPolygons)
+	s.Title = "bowtie <odd>"
+
+	var buf bytes.Buffer
+	if err := s.WriteSVG(&buf, nil); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+	svg := buf.String()
+
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("Expected an SVG document, got %q", svg[:40])
+	}
+	// Two output triangles plus the input contour.
+	if n := strings.Count(svg, "<polygon"); n != 3 {
+		t.Errorf("Expected 3 polygons, got %d", n)
+	}
+	// The crossing vertex is created by the tessellator.
+	if n := strings.Count(svg, `stroke="#e31a1c"`); n != 1 {
+		t.Errorf("Expected 1 intersection marker, got %d", n)
+	}
+	if n := strings.Count(svg, "marker-end"); n != 4 {
+		t.Errorf("Expected 4 orientation arrows, got %d", n)
+	}
+	if !strings.Contains(svg, ">0.3</text>") {
+		t.Error("Expected input vertex labels")
+	}
+	if !strings.Contains(svg, "bowtie &lt;odd&gt;") {
+		t.Error("Expected escaped title")
+	}
+
+	buf.Reset()
+	if err := s.WriteSVG(&buf, &Options{Width: 200, Height: 100, NoLabels: true}); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "0.3</text>") || !strings.Contains(buf.String(), `width="200" height="100"`) {
+		t.Error("Expected options to be applied")
+	}
+
+	boundary := bowtie(t, tess.ElementBoundaryContours)
+	buf.Reset()
+	if err := boundary.WriteSVG(&buf, nil); err != nil {
+		t.Fatalf("WriteSVG failed: %v", err)
+	}
+	if n := strings.Count(buf.String(), `stroke="#d95f02"`); n != 2 {
+		t.Errorf("Expected 2 boundary contours, got %d", n)
+	}
+}
+
+// TestImage tests rasterization of filled output
+func TestImage(t *testing.T) {
+	s := bowtie(t, tess.ElementPolygons)
+	img, err := s.Image(&Options{Width: 100, Height: 100})
+	if err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+
+	// The left triangle of the bowtie is filled; the top gap is not.
+	if c := img.RGBAAt(30, 50); c == (color.RGBA{255, 255, 255, 255}) {
+		t.Error("Expected filled pixel inside the left triangle")
+	}
+	if c := img.RGBAAt(50, 30); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected background above the crossing, got %v", c)
+	}
+	if c := img.RGBAAt(50, 50); c != createdColor {
+		t.Errorf("Expected intersection marker at the centre, got %v", c)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WritePNG(&buf, nil); err != nil {
+		t.Fatalf("WritePNG failed: %v", err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if b := decoded.Bounds(); b.Dx() != 800 || b.Dy() != 800 {
+		t.Errorf("Expected 800x800, got %v", b)
+	}
+}
+
+// TestInvalidScene tests error handling
+func TestInvalidScene(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Scene{Contours: [][]float32{{0, 0, 1}}}).WriteSVG(&buf, nil); err == nil {
+		t.Error("Expected error for odd coordinate count")
+	}
+	bad := &tess.Result{Vertices: []float32{0, 0}, Indices: []int{0, 1, 2}, VertexSize: 2, ElementType: tess.ElementPolygons, PolySize: 3}
+	if _, err := (&Scene{Result: bad}).Image(nil); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+	for _, indices := range [][]int{{0, -1}, {-1, 1}, {0, 2}} {
+		contours := &tess.Result{Vertices: []float32{0, 0}, Indices: indices, VertexSize: 2, ElementType: tess.ElementBoundaryContours}
+		if _, err := (&Scene{Result: contours}).Image(nil); err == nil {
+			t.Errorf("Expected error for contour %v", indices)
+		}
+	}
+}
+
+// fakeTB records what DumpOnFailure does.
+type fakeTB struct {
+	failed bool
+	logs   []string
+}
+
+func (f *fakeTB) Helper()      {}
+func (f *fakeTB) Failed() bool { return f.failed }
+func (f *fakeTB) Name() string { return "TestShape/sub case" }
+func (f *fakeTB) Logf(format string, args ...any) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+// TestDumpOnFailure tests that files are only written for failed tests
+func TestDumpOnFailure(t *testing.T) {
+	s := bowtie(t, tess.ElementPolygons)
+	dir := t.TempDir()
+	t.Setenv("TESS_DEBUGDRAW_DIR", dir)
+
+	tb := &fakeTB{}
+	DumpOnFailure(tb, s)
+	if len(tb.logs) != 0 {
+		t.Errorf("Expected nothing for a passing test, got %v", tb.logs)
+	}
+
+	tb.failed = true
+	DumpOnFailure(tb, s)
+	for _, ext := range []string{".svg", ".png"} {
+		path := filepath.Join(dir, "TestShape_sub_case"+ext)
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s: %v", path, err)
+		}
+	}
+	if len(tb.logs) != 1 || !strings.Contains(tb.logs[0], "TestShape_sub_case.svg") {
+		t.Errorf("Expected the paths to be logged, got %v", tb.logs)
+	}
+	if s.Title != "" {
+		t.Error("Expected the scene not to be modified")
+	}
+}
+
+// failedT is a real test reported as failed, recording the logged paths.
+type failedT struct {
+	*testing.T
+	logs *[]string
+}
+
+func (f failedT) Failed() bool { return true }
+func (f failedT) Logf(format string, args ...any) {
+	*f.logs = append(*f.logs, fmt.Sprintf(format, args...))
+}
+
+// TestDumpOnFailureKeepsFiles tests that the default directory outlives the test
+func TestDumpOnFailureKeepsFiles(t *testing.T) {
+	s := bowtie(t, tess.ElementPolygons)
+	t.Setenv("TESS_DEBUGDRAW_DIR", "")
+
+	var logs []string
+	t.Run("sub case", func(t *testing.T) {
+		DumpOnFailure(failedT{t, &logs}, s)
+	})
+	if len(logs) != 1 {
+		t.Fatalf("Expected the paths to be logged, got %v", logs)
+	}
+	var svg, png string
+	if _, err := fmt.Sscanf(logs[0], "debugdraw: wrote %s and %s", &svg, &png); err != nil {
+		t.Fatalf("Unexpected log %q: %v", logs[0], err)
+	}
+	dir := filepath.Dir(svg)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if !strings.HasPrefix(filepath.Base(dir), "tess-debugdraw-TestDumpOnFailureKeepsFiles_sub_case-") {
+		t.Errorf("Unexpected directory %s", dir)
+	}
+	for _, path := range []string{svg, png} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to outlive the subtest: %v", path, err)
+		}
+	}
+}
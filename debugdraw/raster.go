@@ -0,0 +1,146 @@
+package debugdraw
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+var (
+	contourColor  = color.RGBA{31, 78, 156, 255}
+	edgeColor     = color.RGBA{85, 85, 85, 255}
+	boundaryColor = color.RGBA{217, 95, 2, 255}
+	vertexColor   = color.RGBA{51, 51, 51, 255}
+	createdColor  = color.RGBA{227, 26, 28, 255}
+)
+
+// Image rasterizes the scene. Vertex numbers are only drawn in SVG output.
+func (s *Scene) Image(opts *Options) (*image.RGBA, error) {
+	width, height := opts.size()
+	l, err := s.layout(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for i := range l.polygons {
+		ring := l.ring(i)
+		if l.boundary {
+			strokeRing(img, ring, boundaryColor, false)
+			continue
+		}
+		r, g, b := palette(i)
+		fillPolygon(img, ring, color.RGBA{r, g, b, 255})
+		strokeRing(img, ring, edgeColor, false)
+	}
+
+	for _, c := range l.contours {
+		strokeRing(img, c, contourColor, true)
+	}
+
+	for i, p := range l.vertices {
+		if l.created[i] {
+			square(img, p, 3, createdColor)
+		} else {
+			square(img, p, 1, vertexColor)
+		}
+	}
+	return img, nil
+}
+
+// WritePNG writes the rasterized scene as PNG.
+func (s *Scene) WritePNG(w io.Writer, opts *Options) error {
+	img, err := s.Image(opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// fillPolygon fills a convex polygon as a fan of triangles.
+func fillPolygon(img *image.RGBA, ring []point, c color.RGBA) {
+	for k := 2; k < len(ring); k++ {
+		fillTriangle(img, ring[0], ring[k-1], ring[k], c)
+	}
+}
+
+// fillTriangle fills the pixels whose centres lie inside the triangle.
+func fillTriangle(img *image.RGBA, a, b, c point, col color.RGBA) {
+	area := (b.x-a.x)*(c.y-a.y) - (c.x-a.x)*(b.y-a.y)
+	if area == 0 {
+		return
+	}
+	r := img.Bounds()
+	minX := max(r.Min.X, int(math.Floor(math.Min(a.x, math.Min(b.x, c.x)))))
+	maxX := min(r.Max.X-1, int(math.Ceil(math.Max(a.x, math.Max(b.x, c.x)))))
+	minY := max(r.Min.Y, int(math.Floor(math.Min(a.y, math.Min(b.y, c.y)))))
+	maxY := min(r.Max.Y-1, int(math.Ceil(math.Max(a.y, math.Max(b.y, c.y)))))
+
+	edge := func(p, q point, x, y float64) float64 {
+		return ((q.x-p.x)*(y-p.y) - (x-p.x)*(q.y-p.y)) / area
+	}
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			if edge(a, b, px, py) >= 0 && edge(b, c, px, py) >= 0 && edge(c, a, px, py) >= 0 {
+				img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+// strokeRing draws the edges of a closed ring, with an arrow head at the
+// middle of each edge when arrows is set.
+func strokeRing(img *image.RGBA, ring []point, c color.RGBA, arrows bool) {
+	for i := range ring {
+		p, q := ring[i], ring[(i+1)%len(ring)]
+		line(img, p, q, c)
+		if !arrows {
+			continue
+		}
+		dx, dy := q.x-p.x, q.y-p.y
+		d := math.Hypot(dx, dy)
+		if d < 12 {
+			continue
+		}
+		dx, dy = dx/d, dy/d
+		tip := point{(p.x+q.x)/2 + 3*dx, (p.y+q.y)/2 + 3*dy}
+		for _, side := range []float64{-1, 1} {
+			back := point{tip.x - 6*dx - 4*side*dy, tip.y - 6*dy + 4*side*dx}
+			line(img, tip, back, c)
+		}
+	}
+}
+
+// line draws a one pixel wide line from p to q.
+func line(img *image.RGBA, p, q point, c color.RGBA) {
+	steps := int(math.Ceil(math.Max(math.Abs(q.x-p.x), math.Abs(q.y-p.y))))
+	if steps == 0 {
+		steps = 1
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		x := int(math.Floor(p.x + (q.x-p.x)*t))
+		y := int(math.Floor(p.y + (q.y-p.y)*t))
+		if image.Pt(x, y).In(img.Bounds()) {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// square draws a filled square of half size r centred on p.
+func square(img *image.RGBA, p point, r int, c color.RGBA) {
+	cx, cy := int(math.Floor(p.x)), int(math.Floor(p.y))
+	for y := cy - r; y <= cy+r; y++ {
+		for x := cx - r; x <= cx+r; x++ {
+			if image.Pt(x, y).In(img.Bounds()) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
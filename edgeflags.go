@@ -0,0 +1,47 @@
+package tess
+
+// maxEdgeFlagsPolySize is the largest polygon whose edges fit in a mask.
+const maxEdgeFlagsPolySize = 32
+
+// connectedEdgeFlags derives boundary edge masks from ElementConnectedPolygons
+// output, where a neighbour of Undef marks an edge without an adjacent
+// polygon. Bit k of a mask is set if the edge from vertex k to vertex k+1 of
+// the polygon is such a boundary edge. The polygons are returned with their
+// neighbours if keepNeighbours is set, and in the ElementPolygons layout
+// otherwise.
+func connectedEdgeFlags(elements []int, polySize int, keepNeighbours bool) (polygons []int, masks []uint32) {
+	stride := 2 * polySize
+	count := len(elements) / stride
+	masks = make([]uint32, count)
+	if keepNeighbours {
+		polygons = elements
+	} else {
+		polygons = make([]int, 0, count*polySize)
+	}
+	for e := 0; e < count; e++ {
+		poly := elements[e*stride : e*stride+polySize]
+		neighbours := elements[e*stride+polySize : (e+1)*stride]
+		for k, v := range poly {
+			if v == Undef {
+				break
+			}
+			if neighbours[k] == Undef {
+				masks[e] |= 1 << k
+			}
+		}
+		if !keepNeighbours {
+			polygons = append(polygons, poly...)
+		}
+	}
+	return polygons, masks
+}
+
+// IsBoundaryEdge reports whether the edge from vertex k to vertex k+1 of
+// polygon e lies on the boundary of the output region. It requires a result
+// tessellated with OptionEdgeFlags.
+func (r *Result) IsBoundaryEdge(e, k int) bool {
+	if e < 0 || e >= len(r.EdgeFlags) || k < 0 || k >= maxEdgeFlagsPolySize {
+		return false
+	}
+	return r.EdgeFlags[e]&(1<<k) != 0
+}
@@ -0,0 +1,165 @@
+package tess
+
+import (
+	"reflect"
+	"testing"
+)
+
+// edgeUses counts how many polygons use each undirected edge.
+func edgeUses(r *Result, stride int) map[[2]int]int {
+	uses := make(map[[2]int]int)
+	for e := 0; e+r.PolySize <= len(r.Indices); e += stride {
+		poly := r.Indices[e : e+r.PolySize]
+		n := 0
+		for n < len(poly) && poly[n] != Undef {
+			n++
+		}
+		for k := 0; k < n; k++ {
+			a, b := poly[k], poly[(k+1)%n]
+			uses[[2]int{min(a, b), max(a, b)}]++
+		}
+	}
+	return uses
+}
+
+// TestEdgeFlags tests that flagged edges are exactly those used by one polygon
+func TestEdgeFlags(t *testing.T) {
+	contours := [][]float32{
+		{0, 0, 10, 0, 10, 10, 0, 10},
+		{4, 4, 6, 4, 6, 6, 4, 6},
+		{0, 3, 6, 3, 1, 0, 3, 5, 5, 0},
+	}
+
+	for _, elementType := range []ElementType{ElementPolygons, ElementConnectedPolygons} {
+		for _, polySize := range []int{3, 4, 8} {
+			tess := NewTessellator()
+			for _, c := range contours {
+				if err := tess.AddContour(2, c); err != nil {
+					t.Fatalf("AddContour failed: %v", err)
+				}
+			}
+			if err := tess.SetOption(OptionEdgeFlags, true); err != nil {
+				t.Fatalf("SetOption failed: %v", err)
+			}
+			r, err := tess.TessellateResult(WindingOdd, elementType, polySize, 2, nil)
+			tess.Delete()
+			if err != nil {
+				t.Fatalf("TessellateResult failed: %v", err)
+			}
+
+			stride := polySize
+			if elementType == ElementConnectedPolygons {
+				stride *= 2
+			}
+			if len(r.EdgeFlags) != len(r.Indices)/stride {
+				t.Fatalf("%s/%d: expected %d masks, got %d", elementType, polySize, len(r.Indices)/stride, len(r.EdgeFlags))
+			}
+			uses := edgeUses(r, stride)
+			boundary := 0
+			for e := 0; e < len(r.EdgeFlags); e++ {
+				poly := r.Indices[e*stride : e*stride+polySize]
+				n := 0
+				for n < len(poly) && poly[n] != Undef {
+					n++
+				}
+				if r.EdgeFlags[e]>>n != 0 {
+					t.Errorf("%s/%d: polygon %d has flags %b beyond its %d edges", elementType, polySize, e, r.EdgeFlags[e], n)
+				}
+				for k := 0; k < n; k++ {
+					a, b := poly[k], poly[(k+1)%n]
+					single := uses[[2]int{min(a, b), max(a, b)}] == 1
+					if r.IsBoundaryEdge(e, k) != single {
+						t.Errorf("%s/%d: polygon %d edge %d: flagged %v, used once %v", elementType, polySize, e, k, r.IsBoundaryEdge(e, k), single)
+					}
+					if single {
+						boundary++
+					}
+				}
+			}
+			if boundary == 0 {
+				t.Errorf("%s/%d: expected boundary edges", elementType, polySize)
+			}
+		}
+	}
+}
+
+// TestEdgeFlagsLayout tests that edge flags leave the output unchanged
+func TestEdgeFlagsLayout(t *testing.T) {
+	contours := [][]float32{{0, 0, 10, 0, 10, 10, 0, 10}, {4, 4, 6, 4, 6, 6, 4, 6}}
+	for _, elementType := range []ElementType{ElementPolygons, ElementConnectedPolygons, ElementBoundaryContours} {
+		var results [2]*Result
+		for i, flags := range []bool{false, true} {
+			tess := NewTessellator()
+			if err := tess.SetOption(OptionEdgeFlags, flags); err != nil {
+				t.Fatalf("SetOption failed: %v", err)
+			}
+			for _, c := range contours {
+				if err := tess.AddContour(2, c); err != nil {
+					t.Fatalf("AddContour failed: %v", err)
+				}
+			}
+			r, err := tess.TessellateResult(WindingOdd, elementType, 5, 2, nil)
+			tess.Delete()
+			if err != nil {
+				t.Fatalf("TessellateResult failed: %v", err)
+			}
+			results[i] = r
+		}
+		plain, flagged := results[0], results[1]
+		if !reflect.DeepEqual(plain.Vertices, flagged.Vertices) || !reflect.DeepEqual(plain.Indices, flagged.Indices) {
+			t.Errorf("%s: edge flags changed the output", elementType)
+		}
+		if plain.EdgeFlags != nil {
+			t.Errorf("%s: expected no flags without the option", elementType)
+		}
+		if (flagged.EdgeFlags == nil) != (elementType == ElementBoundaryContours) {
+			t.Errorf("%s: unexpected flags %v", elementType, flagged.EdgeFlags)
+		}
+	}
+}
+
+// TestEdgeFlagsOption tests validation and reuse of the option
+func TestEdgeFlagsOption(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.SetOption(OptionEdgeFlags, true); err != nil {
+		t.Fatalf("SetOption failed: %v", err)
+	}
+	if err := tess.AddContour(2, []float32{0, 0, 1, 0, 1, 1}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	if _, err := tess.TessellateResult(WindingOdd, ElementPolygons, 33, 2, nil); err == nil {
+		t.Error("Expected error for polySize above 32")
+	}
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	if len(r.EdgeFlags) != 1 || r.EdgeFlags[0] != 0b111 {
+		t.Errorf("Expected a single triangle with three boundary edges, got %v", r.EdgeFlags)
+	}
+	if r.IsBoundaryEdge(1, 0) || r.IsBoundaryEdge(0, -1) || r.IsBoundaryEdge(0, 40) {
+		t.Error("Expected out of range edges not to be boundary edges")
+	}
+
+	// The option is part of a Job and reset by a Pool.
+	job := squareJob(2)
+	job.Options = []Option{OptionEdgeFlags}
+	if jobKey(tess, job) == jobKey(tess, squareJob(2)) {
+		t.Error("Expected the option to change the cache key")
+	}
+	p := NewPool(1)
+	defer p.Close()
+	pooled, _ := p.Get()
+	if r, err := job.Run(pooled); err != nil || len(r.EdgeFlags) != 2 {
+		t.Fatalf("Expected flags for two triangles, got %v, %v", r, err)
+	}
+	p.Put(pooled)
+	pooled, _ = p.Get()
+	if err := pooled.AddContour(2, []float32{0, 0, 1, 0, 1, 1}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	if r, err := pooled.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil); err != nil || r.EdgeFlags != nil {
+		t.Errorf("Expected no flags after Put, got %v, %v", r, err)
+	}
+}
@@ -0,0 +1,330 @@
+package tess
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The binary encoding of a Result starts with resultMagic and a version
+// byte, followed by the length of the payload as a uvarint. The payload
+// holds a flags byte and the element type, polySize and vertex size as
+// uvarints. The number of coordinates follows, then the quantization step
+// as a float32 if quantized, and the coordinates: little-endian float32s,
+// or when quantized varint deltas between the multiples of the step of
+// consecutive vertices. The indices and, if present, the vertex indices
+// follow, each preceded by its length and stored as varint deltas from the
+// previous index, which keeps neighbouring indices small.
+const (
+	resultMagic   = "TESR"
+	resultVersion = 1
+
+	resultHasVertexIndices = 1 << 0
+	resultQuantized        = 1 << 1
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Result)(nil)
+	_ encoding.BinaryUnmarshaler = (*Result)(nil)
+	_ io.WriterTo                = (*Result)(nil)
+	_ io.ReaderFrom              = (*Result)(nil)
+)
+
+// MarshalBinary encodes the vertices, indices, element type, polySize,
+// vertex size and vertex indices of the result in a compact, versioned
+// binary format. Projection, EdgeFlags, ContourIDs and Windings are not
+// encoded.
+func (r *Result) MarshalBinary() ([]byte, error) {
+	return r.marshal(0)
+}
+
+// MarshalQuantized encodes the result like MarshalBinary, rounding every
+// coordinate to a multiple of step. Quantized coordinates take a few bytes
+// each rather than four, and decode to within step/2 of the original.
+func (r *Result) MarshalQuantized(step float32) ([]byte, error) {
+	if !(step > 0) || math.IsInf(float64(step), 0) {
+		return nil, fmt.Errorf("quantization step must be positive and finite, got %v", step)
+	}
+	return r.marshal(step)
+}
+
+// WriteTo writes the result to w in the format of MarshalBinary.
+func (r *Result) WriteTo(w io.Writer) (int64, error) {
+	data, err := r.marshal(0)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// UnmarshalBinary decodes a result encoded by MarshalBinary or
+// MarshalQuantized, replacing the whole result.
+func (r *Result) UnmarshalBinary(data []byte) error {
+	if len(data) < len(resultMagic)+1 || string(data[:len(resultMagic)]) != resultMagic {
+		return fmt.Errorf("not an encoded tessellation result")
+	}
+	if v := data[len(resultMagic)]; v != resultVersion {
+		return fmt.Errorf("unsupported result encoding version %d", v)
+	}
+	data = data[len(resultMagic)+1:]
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size != uint64(len(data)-n) {
+		return fmt.Errorf("encoded result is truncated or has trailing data")
+	}
+	return r.unmarshal(data[n:])
+}
+
+// ReadFrom reads one result written by WriteTo, or encoded by MarshalBinary
+// or MarshalQuantized, from rd. It reads no further than the end of the
+// result, so results can be read one after another from a stream.
+func (r *Result) ReadFrom(rd io.Reader) (int64, error) {
+	header := make([]byte, len(resultMagic)+1)
+	n, err := io.ReadFull(rd, header)
+	read := int64(n)
+	if err != nil {
+		return read, err
+	}
+	if string(header[:len(resultMagic)]) != resultMagic {
+		return read, fmt.Errorf("not an encoded tessellation result")
+	}
+	if v := header[len(resultMagic)]; v != resultVersion {
+		return read, fmt.Errorf("unsupported result encoding version %d", v)
+	}
+	br := &byteReader{r: rd}
+	size, err := binary.ReadUvarint(br)
+	read += br.n
+	if err != nil {
+		return read, noEOF(err)
+	}
+	// The payload is copied rather than allocated up front so that a
+	// corrupt length cannot exhaust memory.
+	var payload bytes.Buffer
+	copied, err := io.CopyN(&payload, rd, int64(min(size, math.MaxInt64)))
+	read += copied
+	if err != nil {
+		return read, noEOF(err)
+	}
+	return read, r.unmarshal(payload.Bytes())
+}
+
+func (r *Result) marshal(step float32) ([]byte, error) {
+	size := r.VertexSize
+	if size < 0 || size > 3 || size == 0 && len(r.Vertices) > 0 || size > 0 && len(r.Vertices)%size != 0 {
+		return nil, fmt.Errorf("len(vertices)(%d) must be multiple of vertex size (%d)", len(r.Vertices), size)
+	}
+	if r.PolySize < 0 {
+		return nil, fmt.Errorf("polySize must not be negative, got %d", r.PolySize)
+	}
+	if !validElementType(r.ElementType) {
+		return nil, fmt.Errorf("invalid element type %d", r.ElementType)
+	}
+
+	var flags byte
+	if r.VertexIndices != nil {
+		flags |= resultHasVertexIndices
+	}
+	if step > 0 {
+		flags |= resultQuantized
+	}
+	payload := []byte{flags}
+	payload = binary.AppendUvarint(payload, uint64(r.ElementType))
+	payload = binary.AppendUvarint(payload, uint64(r.PolySize))
+	payload = binary.AppendUvarint(payload, uint64(size))
+
+	payload = binary.AppendUvarint(payload, uint64(len(r.Vertices)))
+	if step > 0 {
+		payload = binary.LittleEndian.AppendUint32(payload, math.Float32bits(step))
+		var last [3]int64
+		for i, f := range r.Vertices {
+			q := math.Round(float64(f) / float64(step))
+			if math.IsNaN(q) || math.Abs(q) > 1<<53 {
+				return nil, fmt.Errorf("vertex %d cannot be quantized with step %v: %v", i/size, step, f)
+			}
+			payload = binary.AppendVarint(payload, int64(q)-last[i%size])
+			last[i%size] = int64(q)
+		}
+	} else {
+		for _, f := range r.Vertices {
+			payload = binary.LittleEndian.AppendUint32(payload, math.Float32bits(f))
+		}
+	}
+	payload = appendIndices(payload, r.Indices)
+	if r.VertexIndices != nil {
+		payload = appendIndices(payload, r.VertexIndices)
+	}
+
+	data := make([]byte, 0, len(resultMagic)+1+binary.MaxVarintLen64+len(payload))
+	data = append(data, resultMagic...)
+	data = append(data, resultVersion)
+	data = binary.AppendUvarint(data, uint64(len(payload)))
+	return append(data, payload...), nil
+}
+
+// appendIndices appends the length of indices and their deltas.
+func appendIndices(b []byte, indices []int) []byte {
+	b = binary.AppendUvarint(b, uint64(len(indices)))
+	last := 0
+	for _, i := range indices {
+		b = binary.AppendVarint(b, int64(i-last))
+		last = i
+	}
+	return b
+}
+
+func (r *Result) unmarshal(payload []byte) error {
+	d := decoder{b: payload}
+	flags := d.uint8()
+	if flags&^(resultHasVertexIndices|resultQuantized) != 0 {
+		return fmt.Errorf("encoded result has unknown flags %#x", flags)
+	}
+	elementType := ElementType(d.uvarint())
+	polySize := int(d.uvarint())
+	size := int(d.uvarint())
+	count := d.length(1)
+	if d.err == nil && !validElementType(elementType) {
+		return fmt.Errorf("encoded result has invalid element type %d", elementType)
+	}
+	if d.err == nil && (size > 3 || size == 0 && count > 0 || size > 0 && count%size != 0) {
+		return fmt.Errorf("encoded result has %d coordinates of vertex size %d", count, size)
+	}
+
+	vertices := make([]float32, count)
+	if flags&resultQuantized != 0 {
+		step := float64(math.Float32frombits(d.uint32()))
+		var last [3]int64
+		for i := range vertices {
+			last[i%size] += d.varint()
+			vertices[i] = float32(float64(last[i%size]) * step)
+		}
+	} else {
+		for i := range vertices {
+			vertices[i] = math.Float32frombits(d.uint32())
+		}
+	}
+	indices := d.indices()
+	var vertexIndices []int
+	if flags&resultHasVertexIndices != 0 {
+		vertexIndices = d.indices()
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.b) > 0 {
+		return fmt.Errorf("encoded result has %d bytes of trailing data", len(d.b))
+	}
+
+	*r = Result{
+		Vertices:      vertices,
+		Indices:       indices,
+		VertexIndices: vertexIndices,
+		VertexSize:    size,
+		ElementType:   elementType,
+		PolySize:      polySize,
+	}
+	return nil
+}
+
+func validElementType(e ElementType) bool {
+	return e == ElementPolygons || e == ElementConnectedPolygons || e == ElementBoundaryContours
+}
+
+// decoder reads the fields of a payload, recording the first error and
+// returning zero values after it.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf("encoded result is truncated or corrupt")
+	}
+	d.b = nil
+}
+
+func (d *decoder) uint8() byte {
+	if len(d.b) < 1 {
+		d.fail()
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *decoder) uint32() uint32 {
+	if len(d.b) < 4 {
+		d.fail()
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 || v > math.MaxInt32 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+// length reads the length of a sequence of elements taking at least each
+// bytes, failing if the rest of the payload cannot hold them.
+func (d *decoder) length(each int) int {
+	n := int(d.uvarint())
+	if n > len(d.b)/each {
+		d.fail()
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) indices() []int {
+	indices := make([]int, d.length(1))
+	last := int64(0)
+	for i := range indices {
+		last += d.varint()
+		indices[i] = int(last)
+	}
+	return indices
+}
+
+// byteReader reads single bytes from r, counting them, so that a uvarint
+// can be read without reading ahead.
+type byteReader struct {
+	r io.Reader
+	n int64
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	n, err := io.ReadFull(b.r, buf[:])
+	b.n += int64(n)
+	return buf[0], err
+}
+
+// noEOF reports an EOF inside a result as unexpected.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+package tess
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// encodedResults tessellates the canonical contours into every element type.
+func encodedResults(t *testing.T) []*Result {
+	var results []*Result
+	for _, tt := range []struct {
+		elementType ElementType
+		polySize    int
+		vertexSize  int
+	}{
+		{ElementPolygons, 3, 2},
+		{ElementConnectedPolygons, 5, 3},
+		{ElementBoundaryContours, 0, 2},
+	} {
+		tess := NewTessellator()
+		for _, c := range canonicalContours() {
+			tess.AddContour(2, c)
+		}
+		r, err := tess.TessellateResult(WindingOdd, tt.elementType, tt.polySize, tt.vertexSize, nil)
+		tess.Delete()
+		if err != nil {
+			t.Fatalf("TessellateResult failed: %v", err)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// encodedFields returns the fields of r covered by the binary encoding.
+func encodedFields(r *Result) Result {
+	return Result{
+		Vertices:      r.Vertices,
+		Indices:       r.Indices,
+		VertexIndices: r.VertexIndices,
+		VertexSize:    r.VertexSize,
+		ElementType:   r.ElementType,
+		PolySize:      r.PolySize,
+	}
+}
+
+// TestMarshalBinary tests that results survive a round trip
+func TestMarshalBinary(t *testing.T) {
+	for _, r := range encodedResults(t) {
+		data, err := r.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %v", err)
+		}
+		if raw := 4*len(r.Vertices) + 4*len(r.Indices) + 4*len(r.VertexIndices); len(data) >= raw {
+			t.Errorf("%s: expected fewer than %d bytes, got %d", r.ElementType, raw, len(data))
+		}
+
+		got := Result{Windings: []int{1}}
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+		if want := encodedFields(r); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %+v, got %+v", r.ElementType, want, got)
+		}
+	}
+
+	// Results without vertex indices keep them nil.
+	r := &Result{Vertices: []float32{0, 0, 1, 0, 0, 1}, Indices: []int{0, 1, 2}, VertexSize: 2, PolySize: 3}
+	data, _ := r.MarshalBinary()
+	var got Result
+	if err := got.UnmarshalBinary(data); err != nil || got.VertexIndices != nil || !reflect.DeepEqual(got.Indices, r.Indices) {
+		t.Errorf("Expected %+v, got %+v (%v)", r, got, err)
+	}
+}
+
+// TestWriteToReadFrom tests results written to and read from a stream
+func TestWriteToReadFrom(t *testing.T) {
+	results := encodedResults(t)
+	var buf bytes.Buffer
+	var written int64
+	for _, r := range results {
+		n, err := r.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("WriteTo failed: %v", err)
+		}
+		written += n
+	}
+	if written != int64(buf.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", buf.Len(), written)
+	}
+
+	// Results are read one after another without reading ahead.
+	var read int64
+	for _, want := range results {
+		var got Result
+		n, err := got.ReadFrom(&buf)
+		if err != nil {
+			t.Fatalf("ReadFrom failed: %v", err)
+		}
+		read += n
+		if !reflect.DeepEqual(got, encodedFields(want)) {
+			t.Errorf("%s: expected %+v, got %+v", want.ElementType, encodedFields(want), got)
+		}
+	}
+	if read != written {
+		t.Errorf("Expected %d bytes read, got %d", written, read)
+	}
+	var r Result
+	if _, err := r.ReadFrom(&buf); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end of the stream, got %v", err)
+	}
+}
+
+// TestMarshalQuantized tests the precision and size of quantized results
+func TestMarshalQuantized(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	tess.AddContour(2, noisyCircle(500, 10, 0.01))
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	full, _ := r.MarshalBinary()
+
+	const step = 0.001
+	data, err := r.MarshalQuantized(step)
+	if err != nil {
+		t.Fatalf("MarshalQuantized failed: %v", err)
+	}
+	if len(data) >= len(full) {
+		t.Errorf("Expected fewer than %d bytes, got %d", len(full), len(data))
+	}
+	var got Result
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if !reflect.DeepEqual(got.Indices, r.Indices) || !reflect.DeepEqual(got.VertexIndices, r.VertexIndices) {
+		t.Error("Expected indices to be preserved")
+	}
+	if len(got.Vertices) != len(r.Vertices) {
+		t.Fatalf("Expected %d coordinates, got %d", len(r.Vertices), len(got.Vertices))
+	}
+	for i, f := range r.Vertices {
+		if d := math.Abs(float64(got.Vertices[i] - f)); d > step/2+1e-6 {
+			t.Errorf("Coordinate %d: %v is %v from %v", i, got.Vertices[i], d, f)
+		}
+	}
+
+	for _, step := range []float32{0, -1, float32(math.NaN()), float32(math.Inf(1))} {
+		if _, err := r.MarshalQuantized(step); err == nil {
+			t.Errorf("Expected error for step %v", step)
+		}
+	}
+	if _, err := r.MarshalQuantized(1e-30); err == nil {
+		t.Error("Expected error for coordinates out of range")
+	}
+}
+
+// TestUnmarshalBinaryErrors tests that corrupt input is rejected
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	r := encodedResults(t)[1]
+	data, _ := r.MarshalBinary()
+
+	var got Result
+	for n := 0; n < len(data); n++ {
+		if err := got.UnmarshalBinary(data[:n]); err == nil {
+			t.Errorf("Expected error for %d of %d bytes", n, len(data))
+		}
+		if _, err := got.ReadFrom(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("Expected ReadFrom error for %d of %d bytes", n, len(data))
+		}
+	}
+	if err := got.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Error("Expected error for trailing data")
+	}
+
+	// The payload starts after the magic, the version and its length.
+	_, n := binary.Uvarint(data[5:])
+	payload := 5 + n
+	corrupt := func(i int, b byte) []byte {
+		c := bytes.Clone(data)
+		c[i] = b
+		return c
+	}
+	for name, c := range map[string][]byte{
+		"magic":        corrupt(0, 'X'),
+		"version":      corrupt(4, 2),
+		"flags":        corrupt(payload, 0x40),
+		"element type": corrupt(payload+1, 9),
+		"vertex size":  corrupt(payload+3, 4),
+	} {
+		if err := got.UnmarshalBinary(c); err == nil {
+			t.Errorf("Expected error for corrupt %s", name)
+		}
+	}
+
+	for _, bad := range []*Result{
+		{Vertices: []float32{0, 0, 1}, VertexSize: 2},
+		{Vertices: []float32{0}, VertexSize: 0},
+		{VertexSize: 4},
+		{PolySize: -1},
+		{ElementType: 7},
+	} {
+		if _, err := bad.MarshalBinary(); err == nil {
+			t.Errorf("Expected error for %+v", bad)
+		}
+	}
+}
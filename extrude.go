@@ -0,0 +1,354 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+)
+
+// ExtrudeOptions configures Extrude.
+type ExtrudeOptions struct {
+	// WindingRule selects which parts of the input contours are solid.
+	WindingRule WindingRule
+	// FaceNormals requests a normal per vertex. Vertices are duplicated per
+	// face so that each cap and side quad is flat shaded with hard edges.
+	FaceNormals bool
+	// Bevel, if not nil, bevels the edges between the caps and the sides.
+	Bevel *Bevel
+}
+
+// Bevel describes the profile used to bevel both cap edges of an extrusion.
+type Bevel struct {
+	// Width is how far the cap outline is inset from the side walls.
+	Width float32
+	// Depth is how far along the extrusion axis each bevel extends.
+	// 2*Depth must not exceed the extrusion depth.
+	Depth float32
+	// Segments is the number of steps in the profile. 1 produces a straight
+	// chamfer, larger values approximate a rounded quarter circle.
+	Segments int
+}
+
+// ExtrudedMesh is a closed triangle mesh produced by Extrude.
+type ExtrudedMesh struct {
+	// Vertices holds x, y, z triples.
+	Vertices []float32
+	// Normals holds a unit normal per vertex, or nil unless
+	// ExtrudeOptions.FaceNormals was set.
+	Normals []float32
+	// Indices holds three vertex indices per triangle, wound
+	// counter-clockwise when seen from outside the solid.
+	Indices []int
+}
+
+// profileStep is one ring of an extrusion profile: outlines inset by inset
+// and placed at height z.
+type profileStep struct {
+	inset, z float32
+}
+
+// Extrude turns 2D contours (x, y pairs) into a closed solid spanning z=0 to
+// z=depth. The back cap faces -Z, the front cap faces +Z, and side walls are
+// built along the region boundary selected by opts.WindingRule, so
+// overlapping or self-intersecting input still yields a watertight mesh.
+// opts may be nil to use WindingOdd without normals or bevels.
+func Extrude(contours [][]float32, depth float32, opts *ExtrudeOptions) (*ExtrudedMesh, error) {
+	if opts == nil {
+		opts = &ExtrudeOptions{}
+	}
+	if !(depth > 0) {
+		return nil, fmt.Errorf("depth must be positive, got %v", depth)
+	}
+
+	profile := []profileStep{{0, 0}, {0, depth}}
+	if b := opts.Bevel; b != nil {
+		var err error
+		if profile, err = bevelProfile(b, depth); err != nil {
+			return nil, err
+		}
+	}
+
+	rings, err := boundaryRings(contours, opts.WindingRule)
+	if err != nil {
+		return nil, err
+	}
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("contours do not enclose any area")
+	}
+
+	// levels[k][r] holds the 2D outline of ring r at profile step k.
+	levels := make([][][]float32, len(profile))
+	for k, step := range profile {
+		levels[k] = make([][]float32, len(rings))
+		for r, ring := range rings {
+			levels[k][r] = insetRing(ring, step.inset)
+		}
+	}
+
+	capTris, err := capTriangles(levels[0])
+	if err != nil {
+		return nil, err
+	}
+
+	m := &ExtrudedMesh{}
+	if opts.FaceNormals {
+		m.buildFlat(levels, profile, capTris)
+	} else {
+		m.buildShared(levels, profile, capTris)
+	}
+	return m, nil
+}
+
+// bevelProfile returns the profile steps from the back cap to the front cap.
+func bevelProfile(b *Bevel, depth float32) ([]profileStep, error) {
+	if b.Width < 0 || b.Depth < 0 {
+		return nil, fmt.Errorf("bevel width and depth must not be negative")
+	}
+	if 2*b.Depth > depth {
+		return nil, fmt.Errorf("bevel depth %v exceeds half the extrusion depth %v", b.Depth, depth)
+	}
+	n := b.Segments
+	if n < 1 {
+		n = 1
+	}
+
+	var back []profileStep
+	for k := 0; k <= n; k++ {
+		if n == 1 {
+			back = append(back, profileStep{b.Width * float32(1-k), b.Depth * float32(k)})
+			continue
+		}
+		theta := float64(k) / float64(n) * math.Pi / 2
+		back = append(back, profileStep{
+			inset: b.Width * float32(1-math.Sin(theta)),
+			z:     b.Depth * float32(1-math.Cos(theta)),
+		})
+	}
+
+	profile := append([]profileStep{}, back...)
+	for k := len(back) - 1; k >= 0; k-- {
+		step := profileStep{back[k].inset, depth - back[k].z}
+		if last := profile[len(profile)-1]; last == step {
+			continue
+		}
+		profile = append(profile, step)
+	}
+	return profile, nil
+}
+
+// boundaryRings resolves the input contours with the winding rule and
+// returns the boundary of the filled region. Each ring has the region on its
+// left, so outer rings are counter-clockwise and holes clockwise.
+func boundaryRings(contours [][]float32, windingRule WindingRule) ([][]float32, error) {
+	t := NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	added := 0
+	for i, c := range contours {
+		if len(c) == 0 {
+			continue
+		}
+		if err := t.AddContour(2, c); err != nil {
+			return nil, fmt.Errorf("contour %d: %w", i, err)
+		}
+		added++
+	}
+	if added == 0 {
+		return nil, nil
+	}
+
+	vertices, elements, err := t.Tessellate(windingRule, ElementBoundaryContours, 0, 2, []float32{0, 0, 1})
+	if err != nil {
+		return nil, err
+	}
+
+	rings := make([][]float32, 0, len(elements)/2)
+	for i := 0; i+1 < len(elements); i += 2 {
+		base, count := elements[i], elements[i+1]
+		if count < 3 {
+			continue
+		}
+		rings = append(rings, vertices[2*base:2*(base+count)])
+	}
+	return rings, nil
+}
+
+// insetRing moves every vertex of a ring towards the ring's left side by
+// inset, using mitered corners. The miter is limited so that very sharp
+// corners do not produce long spikes.
+func insetRing(ring []float32, inset float32) []float32 {
+	out := make([]float32, len(ring))
+	copy(out, ring)
+	if inset == 0 {
+		return out
+	}
+
+	n := len(ring) / 2
+	for i := 0; i < n; i++ {
+		prev := (i + n - 1) % n
+		next := (i + 1) % n
+		n1x, n1y := leftNormal(ring[2*prev], ring[2*prev+1], ring[2*i], ring[2*i+1])
+		n2x, n2y := leftNormal(ring[2*i], ring[2*i+1], ring[2*next], ring[2*next+1])
+
+		mx, my := n1x+n2x, n1y+n2y
+		d := 1 + n1x*n2x + n1y*n2y
+		if d < 0.25 {
+			// Limit the miter length to twice the inset.
+			d = 0.25
+		}
+		out[2*i] = ring[2*i] + inset*mx/d
+		out[2*i+1] = ring[2*i+1] + inset*my/d
+	}
+	return out
+}
+
+// leftNormal returns the unit normal pointing to the left of segment a->b.
+func leftNormal(ax, ay, bx, by float32) (float32, float32) {
+	dx, dy := bx-ax, by-ay
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	if l == 0 {
+		return 0, 0
+	}
+	return -dy / l, dx / l
+}
+
+// capTriangles triangulates cap rings and returns triangles as (ring, vertex)
+// references so that caps can share vertices with the side walls.
+func capTriangles(rings [][]float32) ([][2]int, error) {
+	t := NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	// Map tessellator input vertex numbers back to (ring, vertex).
+	var refs [][2]int
+	for r, ring := range rings {
+		if err := t.AddContour(2, ring); err != nil {
+			return nil, fmt.Errorf("cap ring %d: %w", r, err)
+		}
+		for i := 0; i < len(ring)/2; i++ {
+			refs = append(refs, [2]int{r, i})
+		}
+	}
+
+	_, indices, err := t.Tessellate(WindingPositive, ElementPolygons, 3, 2, []float32{0, 0, 1})
+	if err != nil {
+		return nil, err
+	}
+	vertexIndices := t.getVertexIndices()
+
+	tris := make([][2]int, len(indices))
+	for i, idx := range indices {
+		src := vertexIndices[idx]
+		if src < 0 || src >= len(refs) {
+			return nil, fmt.Errorf("bevel outline intersects itself; reduce the bevel width")
+		}
+		tris[i] = refs[src]
+	}
+	return tris, nil
+}
+
+// buildShared emits a mesh where neighbouring faces share vertices.
+func (m *ExtrudedMesh) buildShared(levels [][][]float32, profile []profileStep, capTris [][2]int) {
+	// ids[k][r][i] is the output vertex of ring r, vertex i at profile step k.
+	// Rings may touch at a vertex, which is welded into one output vertex so
+	// that the caps and the walls of both rings share it.
+	ids := make([][][]int, len(levels))
+	for k, rings := range levels {
+		ids[k] = make([][]int, len(rings))
+		welded := make(map[[2]float32]int)
+		for r, ring := range rings {
+			ids[k][r] = make([]int, len(ring)/2)
+			for i := range ids[k][r] {
+				p := [2]float32{ring[2*i], ring[2*i+1]}
+				id, ok := welded[p]
+				if !ok {
+					id = len(m.Vertices) / 3
+					welded[p] = id
+					m.Vertices = append(m.Vertices, p[0], p[1], profile[k].z)
+				}
+				ids[k][r][i] = id
+			}
+		}
+	}
+
+	back, front := ids[0], ids[len(ids)-1]
+	for i := 0; i+2 < len(capTris); i += 3 {
+		a, b, c := capTris[i], capTris[i+1], capTris[i+2]
+		m.Indices = append(m.Indices,
+			back[a[0]][a[1]], back[c[0]][c[1]], back[b[0]][b[1]],
+			front[a[0]][a[1]], front[b[0]][b[1]], front[c[0]][c[1]],
+		)
+	}
+
+	for k := 0; k+1 < len(ids); k++ {
+		for r := range ids[k] {
+			lo, hi := ids[k][r], ids[k+1][r]
+			for i := range lo {
+				j := (i + 1) % len(lo)
+				m.Indices = append(m.Indices, lo[i], lo[j], hi[j], lo[i], hi[j], hi[i])
+			}
+		}
+	}
+}
+
+// buildFlat emits a mesh where every face has its own vertices and normals.
+func (m *ExtrudedMesh) buildFlat(levels [][][]float32, profile []profileStep, capTris [][2]int) {
+	vertex := func(x, y, z, nx, ny, nz float32) int {
+		m.Vertices = append(m.Vertices, x, y, z)
+		m.Normals = append(m.Normals, nx, ny, nz)
+		return len(m.Vertices)/3 - 1
+	}
+
+	last := len(levels) - 1
+	for _, c := range []struct {
+		k  int
+		nz float32
+	}{{0, -1}, {last, 1}} {
+		ids := make([][]int, len(levels[c.k]))
+		for r, ring := range levels[c.k] {
+			ids[r] = make([]int, len(ring)/2)
+			for i := range ids[r] {
+				ids[r][i] = vertex(ring[2*i], ring[2*i+1], profile[c.k].z, 0, 0, c.nz)
+			}
+		}
+		for i := 0; i+2 < len(capTris); i += 3 {
+			a, b, t := capTris[i], capTris[i+1], capTris[i+2]
+			if c.nz < 0 {
+				b, t = t, b
+			}
+			m.Indices = append(m.Indices, ids[a[0]][a[1]], ids[b[0]][b[1]], ids[t[0]][t[1]])
+		}
+	}
+
+	for k := 0; k < last; k++ {
+		z0, z1 := profile[k].z, profile[k+1].z
+		for r, lo := range levels[k] {
+			hi := levels[k+1][r]
+			n := len(lo) / 2
+			for i := 0; i < n; i++ {
+				j := (i + 1) % n
+				ax, ay, bx, by := lo[2*i], lo[2*i+1], lo[2*j], lo[2*j+1]
+				cx, cy, dx, dy := hi[2*j], hi[2*j+1], hi[2*i], hi[2*i+1]
+
+				// Face normal from the quad diagonals.
+				ux, uy, uz := cx-ax, cy-ay, z1-z0
+				vx, vy, vz := dx-bx, dy-by, z1-z0
+				nx, ny, nz := uy*vz-uz*vy, uz*vx-ux*vz, ux*vy-uy*vx
+				l := float32(math.Sqrt(float64(nx*nx + ny*ny + nz*nz)))
+				if l > 0 {
+					nx, ny, nz = nx/l, ny/l, nz/l
+				}
+
+				va := vertex(ax, ay, z0, nx, ny, nz)
+				vb := vertex(bx, by, z0, nx, ny, nz)
+				vc := vertex(cx, cy, z1, nx, ny, nz)
+				vd := vertex(dx, dy, z1, nx, ny, nz)
+				m.Indices = append(m.Indices, va, vb, vc, va, vc, vd)
+			}
+		}
+	}
+}
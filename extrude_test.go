@@ -0,0 +1,209 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// checkClosedMesh verifies that every directed edge of the mesh is matched by
+// exactly one edge in the opposite direction, comparing vertices by position.
+func checkClosedMesh(t *testing.T, m *ExtrudedMesh) {
+	t.Helper()
+
+	type point [3]float32
+	type edge [2]point
+	pos := func(i int) point {
+		return point{m.Vertices[3*i], m.Vertices[3*i+1], m.Vertices[3*i+2]}
+	}
+
+	edges := make(map[edge]int)
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		for k := 0; k < 3; k++ {
+			a, b := pos(m.Indices[i+k]), pos(m.Indices[i+(k+1)%3])
+			edges[edge{a, b}]++
+		}
+	}
+	for e, n := range edges {
+		if n != 1 {
+			t.Errorf("Directed edge %v used %d times", e, n)
+		}
+		if edges[edge{e[1], e[0]}] != 1 {
+			t.Errorf("Edge %v has no opposite edge", e)
+		}
+	}
+}
+
+// meshVolume returns the signed volume enclosed by a closed triangle mesh.
+func meshVolume(m *ExtrudedMesh) float64 {
+	v := func(i int) [3]float64 {
+		return [3]float64{float64(m.Vertices[3*i]), float64(m.Vertices[3*i+1]), float64(m.Vertices[3*i+2])}
+	}
+	volume := 0.0
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := v(m.Indices[i]), v(m.Indices[i+1]), v(m.Indices[i+2])
+		volume += a[0]*(b[1]*c[2]-b[2]*c[1]) - a[1]*(b[0]*c[2]-b[2]*c[0]) + a[2]*(b[0]*c[1]-b[1]*c[0])
+	}
+	return volume / 6
+}
+
+// TestExtrudeSquareWithHole tests a watertight extrusion of a region with a hole
+func TestExtrudeSquareWithHole(t *testing.T) {
+	outer := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+	// Clockwise input for the outer ring must not matter.
+	outerCW := []float32{0, 0, 0, 4, 4, 4, 4, 0}
+	hole := []float32{1, 1, 3, 1, 3, 3, 1, 3}
+
+	for name, contours := range map[string][][]float32{
+		"ccw": {outer, hole},
+		"cw":  {outerCW, hole},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := Extrude(contours, 2, nil)
+			if err != nil {
+				t.Fatalf("Extrude failed: %v", err)
+			}
+			checkClosedMesh(t, m)
+
+			// (16 - 4) * 2
+			if v := meshVolume(m); math.Abs(v-24) > 1e-4 {
+				t.Errorf("Expected volume 24, got %v", v)
+			}
+			if m.Normals != nil {
+				t.Error("Expected no normals by default")
+			}
+			// 8 cap triangles per side and 8 wall quads.
+			if got := len(m.Indices) / 3; got != 2*8+2*8 {
+				t.Errorf("Expected 32 triangles, got %d", got)
+			}
+		})
+	}
+}
+
+// TestExtrudeSelfIntersecting tests that overlapping input is resolved before walls are built
+func TestExtrudeSelfIntersecting(t *testing.T) {
+	a := []float32{0, 0, 2, 0, 2, 2, 0, 2}
+	b := []float32{1, 1, 3, 1, 3, 3, 1, 3}
+
+	m, err := Extrude([][]float32{a, b}, 1, &ExtrudeOptions{WindingRule: WindingNonZero})
+	if err != nil {
+		t.Fatalf("Extrude failed: %v", err)
+	}
+	checkClosedMesh(t, m)
+	if v := meshVolume(m); math.Abs(v-7) > 1e-4 {
+		t.Errorf("Expected union volume 7, got %v", v)
+	}
+}
+
+// TestExtrudeTouchingRings tests that rings touching at a vertex share it
+func TestExtrudeTouchingRings(t *testing.T) {
+	a := []float32{0, 0, 1, 0, 1, 1, 0, 1}
+	b := []float32{1, 1, 2, 1, 2, 2, 1, 2}
+
+	m, err := Extrude([][]float32{a, b}, 1, nil)
+	if err != nil {
+		t.Fatalf("Extrude failed: %v", err)
+	}
+	if v := meshVolume(m); math.Abs(v-2) > 1e-4 {
+		t.Errorf("Expected volume 2, got %v", v)
+	}
+	if n := len(m.Vertices) / 3; n != 14 {
+		t.Errorf("Expected the touching corner welded into 14 vertices, got %d", n)
+	}
+
+	// Edges are matched by vertex index. The vertical edge at the touching
+	// corner bounds the walls of both squares, so it is used twice each way.
+	edges := make(map[[2]int]int)
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		for k := 0; k < 3; k++ {
+			edges[[2]int{m.Indices[i+k], m.Indices[i+(k+1)%3]}]++
+		}
+	}
+	shared := 0
+	for e, n := range edges {
+		if opposite := edges[[2]int{e[1], e[0]}]; n != opposite {
+			t.Errorf("Edge %v used %d times, opposite %d times", e, n, opposite)
+		}
+		if n > 1 {
+			shared++
+		}
+	}
+	if shared != 2 {
+		t.Errorf("Expected one shared vertical edge, got %d directed edges used more than once", shared)
+	}
+}
+
+// TestExtrudeFaceNormals tests per-face normals
+func TestExtrudeFaceNormals(t *testing.T) {
+	square := []float32{0, 0, 1, 0, 1, 1, 0, 1}
+
+	m, err := Extrude([][]float32{square}, 3, &ExtrudeOptions{FaceNormals: true})
+	if err != nil {
+		t.Fatalf("Extrude failed: %v", err)
+	}
+	if len(m.Normals) != len(m.Vertices) {
+		t.Fatalf("Expected one normal per vertex, got %d for %d", len(m.Normals)/3, len(m.Vertices)/3)
+	}
+	checkClosedMesh(t, m)
+	if v := meshVolume(m); math.Abs(v-3) > 1e-4 {
+		t.Errorf("Expected volume 3, got %v", v)
+	}
+
+	// Each vertex normal must match the geometric normal of its triangles.
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := m.Indices[i], m.Indices[i+1], m.Indices[i+2]
+		ux, uy, uz := m.Vertices[3*b]-m.Vertices[3*a], m.Vertices[3*b+1]-m.Vertices[3*a+1], m.Vertices[3*b+2]-m.Vertices[3*a+2]
+		vx, vy, vz := m.Vertices[3*c]-m.Vertices[3*a], m.Vertices[3*c+1]-m.Vertices[3*a+1], m.Vertices[3*c+2]-m.Vertices[3*a+2]
+		nx, ny, nz := uy*vz-uz*vy, uz*vx-ux*vz, ux*vy-uy*vx
+		for _, v := range []int{a, b, c} {
+			dot := nx*m.Normals[3*v] + ny*m.Normals[3*v+1] + nz*m.Normals[3*v+2]
+			if dot <= 0 {
+				t.Errorf("Triangle %d: vertex %d normal points inwards", i/3, v)
+			}
+		}
+	}
+}
+
+// TestExtrudeBevel tests chamfered and rounded bevel profiles
+func TestExtrudeBevel(t *testing.T) {
+	square := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+
+	chamfer, err := Extrude([][]float32{square}, 2, &ExtrudeOptions{Bevel: &Bevel{Width: 0.5, Depth: 0.5, Segments: 1}})
+	if err != nil {
+		t.Fatalf("Extrude failed: %v", err)
+	}
+	checkClosedMesh(t, chamfer)
+
+	// The middle slab is 4x4x1; each chamfer is a frustum from 3x3 to 4x4 with height 0.5.
+	frustum := 0.5 / 3 * (9 + 16 + 12)
+	if v := meshVolume(chamfer); math.Abs(v-(16+2*frustum)) > 1e-3 {
+		t.Errorf("Expected volume %v, got %v", 16+2*frustum, v)
+	}
+
+	rounded, err := Extrude([][]float32{square}, 2, &ExtrudeOptions{Bevel: &Bevel{Width: 0.5, Depth: 0.5, Segments: 4}, FaceNormals: true})
+	if err != nil {
+		t.Fatalf("Extrude failed: %v", err)
+	}
+	checkClosedMesh(t, rounded)
+	if v := meshVolume(rounded); v <= meshVolume(chamfer) || v >= 32 {
+		t.Errorf("Expected rounded volume between chamfer and box, got %v", v)
+	}
+
+	if _, err := Extrude([][]float32{square}, 2, &ExtrudeOptions{Bevel: &Bevel{Width: 0.5, Depth: 1.5}}); err == nil {
+		t.Error("Expected error when bevel is deeper than half the extrusion")
+	}
+}
+
+// TestExtrudeInvalidInput tests error handling
+func TestExtrudeInvalidInput(t *testing.T) {
+	square := []float32{0, 0, 1, 0, 1, 1, 0, 1}
+
+	if _, err := Extrude([][]float32{square}, 0, nil); err == nil {
+		t.Error("Expected error for zero depth")
+	}
+	if _, err := Extrude(nil, 1, nil); err == nil {
+		t.Error("Expected error for no contours")
+	}
+	if _, err := Extrude([][]float32{{0, 0, 1, 1, 2, 2}}, 1, nil); err == nil {
+		t.Error("Expected error for contours without area")
+	}
+}
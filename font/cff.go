@@ -0,0 +1,636 @@
+package font
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// Limits guarding the Type 2 charstring interpreter against malformed fonts.
+const (
+	cffMaxStack     = 48
+	cffMaxSubrDepth = 10
+	cffMaxHints     = 96
+)
+
+// cffFont holds the parsed pieces of a CFF table needed to decode outlines.
+type cffFont struct {
+	charStrings [][]byte
+	globalSubrs [][]byte
+	// localSubrs holds the local subroutines of each Font DICT. Non-CID fonts
+	// have exactly one entry.
+	localSubrs [][][]byte
+	// fdSelect maps a glyph to its Font DICT for CID-keyed fonts.
+	fdSelect func(GlyphIndex) int
+}
+
+// parseCFF parses a version 1 CFF table.
+func parseCFF(b []byte) (*cffFont, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("header truncated")
+	}
+	if b[0] != 1 {
+		return nil, fmt.Errorf("unsupported CFF version %d", b[0])
+	}
+
+	p := int(b[2])
+	var err error
+	// Name INDEX, unused.
+	if _, p, err = readIndex(b, p); err != nil {
+		return nil, fmt.Errorf("name index: %w", err)
+	}
+	topDicts, p, err := readIndex(b, p)
+	if err != nil {
+		return nil, fmt.Errorf("top dict index: %w", err)
+	}
+	if len(topDicts) == 0 {
+		return nil, fmt.Errorf("no top dict")
+	}
+	// String INDEX, unused.
+	if _, p, err = readIndex(b, p); err != nil {
+		return nil, fmt.Errorf("string index: %w", err)
+	}
+	globalSubrs, _, err := readIndex(b, p)
+	if err != nil {
+		return nil, fmt.Errorf("global subrs index: %w", err)
+	}
+
+	top, err := parseDict(topDicts[0])
+	if err != nil {
+		return nil, fmt.Errorf("top dict: %w", err)
+	}
+
+	if cs, ok := top[12<<8|6]; ok && len(cs) > 0 && cs[0] != 2 {
+		return nil, fmt.Errorf("unsupported charstring type %v", cs[0])
+	}
+
+	c := &cffFont{globalSubrs: globalSubrs}
+
+	csOff, ok := top[17]
+	if !ok || len(csOff) < 1 {
+		return nil, fmt.Errorf("top dict has no CharStrings")
+	}
+	if c.charStrings, _, err = readIndex(b, int(csOff[0])); err != nil {
+		return nil, fmt.Errorf("charstrings index: %w", err)
+	}
+
+	if fdArray, ok := top[12<<8|36]; ok && len(fdArray) > 0 {
+		// CID-keyed font: one Private DICT per Font DICT.
+		fds, _, err := readIndex(b, int(fdArray[0]))
+		if err != nil {
+			return nil, fmt.Errorf("fdarray index: %w", err)
+		}
+		for i, fd := range fds {
+			d, err := parseDict(fd)
+			if err != nil {
+				return nil, fmt.Errorf("font dict %d: %w", i, err)
+			}
+			subrs, err := readPrivateSubrs(b, d)
+			if err != nil {
+				return nil, fmt.Errorf("font dict %d: %w", i, err)
+			}
+			c.localSubrs = append(c.localSubrs, subrs)
+		}
+
+		sel, ok := top[12<<8|37]
+		if !ok || len(sel) < 1 {
+			return nil, fmt.Errorf("CID font has no FDSelect")
+		}
+		if c.fdSelect, err = parseFDSelect(b, int(sel[0]), len(c.charStrings)); err != nil {
+			return nil, fmt.Errorf("fdselect: %w", err)
+		}
+	} else {
+		subrs, err := readPrivateSubrs(b, top)
+		if err != nil {
+			return nil, err
+		}
+		c.localSubrs = [][][]byte{subrs}
+		c.fdSelect = func(GlyphIndex) int { return 0 }
+	}
+
+	return c, nil
+}
+
+// readPrivateSubrs reads the local subroutines referenced by the Private
+// operator of a Top or Font DICT.
+func readPrivateSubrs(b []byte, d map[int][]float64) ([][]byte, error) {
+	priv, ok := d[18]
+	if !ok || len(priv) < 2 {
+		return nil, nil
+	}
+	size, offset := int(priv[0]), int(priv[1])
+	if size < 0 || offset < 0 || offset+size > len(b) {
+		return nil, fmt.Errorf("private dict out of bounds")
+	}
+	pd, err := parseDict(b[offset : offset+size])
+	if err != nil {
+		return nil, fmt.Errorf("private dict: %w", err)
+	}
+	subrsOff, ok := pd[19]
+	if !ok || len(subrsOff) < 1 {
+		return nil, nil
+	}
+	subrs, _, err := readIndex(b, offset+int(subrsOff[0]))
+	if err != nil {
+		return nil, fmt.Errorf("local subrs index: %w", err)
+	}
+	return subrs, nil
+}
+
+// parseFDSelect decodes FDSelect formats 0 and 3.
+func parseFDSelect(b []byte, p, numGlyphs int) (func(GlyphIndex) int, error) {
+	if p < 0 || p >= len(b) {
+		return nil, fmt.Errorf("offset out of bounds")
+	}
+	switch b[p] {
+	case 0:
+		if p+1+numGlyphs > len(b) {
+			return nil, fmt.Errorf("format 0 truncated")
+		}
+		fds := b[p+1 : p+1+numGlyphs]
+		return func(g GlyphIndex) int { return int(fds[g]) }, nil
+	case 3:
+		if p+3 > len(b) {
+			return nil, fmt.Errorf("format 3 truncated")
+		}
+		nRanges := int(binary.BigEndian.Uint16(b[p+1:]))
+		ranges := b[p+3:]
+		if len(ranges) < 3*nRanges+2 {
+			return nil, fmt.Errorf("format 3 truncated")
+		}
+		return func(g GlyphIndex) int {
+			fd := 0
+			for i := 0; i < nRanges; i++ {
+				if GlyphIndex(binary.BigEndian.Uint16(ranges[3*i:])) > g {
+					break
+				}
+				fd = int(ranges[3*i+2])
+			}
+			return fd
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %d", b[p])
+}
+
+// readIndex reads a CFF INDEX structure starting at p and returns its
+// objects and the offset just past it.
+func readIndex(b []byte, p int) ([][]byte, int, error) {
+	if p < 0 || p+2 > len(b) {
+		return nil, 0, fmt.Errorf("offset %d out of bounds", p)
+	}
+	count := int(binary.BigEndian.Uint16(b[p:]))
+	if count == 0 {
+		return nil, p + 2, nil
+	}
+	if p+3 > len(b) {
+		return nil, 0, fmt.Errorf("index header truncated")
+	}
+	offSize := int(b[p+2])
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, fmt.Errorf("invalid offset size %d", offSize)
+	}
+	offsets := p + 3
+	data := offsets + (count+1)*offSize - 1
+	if data >= len(b) {
+		return nil, 0, fmt.Errorf("index offsets truncated")
+	}
+
+	readOffset := func(i int) int {
+		v := 0
+		for _, c := range b[offsets+i*offSize : offsets+(i+1)*offSize] {
+			v = v<<8 | int(c)
+		}
+		return v
+	}
+
+	objects := make([][]byte, count)
+	prev := readOffset(0)
+	for i := 0; i < count; i++ {
+		next := readOffset(i + 1)
+		if prev < 1 || next < prev || data+next > len(b) {
+			return nil, 0, fmt.Errorf("object %d out of bounds", i)
+		}
+		objects[i] = b[data+prev : data+next]
+		prev = next
+	}
+	return objects, data + prev, nil
+}
+
+// parseDict decodes a CFF DICT into a map from operator to operands.
+// Two-byte operators are keyed as 12<<8 | second byte.
+func parseDict(b []byte) (map[int][]float64, error) {
+	d := make(map[int][]float64)
+	var operands []float64
+	for p := 0; p < len(b); {
+		c := int(b[p])
+		switch {
+		case c <= 21:
+			op := c
+			p++
+			if c == 12 {
+				if p >= len(b) {
+					return nil, fmt.Errorf("escape operator truncated")
+				}
+				op = 12<<8 | int(b[p])
+				p++
+			}
+			d[op] = operands
+			operands = nil
+		case c == 28:
+			if p+3 > len(b) {
+				return nil, fmt.Errorf("operand truncated")
+			}
+			operands = append(operands, float64(int16(binary.BigEndian.Uint16(b[p+1:]))))
+			p += 3
+		case c == 29:
+			if p+5 > len(b) {
+				return nil, fmt.Errorf("operand truncated")
+			}
+			operands = append(operands, float64(int32(binary.BigEndian.Uint32(b[p+1:]))))
+			p += 5
+		case c == 30:
+			v, n, err := parseReal(b[p+1:])
+			if err != nil {
+				return nil, err
+			}
+			operands = append(operands, v)
+			p += 1 + n
+		case c >= 32 && c <= 246:
+			operands = append(operands, float64(c-139))
+			p++
+		case c >= 247 && c <= 254:
+			if p+2 > len(b) {
+				return nil, fmt.Errorf("operand truncated")
+			}
+			w := int(b[p+1])
+			if c <= 250 {
+				operands = append(operands, float64((c-247)*256+w+108))
+			} else {
+				operands = append(operands, float64(-(c-251)*256-w-108))
+			}
+			p += 2
+		default:
+			return nil, fmt.Errorf("invalid dict byte %d", c)
+		}
+	}
+	return d, nil
+}
+
+// parseReal decodes a nibble-encoded DICT real number and returns it together
+// with the number of bytes consumed.
+func parseReal(b []byte) (float64, int, error) {
+	var s []byte
+	for i, c := range b {
+		for _, nib := range [2]byte{c >> 4, c & 0xf} {
+			switch {
+			case nib <= 9:
+				s = append(s, '0'+nib)
+			case nib == 0xa:
+				s = append(s, '.')
+			case nib == 0xb:
+				s = append(s, 'e')
+			case nib == 0xc:
+				s = append(s, 'e', '-')
+			case nib == 0xe:
+				s = append(s, '-')
+			case nib == 0xf:
+				if len(s) == 0 {
+					return 0, i + 1, nil
+				}
+				v, err := strconv.ParseFloat(string(s), 64)
+				if err != nil {
+					return 0, 0, fmt.Errorf("invalid real %q", s)
+				}
+				return v, i + 1, nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("real operand truncated")
+}
+
+// subrBias returns the bias added to subroutine numbers.
+func subrBias(n int) int {
+	switch {
+	case n < 1240:
+		return 107
+	case n < 33900:
+		return 1131
+	}
+	return 32768
+}
+
+// outline decodes the Type 2 charstring of a glyph.
+func (c *cffFont) outline(g GlyphIndex) (Outline, error) {
+	if int(g) >= len(c.charStrings) {
+		return nil, fmt.Errorf("glyph %d has no charstring", g)
+	}
+	fd := c.fdSelect(g)
+	if fd < 0 || fd >= len(c.localSubrs) {
+		return nil, fmt.Errorf("glyph %d references missing font dict %d", g, fd)
+	}
+
+	r := &charstringRunner{global: c.globalSubrs, local: c.localSubrs[fd]}
+	if err := r.run(c.charStrings[g], 0); err != nil && err != errEndChar {
+		return nil, fmt.Errorf("glyph %d: %w", g, err)
+	}
+	return r.out, nil
+}
+
+var errEndChar = fmt.Errorf("endchar")
+
+// charstringRunner interprets Type 2 charstrings into outline segments.
+type charstringRunner struct {
+	global, local [][]byte
+
+	stack     []float64
+	x, y      float32
+	open      bool
+	nStems    int
+	seenWidth bool
+	out       Outline
+}
+
+func (r *charstringRunner) moveTo(dx, dy float64) {
+	r.x += float32(dx)
+	r.y += float32(dy)
+	r.out = append(r.out, Segment{Op: SegmentMoveTo, Args: [3]Point{{r.x, r.y}}})
+	r.open = true
+}
+
+func (r *charstringRunner) lineTo(dx, dy float64) {
+	r.x += float32(dx)
+	r.y += float32(dy)
+	r.out = append(r.out, Segment{Op: SegmentLineTo, Args: [3]Point{{r.x, r.y}}})
+}
+
+func (r *charstringRunner) curveTo(dxa, dya, dxb, dyb, dxc, dyc float64) {
+	ax, ay := r.x+float32(dxa), r.y+float32(dya)
+	bx, by := ax+float32(dxb), ay+float32(dyb)
+	r.x, r.y = bx+float32(dxc), by+float32(dyc)
+	r.out = append(r.out, Segment{Op: SegmentCubeTo, Args: [3]Point{{ax, ay}, {bx, by}, {r.x, r.y}}})
+}
+
+// takeWidth drops the optional advance width operand that may precede the
+// first stack-clearing operator of a charstring.
+func (r *charstringRunner) takeWidth(odd bool) {
+	if r.seenWidth {
+		return
+	}
+	r.seenWidth = true
+	if odd && len(r.stack) > 0 {
+		r.stack = r.stack[1:]
+	}
+}
+
+// run executes a charstring or subroutine.
+func (r *charstringRunner) run(b []byte, depth int) error {
+	if depth > cffMaxSubrDepth {
+		return fmt.Errorf("subroutine nesting too deep")
+	}
+
+	for p := 0; p < len(b); {
+		c := int(b[p])
+		p++
+
+		// Operands.
+		switch {
+		case c == 28:
+			if p+2 > len(b) {
+				return fmt.Errorf("operand truncated")
+			}
+			if err := r.push(float64(int16(binary.BigEndian.Uint16(b[p:])))); err != nil {
+				return err
+			}
+			p += 2
+			continue
+		case c >= 32 && c <= 246:
+			if err := r.push(float64(c - 139)); err != nil {
+				return err
+			}
+			continue
+		case c >= 247 && c <= 254:
+			if p >= len(b) {
+				return fmt.Errorf("operand truncated")
+			}
+			v := (c-247)*256 + int(b[p]) + 108
+			if c >= 251 {
+				v = -(c-251)*256 - int(b[p]) - 108
+			}
+			p++
+			if err := r.push(float64(v)); err != nil {
+				return err
+			}
+			continue
+		case c == 255:
+			if p+4 > len(b) {
+				return fmt.Errorf("operand truncated")
+			}
+			if err := r.push(float64(int32(binary.BigEndian.Uint32(b[p:]))) / 65536); err != nil {
+				return err
+			}
+			p += 4
+			continue
+		}
+
+		s := r.stack
+		switch c {
+		case 1, 3, 18, 23: // hstem, vstem, hstemhm, vstemhm
+			r.takeWidth(len(s)%2 == 1)
+			r.nStems += len(r.stack) / 2
+		case 19, 20: // hintmask, cntrmask
+			// Pending operands are an implicit vstem.
+			r.takeWidth(len(s)%2 == 1)
+			r.nStems += len(r.stack) / 2
+			if r.nStems > cffMaxHints {
+				return fmt.Errorf("too many hints")
+			}
+			p += (r.nStems + 7) / 8
+		case 21: // rmoveto
+			r.takeWidth(len(s) > 2)
+			s = r.stack
+			if len(s) < 2 {
+				return fmt.Errorf("rmoveto: stack underflow")
+			}
+			r.moveTo(s[0], s[1])
+		case 22: // hmoveto
+			r.takeWidth(len(s) > 1)
+			s = r.stack
+			if len(s) < 1 {
+				return fmt.Errorf("hmoveto: stack underflow")
+			}
+			r.moveTo(s[0], 0)
+		case 4: // vmoveto
+			r.takeWidth(len(s) > 1)
+			s = r.stack
+			if len(s) < 1 {
+				return fmt.Errorf("vmoveto: stack underflow")
+			}
+			r.moveTo(0, s[0])
+		case 5: // rlineto
+			for i := 0; i+1 < len(s); i += 2 {
+				r.lineTo(s[i], s[i+1])
+			}
+		case 6, 7: // hlineto, vlineto
+			horizontal := c == 6
+			for _, d := range s {
+				if horizontal {
+					r.lineTo(d, 0)
+				} else {
+					r.lineTo(0, d)
+				}
+				horizontal = !horizontal
+			}
+		case 8: // rrcurveto
+			for i := 0; i+5 < len(s); i += 6 {
+				r.curveTo(s[i], s[i+1], s[i+2], s[i+3], s[i+4], s[i+5])
+			}
+		case 24: // rcurveline
+			i := 0
+			for ; i+5 < len(s)-2; i += 6 {
+				r.curveTo(s[i], s[i+1], s[i+2], s[i+3], s[i+4], s[i+5])
+			}
+			if i+1 < len(s) {
+				r.lineTo(s[i], s[i+1])
+			}
+		case 25: // rlinecurve
+			i := 0
+			for ; i+1 < len(s)-6; i += 2 {
+				r.lineTo(s[i], s[i+1])
+			}
+			if i+5 < len(s) {
+				r.curveTo(s[i], s[i+1], s[i+2], s[i+3], s[i+4], s[i+5])
+			}
+		case 26: // vvcurveto
+			i := 0
+			dx1 := 0.0
+			if len(s)%2 == 1 {
+				dx1 = s[0]
+				i = 1
+			}
+			for ; i+3 < len(s); i += 4 {
+				r.curveTo(dx1, s[i], s[i+1], s[i+2], 0, s[i+3])
+				dx1 = 0
+			}
+		case 27: // hhcurveto
+			i := 0
+			dy1 := 0.0
+			if len(s)%2 == 1 {
+				dy1 = s[0]
+				i = 1
+			}
+			for ; i+3 < len(s); i += 4 {
+				r.curveTo(s[i], dy1, s[i+1], s[i+2], s[i+3], 0)
+				dy1 = 0
+			}
+		case 30, 31: // vhcurveto, hvcurveto
+			horizontal := c == 31
+			for i := 0; i+3 < len(s); i += 4 {
+				last := 0.0
+				if len(s)-i == 5 {
+					last = s[i+4]
+				}
+				if horizontal {
+					r.curveTo(s[i], 0, s[i+1], s[i+2], last, s[i+3])
+				} else {
+					r.curveTo(0, s[i], s[i+1], s[i+2], s[i+3], last)
+				}
+				horizontal = !horizontal
+			}
+		case 10, 29: // callsubr, callgsubr
+			if len(s) < 1 {
+				return fmt.Errorf("callsubr: stack underflow")
+			}
+			subrs := r.local
+			if c == 29 {
+				subrs = r.global
+			}
+			n := int(s[len(s)-1]) + subrBias(len(subrs))
+			r.stack = s[:len(s)-1]
+			if n < 0 || n >= len(subrs) {
+				return fmt.Errorf("subroutine %d out of range", n)
+			}
+			if err := r.run(subrs[n], depth+1); err != nil {
+				return err
+			}
+			continue
+		case 11: // return
+			return nil
+		case 14: // endchar
+			r.takeWidth(len(s) == 1 || len(s) == 5)
+			if len(r.stack) >= 4 {
+				return fmt.Errorf("seac accented characters are not supported")
+			}
+			r.stack = r.stack[:0]
+			return errEndChar
+		case 12:
+			if p >= len(b) {
+				return fmt.Errorf("escape operator truncated")
+			}
+			if err := r.flex(int(b[p]), s); err != nil {
+				return err
+			}
+			p++
+		default:
+			return fmt.Errorf("unsupported charstring operator %d", c)
+		}
+		r.stack = r.stack[:0]
+	}
+	return nil
+}
+
+// flex handles the two-byte flex operators, which are drawn as plain curves.
+func (r *charstringRunner) flex(op int, s []float64) error {
+	switch op {
+	case 35: // flex
+		if len(s) < 13 {
+			return fmt.Errorf("flex: stack underflow")
+		}
+		r.curveTo(s[0], s[1], s[2], s[3], s[4], s[5])
+		r.curveTo(s[6], s[7], s[8], s[9], s[10], s[11])
+	case 34: // hflex
+		if len(s) < 7 {
+			return fmt.Errorf("hflex: stack underflow")
+		}
+		y := r.y
+		r.curveTo(s[0], 0, s[1], s[2], s[3], 0)
+		r.curveTo(s[4], 0, s[5], float64(y-r.y), s[6], 0)
+	case 36: // hflex1
+		if len(s) < 9 {
+			return fmt.Errorf("hflex1: stack underflow")
+		}
+		y := r.y
+		r.curveTo(s[0], s[1], s[2], s[3], s[4], 0)
+		r.curveTo(s[5], 0, s[6], s[7], s[8], float64(y-r.y-float32(s[7])))
+	case 37: // flex1
+		if len(s) < 11 {
+			return fmt.Errorf("flex1: stack underflow")
+		}
+		x, y := r.x, r.y
+		dx := s[0] + s[2] + s[4] + s[6] + s[8]
+		dy := s[1] + s[3] + s[5] + s[7] + s[9]
+		r.curveTo(s[0], s[1], s[2], s[3], s[4], s[5])
+		if abs(dx) > abs(dy) {
+			r.curveTo(s[6], s[7], s[8], s[9], s[10], float64(y-r.y)-s[7]-s[9])
+		} else {
+			r.curveTo(s[6], s[7], s[8], s[9], float64(x-r.x)-s[6]-s[8], s[10])
+		}
+	default:
+		return fmt.Errorf("unsupported charstring operator 12 %d", op)
+	}
+	return nil
+}
+
+func (r *charstringRunner) push(v float64) error {
+	if len(r.stack) >= cffMaxStack {
+		return fmt.Errorf("charstring stack overflow")
+	}
+	r.stack = append(r.stack, v)
+	return nil
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,326 @@
+// Package font parses TrueType and OpenType (CFF) font files and tessellates
+// glyph outlines into triangle meshes using libtess2.
+//
+// The parser has no dependencies outside the standard library. It reads the
+// tables needed to turn a rune into a filled outline: cmap, head, hhea, hmtx,
+// maxp and either loca/glyf (TrueType outlines) or CFF (PostScript outlines).
+// Hinting, shaping and kerning are not supported.
+package font
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GlyphIndex identifies a glyph within a font.
+type GlyphIndex uint16
+
+// Font is a parsed TrueType or OpenType font.
+// A Font is immutable after Parse and safe for concurrent use.
+type Font struct {
+	data []byte
+
+	unitsPerEm       int
+	numGlyphs        int
+	indexToLocFormat int
+	ascender         int
+	descender        int
+	lineGap          int
+	numHMetrics      int
+
+	cmap []byte
+	hmtx []byte
+	loca []byte
+	glyf []byte
+	cff  *cffFont
+}
+
+// Parse parses a TrueType (.ttf) or OpenType (.otf) font file.
+// The data slice is retained by the returned Font and must not be modified.
+func Parse(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("font data too short: %d bytes", len(data))
+	}
+
+	switch tag := string(data[0:4]); tag {
+	case "\x00\x01\x00\x00", "true", "OTTO":
+	case "ttcf":
+		return nil, fmt.Errorf("font collections are not supported")
+	default:
+		return nil, fmt.Errorf("unsupported font format %q", tag)
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, fmt.Errorf("table directory truncated")
+	}
+
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := data[12+16*i:]
+		tag := string(rec[0:4])
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) || offset+length < offset {
+			return nil, fmt.Errorf("table %q out of bounds", tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+
+	f := &Font{data: data}
+	if err := f.parseHead(tables["head"]); err != nil {
+		return nil, err
+	}
+	if err := f.parseMaxp(tables["maxp"]); err != nil {
+		return nil, err
+	}
+	if err := f.parseHhea(tables["hhea"]); err != nil {
+		return nil, err
+	}
+
+	f.hmtx = tables["hmtx"]
+	if len(f.hmtx) < 4*f.numHMetrics {
+		return nil, fmt.Errorf("hmtx table truncated")
+	}
+
+	f.cmap = tables["cmap"]
+	if f.cmap == nil {
+		return nil, fmt.Errorf("missing cmap table")
+	}
+
+	if cff, ok := tables["CFF "]; ok {
+		c, err := parseCFF(cff)
+		if err != nil {
+			return nil, fmt.Errorf("parsing CFF table: %w", err)
+		}
+		f.cff = c
+	} else {
+		f.loca = tables["loca"]
+		f.glyf = tables["glyf"]
+		if f.loca == nil || f.glyf == nil {
+			return nil, fmt.Errorf("missing glyf/loca or CFF outlines")
+		}
+		entry := 2
+		if f.indexToLocFormat != 0 {
+			entry = 4
+		}
+		if len(f.loca) < entry*(f.numGlyphs+1) {
+			return nil, fmt.Errorf("loca table truncated")
+		}
+	}
+
+	return f, nil
+}
+
+func (f *Font) parseHead(b []byte) error {
+	if len(b) < 54 {
+		return fmt.Errorf("missing or truncated head table")
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(b[18:]))
+	if f.unitsPerEm == 0 {
+		return fmt.Errorf("head table has zero unitsPerEm")
+	}
+	f.indexToLocFormat = int(int16(binary.BigEndian.Uint16(b[50:])))
+	return nil
+}
+
+func (f *Font) parseMaxp(b []byte) error {
+	if len(b) < 6 {
+		return fmt.Errorf("missing or truncated maxp table")
+	}
+	f.numGlyphs = int(binary.BigEndian.Uint16(b[4:]))
+	return nil
+}
+
+func (f *Font) parseHhea(b []byte) error {
+	if len(b) < 36 {
+		return fmt.Errorf("missing or truncated hhea table")
+	}
+	f.ascender = int(int16(binary.BigEndian.Uint16(b[4:])))
+	f.descender = int(int16(binary.BigEndian.Uint16(b[6:])))
+	f.lineGap = int(int16(binary.BigEndian.Uint16(b[8:])))
+	f.numHMetrics = int(binary.BigEndian.Uint16(b[34:]))
+	if f.numHMetrics == 0 {
+		return fmt.Errorf("hhea table has zero numberOfHMetrics")
+	}
+	return nil
+}
+
+// UnitsPerEm returns the number of font design units per em.
+func (f *Font) UnitsPerEm() int {
+	return f.unitsPerEm
+}
+
+// NumGlyphs returns the number of glyphs in the font.
+func (f *Font) NumGlyphs() int {
+	return f.numGlyphs
+}
+
+// LineHeight returns the recommended distance between baselines in font units.
+func (f *Font) LineHeight() int {
+	return f.ascender - f.descender + f.lineGap
+}
+
+// Advance returns the horizontal advance width of a glyph in font units.
+func (f *Font) Advance(g GlyphIndex) int {
+	i := int(g)
+	if i >= f.numHMetrics {
+		i = f.numHMetrics - 1
+	}
+	return int(binary.BigEndian.Uint16(f.hmtx[4*i:]))
+}
+
+// GlyphIndex returns the glyph index for a rune, or 0 (the .notdef glyph)
+// when the font has no mapping for it.
+func (f *Font) GlyphIndex(r rune) GlyphIndex {
+	b := f.cmap
+	if len(b) < 4 {
+		return 0
+	}
+
+	// Prefer full Unicode tables, then BMP tables.
+	var best []byte
+	bestScore := 0
+	numTables := int(binary.BigEndian.Uint16(b[2:]))
+	for i := 0; i < numTables && 4+8*i+8 <= len(b); i++ {
+		rec := b[4+8*i:]
+		platform := binary.BigEndian.Uint16(rec[0:])
+		encoding := binary.BigEndian.Uint16(rec[2:])
+		offset := int(binary.BigEndian.Uint32(rec[4:]))
+		if offset+4 > len(b) {
+			continue
+		}
+
+		score := 0
+		switch {
+		case platform == 3 && encoding == 10, platform == 0 && (encoding == 4 || encoding == 6):
+			score = 3
+		case platform == 3 && encoding == 1, platform == 0:
+			score = 2
+		case platform == 3 && encoding == 0:
+			score = 1
+		}
+		if score > bestScore {
+			best = b[offset:]
+			bestScore = score
+		}
+	}
+	if best == nil {
+		return 0
+	}
+
+	switch binary.BigEndian.Uint16(best) {
+	case 4:
+		return cmapFormat4(best, r)
+	case 6:
+		return cmapFormat6(best, r)
+	case 12:
+		return cmapFormat12(best, r)
+	}
+	return 0
+}
+
+func cmapFormat4(b []byte, r rune) GlyphIndex {
+	if r < 0 || r > 0xffff || len(b) < 14 {
+		return 0
+	}
+	c := uint16(r)
+	segCount := int(binary.BigEndian.Uint16(b[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if idRangeOffsets+2*segCount > len(b) {
+		return 0
+	}
+
+	// Binary search for the first segment whose end code is >= c.
+	lo, hi := 0, segCount
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if binary.BigEndian.Uint16(b[endCodes+2*mid:]) < c {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo >= segCount {
+		return 0
+	}
+
+	start := binary.BigEndian.Uint16(b[startCodes+2*lo:])
+	if c < start {
+		return 0
+	}
+	delta := binary.BigEndian.Uint16(b[idDeltas+2*lo:])
+	rangeOffset := int(binary.BigEndian.Uint16(b[idRangeOffsets+2*lo:]))
+	if rangeOffset == 0 {
+		return GlyphIndex(c + delta)
+	}
+
+	p := idRangeOffsets + 2*lo + rangeOffset + 2*int(c-start)
+	if p+2 > len(b) {
+		return 0
+	}
+	g := binary.BigEndian.Uint16(b[p:])
+	if g == 0 {
+		return 0
+	}
+	return GlyphIndex(g + delta)
+}
+
+func cmapFormat6(b []byte, r rune) GlyphIndex {
+	if len(b) < 10 {
+		return 0
+	}
+	first := rune(binary.BigEndian.Uint16(b[6:]))
+	count := rune(binary.BigEndian.Uint16(b[8:]))
+	if r < first || r >= first+count {
+		return 0
+	}
+	p := 10 + 2*int(r-first)
+	if p+2 > len(b) {
+		return 0
+	}
+	return GlyphIndex(binary.BigEndian.Uint16(b[p:]))
+}
+
+func cmapFormat12(b []byte, r rune) GlyphIndex {
+	if len(b) < 16 || r < 0 {
+		return 0
+	}
+	c := uint32(r)
+	numGroups := int(binary.BigEndian.Uint32(b[12:]))
+	if 16+12*numGroups > len(b) || numGroups < 0 {
+		return 0
+	}
+
+	lo, hi := 0, numGroups
+	for lo < hi {
+		mid := (lo + hi) / 2
+		group := b[16+12*mid:]
+		start := binary.BigEndian.Uint32(group[0:])
+		end := binary.BigEndian.Uint32(group[4:])
+		switch {
+		case c < start:
+			hi = mid
+		case c > end:
+			lo = mid + 1
+		default:
+			return GlyphIndex(binary.BigEndian.Uint32(group[8:]) + c - start)
+		}
+	}
+	return 0
+}
+
+// Outline returns the outline of a glyph in font units, with y pointing up.
+// Glyphs without contours (such as the space glyph) return an empty outline.
+func (f *Font) Outline(g GlyphIndex) (Outline, error) {
+	if int(g) >= f.numGlyphs {
+		return nil, fmt.Errorf("glyph index %d out of range (%d glyphs)", g, f.numGlyphs)
+	}
+	if f.cff != nil {
+		return f.cff.outline(g)
+	}
+	return f.glyfOutline(g, 0)
+}
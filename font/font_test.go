@@ -0,0 +1,572 @@
+package font
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"sort"
+	"testing"
+)
+
+// be is a small big-endian byte builder used to assemble test fonts.
+type be struct{ bytes.Buffer }
+
+func (b *be) u8(v int)  { b.WriteByte(byte(v)) }
+func (b *be) u16(v int) { binary.Write(&b.Buffer, binary.BigEndian, uint16(v)) }
+func (b *be) i16(v int) { binary.Write(&b.Buffer, binary.BigEndian, int16(v)) }
+func (b *be) u32(v int) { binary.Write(&b.Buffer, binary.BigEndian, uint32(v)) }
+
+// buildSFNT assembles an sfnt container from raw tables.
+func buildSFNT(version string, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var out be
+	out.WriteString(version)
+	out.u16(len(tags))
+	out.u16(0)
+	out.u16(0)
+	out.u16(0)
+
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		out.WriteString(tag)
+		out.u32(0)
+		out.u32(offset)
+		out.u32(len(tables[tag]))
+		offset += (len(tables[tag]) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		out.Write(tables[tag])
+		for out.Len()%4 != 0 {
+			out.u8(0)
+		}
+	}
+	return out.Bytes()
+}
+
+// commonTables returns head, hhea, maxp, hmtx and a format 4 cmap for a font
+// with the given glyph count, advances and rune mapping.
+func commonTables(numGlyphs int, advance int, runes map[rune]int) map[string][]byte {
+	var head be
+	head.Write(make([]byte, 18))
+	head.u16(1000) // unitsPerEm
+	head.Write(make([]byte, 30))
+	head.i16(0) // indexToLocFormat
+	head.i16(0)
+
+	var hhea be
+	hhea.u32(0x00010000)
+	hhea.i16(800)  // ascender
+	hhea.i16(-200) // descender
+	hhea.i16(100)  // lineGap
+	hhea.Write(make([]byte, 24))
+	hhea.u16(numGlyphs)
+
+	var maxp be
+	maxp.u32(0x00005000)
+	maxp.u16(numGlyphs)
+
+	var hmtx be
+	for i := 0; i < numGlyphs; i++ {
+		hmtx.u16(advance)
+		hmtx.i16(0)
+	}
+
+	codes := make([]int, 0, len(runes))
+	for r := range runes {
+		codes = append(codes, int(r))
+	}
+	sort.Ints(codes)
+	codes = append(codes, 0xffff)
+
+	var sub be
+	segCount := len(codes)
+	sub.u16(4)
+	sub.u16(16 + 8*segCount)
+	sub.u16(0)
+	sub.u16(2 * segCount)
+	sub.u16(0)
+	sub.u16(0)
+	sub.u16(0)
+	for _, c := range codes {
+		sub.u16(c)
+	}
+	sub.u16(0)
+	for _, c := range codes {
+		sub.u16(c)
+	}
+	for _, c := range codes {
+		if c == 0xffff {
+			sub.u16(1)
+		} else {
+			sub.u16((runes[rune(c)] - c) & 0xffff)
+		}
+	}
+	for range codes {
+		sub.u16(0)
+	}
+
+	var cmap be
+	cmap.u16(0)
+	cmap.u16(1)
+	cmap.u16(3)
+	cmap.u16(1)
+	cmap.u32(12)
+	cmap.Write(sub.Bytes())
+
+	return map[string][]byte{
+		"head": head.Bytes(),
+		"hhea": hhea.Bytes(),
+		"maxp": maxp.Bytes(),
+		"hmtx": hmtx.Bytes(),
+		"cmap": cmap.Bytes(),
+	}
+}
+
+// simpleGlyf encodes a simple glyph using 16-bit coordinates.
+func simpleGlyf(contours [][][3]int) []byte {
+	var b be
+	b.i16(len(contours))
+	b.Write(make([]byte, 8))
+	end := -1
+	for _, c := range contours {
+		end += len(c)
+		b.u16(end)
+	}
+	b.u16(0)
+	for _, c := range contours {
+		for _, p := range c {
+			b.u8(p[2] & flagOnCurve)
+		}
+	}
+	prev := 0
+	for _, c := range contours {
+		for _, p := range c {
+			b.i16(p[0] - prev)
+			prev = p[0]
+		}
+	}
+	prev = 0
+	for _, c := range contours {
+		for _, p := range c {
+			b.i16(p[1] - prev)
+			prev = p[1]
+		}
+	}
+	return b.Bytes()
+}
+
+// testTrueType builds a TrueType font with four glyphs: an empty .notdef,
+// a square ('A'), a circle made only of off-curve points ('O') and a
+// composite glyph placing the square twice ('C').
+func testTrueType(t *testing.T) []byte {
+	t.Helper()
+
+	square := simpleGlyf([][][3]int{{{0, 0, 1}, {0, 500, 1}, {500, 500, 1}, {500, 0, 1}}})
+	circle := simpleGlyf([][][3]int{{{0, 250, 0}, {250, 500, 0}, {500, 250, 0}, {250, 0, 0}}})
+
+	var composite be
+	composite.i16(-1)
+	composite.Write(make([]byte, 8))
+	composite.u16(compArgsAreWords | compArgsAreXY | compMoreComponents)
+	composite.u16(1)
+	composite.i16(0)
+	composite.i16(0)
+	composite.u16(compArgsAreWords | compArgsAreXY | compHaveScale)
+	composite.u16(1)
+	composite.i16(600)
+	composite.i16(0)
+	composite.i16(8192) // 0.5 in F2Dot14
+
+	var glyf, loca be
+	loca.u16(0)
+	loca.u16(0) // glyph 0 is empty
+	for _, g := range [][]byte{square, circle, composite.Bytes()} {
+		glyf.Write(g)
+		if glyf.Len()%2 != 0 {
+			glyf.u8(0)
+		}
+		loca.u16(glyf.Len() / 2)
+	}
+
+	tables := commonTables(4, 600, map[rune]int{'A': 1, 'O': 2, 'C': 3})
+	tables["glyf"] = glyf.Bytes()
+	tables["loca"] = loca.Bytes()
+	return buildSFNT("\x00\x01\x00\x00", tables)
+}
+
+// cffIndex encodes a CFF INDEX with 2-byte offsets.
+func cffIndex(objects ...[]byte) []byte {
+	var b be
+	b.u16(len(objects))
+	if len(objects) == 0 {
+		return b.Bytes()
+	}
+	b.u8(2)
+	off := 1
+	b.u16(off)
+	for _, o := range objects {
+		off += len(o)
+		b.u16(off)
+	}
+	for _, o := range objects {
+		b.Write(o)
+	}
+	return b.Bytes()
+}
+
+// csInt encodes a charstring or DICT integer operand using the 28 prefix.
+func csInt(v int) []byte {
+	return []byte{28, byte(v >> 8), byte(v)}
+}
+
+// csOps encodes integer operands followed by raw operator bytes.
+func csOps(args []int, ops ...byte) []byte {
+	var b []byte
+	for _, a := range args {
+		b = append(b, csInt(a)...)
+	}
+	return append(b, ops...)
+}
+
+// testCFF builds an OpenType font with CFF outlines: an empty .notdef,
+// a square drawn with lines ('A') and a shape drawn with curves from a
+// local and a global subroutine ('O').
+func testCFF(t *testing.T) []byte {
+	t.Helper()
+
+	square := csOps([]int{600, 0, 0}, 21)                           // width, rmoveto
+	square = append(square, csOps([]int{500, 500, -500}, 6, 14)...) // hlineto, endchar
+
+	// Global subr 0 curves up and right, local subr 0 curves back.
+	gsubr := csOps([]int{0, 250, 250, 250, 250, -250}, 8, 11)
+	lsubr := csOps([]int{0, -250, -250, -250, -250, 250}, 8, 11)
+
+	curvy := csOps([]int{0, 10, 20}, 1, 19, 0x80) // width, hstem, hintmask
+	curvy = append(curvy, csOps([]int{0, 250}, 21)...)
+	curvy = append(curvy, csOps([]int{-107}, 29)...)     // callgsubr 0
+	curvy = append(curvy, csOps([]int{-107}, 10, 14)...) // callsubr 0, endchar
+
+	charStrings := cffIndex([]byte{14}, square, curvy)
+	localSubrs := cffIndex(lsubr)
+
+	// Private DICT: Subrs offset (relative to the private dict) is its own size.
+	privateBody := func(subrsOff int) []byte {
+		return append(csInt(subrsOff), 19)
+	}
+	private := privateBody(len(privateBody(0)))
+
+	header := []byte{1, 0, 4, 2}
+	names := cffIndex([]byte("Test"))
+	strings := cffIndex()
+	gsubrs := cffIndex(gsubr)
+
+	// The Top DICT has fixed-size operands so its length does not depend on
+	// the offsets written into it.
+	topDict := func(csOff, privOff int) []byte {
+		var b []byte
+		b = append(b, csInt(csOff)...)
+		b = append(b, 17)
+		b = append(b, csInt(len(private))...)
+		b = append(b, csInt(privOff)...)
+		b = append(b, 18)
+		return b
+	}
+	topLen := len(cffIndex(topDict(0, 0)))
+	csOff := len(header) + len(names) + topLen + len(strings) + len(gsubrs)
+	privOff := csOff + len(charStrings)
+
+	var cff []byte
+	cff = append(cff, header...)
+	cff = append(cff, names...)
+	cff = append(cff, cffIndex(topDict(csOff, privOff))...)
+	cff = append(cff, strings...)
+	cff = append(cff, gsubrs...)
+	cff = append(cff, charStrings...)
+	cff = append(cff, private...)
+	cff = append(cff, localSubrs...)
+
+	tables := commonTables(3, 600, map[rune]int{'A': 1, 'O': 2})
+	tables["CFF "] = cff
+	return buildSFNT("OTTO", tables)
+}
+
+// meshArea returns the total signed area of a triangle mesh.
+func meshArea(m Mesh) float64 {
+	area := 0.0
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := m.Indices[i], m.Indices[i+1], m.Indices[i+2]
+		ax, ay := float64(m.Vertices[2*a]), float64(m.Vertices[2*a+1])
+		bx, by := float64(m.Vertices[2*b]), float64(m.Vertices[2*b+1])
+		cx, cy := float64(m.Vertices[2*c]), float64(m.Vertices[2*c+1])
+		area += ((bx-ax)*(cy-ay) - (cx-ax)*(by-ay)) / 2
+	}
+	return area
+}
+
+// TestParseTrueType tests parsing of tables and glyph outlines from glyf
+func TestParseTrueType(t *testing.T) {
+	f, err := Parse(testTrueType(t))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if f.UnitsPerEm() != 1000 {
+		t.Errorf("Expected unitsPerEm 1000, got %d", f.UnitsPerEm())
+	}
+	if f.NumGlyphs() != 4 {
+		t.Errorf("Expected 4 glyphs, got %d", f.NumGlyphs())
+	}
+	if f.LineHeight() != 1100 {
+		t.Errorf("Expected line height 1100, got %d", f.LineHeight())
+	}
+
+	for r, want := range map[rune]GlyphIndex{'A': 1, 'O': 2, 'C': 3, 'Z': 0} {
+		if got := f.GlyphIndex(r); got != want {
+			t.Errorf("GlyphIndex(%q) = %d, want %d", r, got, want)
+		}
+	}
+
+	outline, err := f.Outline(1)
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	if len(outline) != 5 || outline[0].Op != SegmentMoveTo {
+		t.Errorf("Expected moveto + 4 lines for square, got %v", outline)
+	}
+
+	outline, err = f.Outline(2)
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	quads := 0
+	for _, s := range outline {
+		if s.Op == SegmentQuadTo {
+			quads++
+		}
+	}
+	if quads != 4 {
+		t.Errorf("Expected 4 quadratic segments for all off-curve contour, got %d", quads)
+	}
+
+	outline, err = f.Outline(3)
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	if len(outline) != 10 {
+		t.Fatalf("Expected two transformed squares in composite, got %d segments", len(outline))
+	}
+	if p := outline[7].Args[0]; p.X != 850 || p.Y != 250 {
+		t.Errorf("Expected scaled and offset component point (850, 250), got %v", p)
+	}
+
+	if _, err := f.Outline(10); err == nil {
+		t.Error("Expected error for out of range glyph")
+	}
+}
+
+// TestParseCFF tests decoding of Type 2 charstrings including subroutines
+func TestParseCFF(t *testing.T) {
+	f, err := Parse(testCFF(t))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	outline, err := f.Outline(1)
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	want := []Point{{0, 0}, {500, 0}, {500, 500}, {0, 500}}
+	if len(outline) != len(want) {
+		t.Fatalf("Expected %d segments, got %v", len(want), outline)
+	}
+	for i, s := range outline {
+		if s.Args[0] != want[i] {
+			t.Errorf("Segment %d: expected %v, got %v", i, want[i], s.Args[0])
+		}
+	}
+
+	outline, err = f.Outline(2)
+	if err != nil {
+		t.Fatalf("Outline failed: %v", err)
+	}
+	if len(outline) != 3 || outline[1].Op != SegmentCubeTo || outline[2].Op != SegmentCubeTo {
+		t.Fatalf("Expected moveto and two curves, got %v", outline)
+	}
+	if end := outline[2].Args[2]; end != (Point{0, 250}) {
+		t.Errorf("Expected curves to return to start, ended at %v", end)
+	}
+
+	empty, err := f.Outline(0)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Expected empty .notdef outline, got %v, %v", empty, err)
+	}
+}
+
+// TestParseErrors tests that malformed data is rejected
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(nil); err == nil {
+		t.Error("Expected error for empty data")
+	}
+	if _, err := Parse([]byte("wOFFxxxxxxxxxxxx")); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+
+	data := testTrueType(t)
+	if _, err := Parse(data[:40]); err == nil {
+		t.Error("Expected error for truncated font")
+	}
+}
+
+// TestFlatten tests curve flattening tolerance
+func TestFlatten(t *testing.T) {
+	outline := Outline{
+		{Op: SegmentMoveTo, Args: [3]Point{{0, 0}}},
+		{Op: SegmentQuadTo, Args: [3]Point{{50, 100}, {100, 0}}},
+		{Op: SegmentLineTo, Args: [3]Point{{0, 0}}},
+	}
+
+	coarse := outline.Flatten(10)
+	fine := outline.Flatten(0.1)
+	if len(coarse) != 1 || len(fine) != 1 {
+		t.Fatalf("Expected one contour, got %d and %d", len(coarse), len(fine))
+	}
+	if len(fine[0]) <= len(coarse[0]) {
+		t.Errorf("Expected finer tolerance to produce more vertices, got %d <= %d", len(fine[0]), len(coarse[0]))
+	}
+
+	// The explicit closing point must be dropped.
+	c := fine[0]
+	if c[len(c)-2] == 0 && c[len(c)-1] == 0 {
+		t.Error("Expected closing vertex to be removed")
+	}
+
+	// Every flattened vertex lies on the curve y = 2x - x^2/50.
+	for i := 0; i < len(c); i += 2 {
+		x, y := float64(c[i]), float64(c[i+1])
+		if want := 2*x - x*x/50; math.Abs(y-want) > 0.01 {
+			t.Errorf("Vertex (%v, %v) not on curve (want y=%v)", x, y, want)
+		}
+	}
+
+	degenerate := Outline{
+		{Op: SegmentMoveTo, Args: [3]Point{{0, 0}}},
+		{Op: SegmentLineTo, Args: [3]Point{{10, 0}}},
+	}
+	if got := degenerate.Flatten(1); len(got) != 0 {
+		t.Errorf("Expected degenerate contour to be dropped, got %v", got)
+	}
+}
+
+// TestGlyphMeshes tests tessellation of glyphs from both outline formats
+func TestGlyphMeshes(t *testing.T) {
+	for name, data := range map[string][]byte{"TrueType": testTrueType(t), "CFF": testCFF(t)} {
+		t.Run(name, func(t *testing.T) {
+			f, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+
+			m, err := TessellateGlyph(f, f.GlyphIndex('A'), 1)
+			if err != nil {
+				t.Fatalf("TessellateGlyph failed: %v", err)
+			}
+			if len(m.Indices) != 6 {
+				t.Errorf("Expected 2 triangles for square, got %d indices", len(m.Indices))
+			}
+			if area := meshArea(m.Mesh); math.Abs(area-250000) > 1 {
+				t.Errorf("Expected square area 250000, got %v", area)
+			}
+			if m.Advance != 600 {
+				t.Errorf("Expected advance 600, got %v", m.Advance)
+			}
+
+			m, err = TessellateGlyph(f, 0, 1)
+			if err != nil {
+				t.Fatalf("TessellateGlyph failed for empty glyph: %v", err)
+			}
+			if len(m.Vertices) != 0 || len(m.Indices) != 0 {
+				t.Errorf("Expected empty mesh for empty glyph, got %d vertices", len(m.Vertices))
+			}
+
+			m, err = TessellateGlyph(f, f.GlyphIndex('O'), 0.5)
+			if err != nil {
+				t.Fatalf("TessellateGlyph failed: %v", err)
+			}
+			if area := meshArea(m.Mesh); area <= 0 {
+				t.Errorf("Expected positive area for curved glyph, got %v", area)
+			}
+		})
+	}
+}
+
+// TestCacheLayout tests glyph caching and string layout
+func TestCacheLayout(t *testing.T) {
+	f, err := Parse(testTrueType(t))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	c := NewCache(f, 0)
+
+	g1, err := c.Glyph(1)
+	if err != nil {
+		t.Fatalf("Glyph failed: %v", err)
+	}
+	g2, err := c.Glyph(1)
+	if err != nil {
+		t.Fatalf("Glyph failed: %v", err)
+	}
+	if g1 != g2 {
+		t.Error("Expected cached glyph mesh to be reused")
+	}
+
+	m, err := c.Layout("AA\nA ", 10)
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	if len(m.Indices) != 18 {
+		t.Errorf("Expected 6 triangles, got %d indices", len(m.Indices))
+	}
+	// Each square is 5x5 at size 10 and the total area is three squares.
+	if area := meshArea(*m); math.Abs(area-75) > 1e-3 {
+		t.Errorf("Expected total area 75, got %v", area)
+	}
+
+	var minX, maxX, minY float32 = 1e9, -1e9, 1e9
+	for i := 0; i < len(m.Vertices); i += 2 {
+		minX = min(minX, m.Vertices[i])
+		maxX = max(maxX, m.Vertices[i])
+		minY = min(minY, m.Vertices[i+1])
+	}
+	if minX != 0 || maxX != 11 {
+		t.Errorf("Expected x range [0, 11], got [%v, %v]", minX, maxX)
+	}
+	if minY != -11 {
+		t.Errorf("Expected second line at y=-11, got %v", minY)
+	}
+}
+
+// TestSystemFont tests parsing a real font when one is installed
+func TestSystemFont(t *testing.T) {
+	data, err := os.ReadFile("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf")
+	if err != nil {
+		t.Skip("DejaVuSans.ttf not installed")
+	}
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m, err := NewCache(f, 0).Layout("Hello, wörld!", 32)
+	if err != nil {
+		t.Fatalf("Layout failed: %v", err)
+	}
+	if len(m.Indices) == 0 || meshArea(*m) <= 0 {
+		t.Errorf("Expected non-empty mesh with positive area")
+	}
+}
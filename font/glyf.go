@@ -0,0 +1,303 @@
+package font
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Simple glyph point flags.
+const (
+	flagOnCurve      = 0x01
+	flagXShort       = 0x02
+	flagYShort       = 0x04
+	flagRepeat       = 0x08
+	flagXSameOrPos   = 0x10
+	flagYSameOrPos   = 0x20
+	maxCompoundDepth = 8
+)
+
+// Composite glyph component flags.
+const (
+	compArgsAreWords   = 0x0001
+	compArgsAreXY      = 0x0002
+	compHaveScale      = 0x0008
+	compMoreComponents = 0x0020
+	compHaveXYScale    = 0x0040
+	compHaveTwoByTwo   = 0x0080
+)
+
+// glyfData returns the raw glyf entry for a glyph, or nil for empty glyphs.
+func (f *Font) glyfData(g GlyphIndex) ([]byte, error) {
+	var start, end int
+	if f.indexToLocFormat == 0 {
+		start = 2 * int(binary.BigEndian.Uint16(f.loca[2*int(g):]))
+		end = 2 * int(binary.BigEndian.Uint16(f.loca[2*int(g)+2:]))
+	} else {
+		start = int(binary.BigEndian.Uint32(f.loca[4*int(g):]))
+		end = int(binary.BigEndian.Uint32(f.loca[4*int(g)+4:]))
+	}
+	if start == end {
+		return nil, nil
+	}
+	if start > end || end > len(f.glyf) {
+		return nil, fmt.Errorf("glyph %d: invalid loca range [%d, %d)", g, start, end)
+	}
+	if end-start < 10 {
+		return nil, fmt.Errorf("glyph %d: header truncated", g)
+	}
+	return f.glyf[start:end], nil
+}
+
+// glyfOutline decodes a TrueType glyph, following composite references up to
+// maxCompoundDepth levels deep.
+func (f *Font) glyfOutline(g GlyphIndex, depth int) (Outline, error) {
+	if depth > maxCompoundDepth {
+		return nil, fmt.Errorf("glyph %d: composite glyph nesting too deep", g)
+	}
+	b, err := f.glyfData(g)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	numContours := int(int16(binary.BigEndian.Uint16(b)))
+	if numContours >= 0 {
+		return simpleGlyph(b, numContours)
+	}
+	return f.compositeGlyph(b[10:], depth)
+}
+
+// simpleGlyph decodes the points of a simple glyph and converts its
+// quadratic on/off-curve point sequence into outline segments.
+func simpleGlyph(b []byte, numContours int) (Outline, error) {
+	p := 10
+	if p+2*numContours+2 > len(b) {
+		return nil, fmt.Errorf("simple glyph truncated")
+	}
+	endPts := make([]int, numContours)
+	numPoints := 0
+	for i := range endPts {
+		endPts[i] = int(binary.BigEndian.Uint16(b[p:]))
+		p += 2
+		if endPts[i] < numPoints-1 {
+			return nil, fmt.Errorf("simple glyph contour end points are not increasing")
+		}
+		numPoints = endPts[i] + 1
+	}
+	instructionLength := int(binary.BigEndian.Uint16(b[p:]))
+	p += 2 + instructionLength
+
+	flags := make([]byte, numPoints)
+	for i := 0; i < numPoints; {
+		if p >= len(b) {
+			return nil, fmt.Errorf("simple glyph flags truncated")
+		}
+		flag := b[p]
+		p++
+		flags[i] = flag
+		i++
+		if flag&flagRepeat != 0 {
+			if p >= len(b) {
+				return nil, fmt.Errorf("simple glyph flags truncated")
+			}
+			count := int(b[p])
+			p++
+			for ; count > 0 && i < numPoints; count-- {
+				flags[i] = flag
+				i++
+			}
+		}
+	}
+
+	points := make([]Point, numPoints)
+	var err error
+	if p, err = readCoordinates(b, p, flags, points, flagXShort, flagXSameOrPos, func(pt *Point, v int) { pt.X = float32(v) }); err != nil {
+		return nil, err
+	}
+	if _, err = readCoordinates(b, p, flags, points, flagYShort, flagYSameOrPos, func(pt *Point, v int) { pt.Y = float32(v) }); err != nil {
+		return nil, err
+	}
+
+	var out Outline
+	start := 0
+	for _, end := range endPts {
+		out = appendQuadContour(out, points[start:end+1], flags[start:end+1])
+		start = end + 1
+	}
+	return out, nil
+}
+
+// readCoordinates decodes one axis of delta-encoded glyph coordinates.
+func readCoordinates(b []byte, p int, flags []byte, points []Point, short, same byte, set func(*Point, int)) (int, error) {
+	v := 0
+	for i, flag := range flags {
+		switch {
+		case flag&short != 0:
+			if p >= len(b) {
+				return p, fmt.Errorf("simple glyph coordinates truncated")
+			}
+			d := int(b[p])
+			p++
+			if flag&same == 0 {
+				d = -d
+			}
+			v += d
+		case flag&same == 0:
+			if p+2 > len(b) {
+				return p, fmt.Errorf("simple glyph coordinates truncated")
+			}
+			v += int(int16(binary.BigEndian.Uint16(b[p:])))
+			p += 2
+		}
+		set(&points[i], v)
+	}
+	return p, nil
+}
+
+// appendQuadContour converts a closed TrueType contour into segments.
+// Consecutive off-curve points imply an on-curve point halfway between them.
+func appendQuadContour(out Outline, points []Point, flags []byte) Outline {
+	n := len(points)
+	if n == 0 {
+		return out
+	}
+	on := func(i int) bool { return flags[i%n]&flagOnCurve != 0 }
+	mid := func(a, b Point) Point { return Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2} }
+
+	// Find a starting on-curve point, synthesizing one if every point is off-curve.
+	first := -1
+	for i := 0; i < n; i++ {
+		if on(i) {
+			first = i
+			break
+		}
+	}
+	var start Point
+	if first < 0 {
+		start = mid(points[0], points[1%n])
+		out = append(out, Segment{Op: SegmentMoveTo, Args: [3]Point{start}})
+		// Every point is a control point, starting with points[1].
+		for i := 1; i <= n; i++ {
+			ctrl := points[i%n]
+			next := mid(ctrl, points[(i+1)%n])
+			out = append(out, Segment{Op: SegmentQuadTo, Args: [3]Point{ctrl, next}})
+		}
+		return out
+	}
+
+	start = points[first]
+	out = append(out, Segment{Op: SegmentMoveTo, Args: [3]Point{start}})
+
+	var ctrl Point
+	haveCtrl := false
+	for k := 1; k <= n; k++ {
+		i := (first + k) % n
+		pt := points[i]
+		if on(i) {
+			if haveCtrl {
+				out = append(out, Segment{Op: SegmentQuadTo, Args: [3]Point{ctrl, pt}})
+				haveCtrl = false
+			} else {
+				out = append(out, Segment{Op: SegmentLineTo, Args: [3]Point{pt}})
+			}
+			continue
+		}
+		if haveCtrl {
+			m := mid(ctrl, pt)
+			out = append(out, Segment{Op: SegmentQuadTo, Args: [3]Point{ctrl, m}})
+		}
+		ctrl = pt
+		haveCtrl = true
+	}
+	if haveCtrl {
+		out = append(out, Segment{Op: SegmentQuadTo, Args: [3]Point{ctrl, start}})
+	}
+	return out
+}
+
+// compositeGlyph decodes a composite glyph by transforming and concatenating
+// the outlines of its components.
+func (f *Font) compositeGlyph(b []byte, depth int) (Outline, error) {
+	var out Outline
+	p := 0
+	for {
+		if p+4 > len(b) {
+			return nil, fmt.Errorf("composite glyph truncated")
+		}
+		flags := binary.BigEndian.Uint16(b[p:])
+		component := GlyphIndex(binary.BigEndian.Uint16(b[p+2:]))
+		p += 4
+
+		var dx, dy int
+		if flags&compArgsAreWords != 0 {
+			if p+4 > len(b) {
+				return nil, fmt.Errorf("composite glyph truncated")
+			}
+			dx = int(int16(binary.BigEndian.Uint16(b[p:])))
+			dy = int(int16(binary.BigEndian.Uint16(b[p+2:])))
+			p += 4
+		} else {
+			if p+2 > len(b) {
+				return nil, fmt.Errorf("composite glyph truncated")
+			}
+			dx = int(int8(b[p]))
+			dy = int(int8(b[p+1]))
+			p += 2
+		}
+		if flags&compArgsAreXY == 0 {
+			return nil, fmt.Errorf("composite glyph point matching is not supported")
+		}
+
+		// 2x2 transform in F2Dot14, identity by default.
+		a, bb, c, d := float32(1), float32(0), float32(0), float32(1)
+		f2dot14 := func() float32 {
+			v := float32(int16(binary.BigEndian.Uint16(b[p:]))) / 16384
+			p += 2
+			return v
+		}
+		switch {
+		case flags&compHaveScale != 0:
+			if p+2 > len(b) {
+				return nil, fmt.Errorf("composite glyph truncated")
+			}
+			a = f2dot14()
+			d = a
+		case flags&compHaveXYScale != 0:
+			if p+4 > len(b) {
+				return nil, fmt.Errorf("composite glyph truncated")
+			}
+			a = f2dot14()
+			d = f2dot14()
+		case flags&compHaveTwoByTwo != 0:
+			if p+8 > len(b) {
+				return nil, fmt.Errorf("composite glyph truncated")
+			}
+			a = f2dot14()
+			bb = f2dot14()
+			c = f2dot14()
+			d = f2dot14()
+		}
+
+		if int(component) >= f.numGlyphs {
+			return nil, fmt.Errorf("composite glyph references glyph %d out of range", component)
+		}
+		sub, err := f.glyfOutline(component, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sub {
+			for i := range s.Args {
+				pt := s.Args[i]
+				s.Args[i] = Point{
+					X: a*pt.X + c*pt.Y + float32(dx),
+					Y: bb*pt.X + d*pt.Y + float32(dy),
+				}
+			}
+			out = append(out, s)
+		}
+
+		if flags&compMoreComponents == 0 {
+			break
+		}
+	}
+	return out, nil
+}
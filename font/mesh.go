@@ -0,0 +1,140 @@
+package font
+
+import (
+	"fmt"
+	"sync"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+// Mesh is a 2D triangle mesh. Vertices holds x, y pairs and Indices holds
+// three vertex indices per triangle, wound counter-clockwise.
+type Mesh struct {
+	Vertices []float32
+	Indices  []int
+}
+
+// GlyphMesh is the tessellated outline of a single glyph in font units.
+type GlyphMesh struct {
+	Glyph   GlyphIndex
+	Advance float32
+	Mesh
+}
+
+// Cache tessellates glyph outlines on demand and keeps the resulting meshes
+// for reuse. It is safe for concurrent use.
+type Cache struct {
+	font      *Font
+	tolerance float32
+
+	mu     sync.Mutex
+	glyphs map[GlyphIndex]*GlyphMesh
+}
+
+// NewCache returns a glyph mesh cache for f. Curves are flattened so that
+// they deviate from the true outline by at most tolerance font units; a
+// tolerance <= 0 selects a default of UnitsPerEm/1000.
+func NewCache(f *Font, tolerance float32) *Cache {
+	if tolerance <= 0 {
+		tolerance = float32(f.UnitsPerEm()) / 1000
+	}
+	return &Cache{
+		font:      f,
+		tolerance: tolerance,
+		glyphs:    make(map[GlyphIndex]*GlyphMesh),
+	}
+}
+
+// Glyph returns the mesh of glyph g, tessellating it on first use.
+// The returned mesh is shared and must not be modified.
+func (c *Cache) Glyph(g GlyphIndex) (*GlyphMesh, error) {
+	c.mu.Lock()
+	m, ok := c.glyphs[g]
+	c.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+
+	m, err := TessellateGlyph(c.font, g, c.tolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Another goroutine may have won the race; keep a single instance.
+	if existing, ok := c.glyphs[g]; ok {
+		return existing, nil
+	}
+	c.glyphs[g] = m
+	return m, nil
+}
+
+// Layout lays out text on a single baseline starting at the origin and
+// returns the combined mesh. size is the em size in output units, so
+// coordinates are scaled by size/UnitsPerEm. A '\n' starts a new line below
+// the previous one.
+func (c *Cache) Layout(text string, size float32) (*Mesh, error) {
+	scale := size / float32(c.font.UnitsPerEm())
+	lineHeight := float32(c.font.LineHeight()) * scale
+
+	out := &Mesh{}
+	var penX, penY float32
+	for _, r := range text {
+		if r == '\n' {
+			penX = 0
+			penY -= lineHeight
+			continue
+		}
+
+		g, err := c.Glyph(c.font.GlyphIndex(r))
+		if err != nil {
+			return nil, fmt.Errorf("rune %q: %w", r, err)
+		}
+
+		base := len(out.Vertices) / 2
+		for i := 0; i < len(g.Vertices); i += 2 {
+			out.Vertices = append(out.Vertices, penX+g.Vertices[i]*scale, penY+g.Vertices[i+1]*scale)
+		}
+		for _, idx := range g.Indices {
+			out.Indices = append(out.Indices, base+idx)
+		}
+		penX += g.Advance * scale
+	}
+	return out, nil
+}
+
+// TessellateGlyph flattens the outline of glyph g with the given tolerance
+// and tessellates it using the non-zero winding rule, which is what both
+// TrueType and CFF outlines are defined with.
+func TessellateGlyph(f *Font, g GlyphIndex, tolerance float32) (*GlyphMesh, error) {
+	outline, err := f.Outline(g)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &GlyphMesh{Glyph: g, Advance: float32(f.Advance(g))}
+	contours := outline.Flatten(tolerance)
+	if len(contours) == 0 {
+		m.Vertices = []float32{}
+		m.Indices = []int{}
+		return m, nil
+	}
+
+	t := tess.NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	for _, contour := range contours {
+		if err := t.AddContour(2, contour); err != nil {
+			return nil, fmt.Errorf("glyph %d: %w", g, err)
+		}
+	}
+	m.Vertices, m.Indices, err = t.Tessellate(tess.WindingNonZero, tess.ElementPolygons, 3, 2, []float32{0, 0, 1})
+	if err != nil {
+		return nil, fmt.Errorf("glyph %d: %w", g, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,137 @@
+package font
+
+import "math"
+
+// SegmentOp identifies the kind of an outline segment.
+type SegmentOp int
+
+const (
+	// SegmentMoveTo starts a new contour at Args[0].
+	SegmentMoveTo SegmentOp = iota
+	// SegmentLineTo draws a straight line to Args[0].
+	SegmentLineTo
+	// SegmentQuadTo draws a quadratic Bézier curve with control point
+	// Args[0] ending at Args[1].
+	SegmentQuadTo
+	// SegmentCubeTo draws a cubic Bézier curve with control points
+	// Args[0] and Args[1] ending at Args[2].
+	SegmentCubeTo
+)
+
+// Point is a 2D point in font units.
+type Point struct {
+	X, Y float32
+}
+
+// Segment is a single path command of a glyph outline.
+type Segment struct {
+	Op   SegmentOp
+	Args [3]Point
+}
+
+// Outline is a glyph outline made of one or more contours. Each contour
+// begins with a SegmentMoveTo and is implicitly closed.
+type Outline []Segment
+
+// Flatten converts the outline into closed polygonal contours suitable for
+// Tessellator.AddContour with size 2. Curves are subdivided until the
+// distance between the curve and its chords is at most tolerance (in the
+// same units as the outline). Contours with fewer than three distinct
+// vertices are dropped.
+func (o Outline) Flatten(tolerance float32) [][]float32 {
+	if tolerance <= 0 {
+		tolerance = 0.5
+	}
+
+	var contours [][]float32
+	var cur []float32
+	var pen Point
+
+	flush := func() {
+		// Drop the explicit closing point, the contour is closed implicitly.
+		if n := len(cur); n >= 4 && cur[0] == cur[n-2] && cur[1] == cur[n-1] {
+			cur = cur[:n-2]
+		}
+		if len(cur) >= 6 {
+			contours = append(contours, cur)
+		}
+		cur = nil
+	}
+	lineTo := func(p Point) {
+		if n := len(cur); n >= 2 && cur[n-2] == p.X && cur[n-1] == p.Y {
+			return
+		}
+		cur = append(cur, p.X, p.Y)
+	}
+
+	for _, s := range o {
+		switch s.Op {
+		case SegmentMoveTo:
+			flush()
+			pen = s.Args[0]
+			cur = append(cur, pen.X, pen.Y)
+		case SegmentLineTo:
+			pen = s.Args[0]
+			lineTo(pen)
+		case SegmentQuadTo:
+			p0, p1, p2 := pen, s.Args[0], s.Args[1]
+			n := curveSteps(quadDeviation(p0, p1, p2), tolerance)
+			for i := 1; i <= n; i++ {
+				t := float32(i) / float32(n)
+				u := 1 - t
+				lineTo(Point{
+					X: u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+					Y: u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+				})
+			}
+			pen = p2
+		case SegmentCubeTo:
+			p0, p1, p2, p3 := pen, s.Args[0], s.Args[1], s.Args[2]
+			n := curveSteps(cubeDeviation(p0, p1, p2, p3), tolerance)
+			for i := 1; i <= n; i++ {
+				t := float32(i) / float32(n)
+				u := 1 - t
+				lineTo(Point{
+					X: u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X,
+					Y: u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y,
+				})
+			}
+			pen = p3
+		}
+	}
+	flush()
+
+	return contours
+}
+
+// quadDeviation returns the length of the second difference of a quadratic
+// curve, which bounds how far it strays from its chord.
+func quadDeviation(p0, p1, p2 Point) float32 {
+	dx := p0.X - 2*p1.X + p2.X
+	dy := p0.Y - 2*p1.Y + p2.Y
+	return float32(math.Hypot(float64(dx), float64(dy)))
+}
+
+// cubeDeviation returns the deviation bound of a cubic curve, scaled so that
+// it can be used with curveSteps like quadDeviation.
+func cubeDeviation(p0, p1, p2, p3 Point) float32 {
+	d1x, d1y := p0.X-2*p1.X+p2.X, p0.Y-2*p1.Y+p2.Y
+	d2x, d2y := p1.X-2*p2.X+p3.X, p1.Y-2*p2.Y+p3.Y
+	d1 := math.Hypot(float64(d1x), float64(d1y))
+	d2 := math.Hypot(float64(d2x), float64(d2y))
+	return 3 * float32(math.Max(d1, d2))
+}
+
+// curveSteps returns the number of uniform subdivisions needed so that the
+// chord error of a curve with the given deviation stays below tolerance.
+func curveSteps(deviation, tolerance float32) int {
+	// The chord error of n uniform steps is deviation / (4 n^2).
+	n := int(math.Ceil(math.Sqrt(float64(deviation / (4 * tolerance)))))
+	if n < 1 {
+		n = 1
+	}
+	if n > 100 {
+		n = 100
+	}
+	return n
+}
@@ -0,0 +1,320 @@
+package tess
+
+import (
+	"math"
+	"sort"
+)
+
+// TriangleIndex is a bounding volume hierarchy over tessellated triangles for
+// point location, rectangle queries and nearest-triangle search. Queries use
+// the x and y coordinates of the vertices. A TriangleIndex is immutable once
+// built and safe for concurrent use.
+type TriangleIndex struct {
+	vertices []float32
+	size     int
+	tris     []int // three vertex indices per triangle
+	elements []int // source polygon of each triangle
+	nodes    []bvhNode
+	order    []int // triangle numbers referenced by leaves
+}
+
+// Hit describes the triangle found by a point query.
+type Hit struct {
+	// Triangle is the triangle number within the index.
+	Triangle int
+	// Element is the polygon of the Result the triangle belongs to.
+	Element int
+	// Vertices are the output vertex indices of the triangle's corners.
+	Vertices [3]int
+	// Barycentric holds the weights of the three corners at the query point.
+	Barycentric [3]float32
+}
+
+type bbox struct{ minX, minY, maxX, maxY float32 }
+
+func emptyBox() bbox {
+	inf := float32(math.Inf(1))
+	return bbox{inf, inf, -inf, -inf}
+}
+
+func (b bbox) union(o bbox) bbox {
+	return bbox{min(b.minX, o.minX), min(b.minY, o.minY), max(b.maxX, o.maxX), max(b.maxY, o.maxY)}
+}
+
+func (b bbox) overlaps(o bbox) bool {
+	return b.minX <= o.maxX && o.minX <= b.maxX && b.minY <= o.maxY && o.minY <= b.maxY
+}
+
+// distance2 returns the squared distance from a point to the box.
+func (b bbox) distance2(x, y float32) float64 {
+	dx := math.Max(0, math.Max(float64(b.minX-x), float64(x-b.maxX)))
+	dy := math.Max(0, math.Max(float64(b.minY-y), float64(y-b.maxY)))
+	return dx*dx + dy*dy
+}
+
+// bvhNode is an inner node with children left and right when count is
+// zero, and a leaf holding order[start:start+count] otherwise.
+type bvhNode struct {
+	box          bbox
+	left, right  int
+	start, count int
+}
+
+const bvhLeafSize = 4
+
+// NewTriangleIndex builds an index over the polygons of an ElementPolygons
+// or ElementConnectedPolygons result.
+func NewTriangleIndex(r *Result) (*TriangleIndex, error) {
+	tris, elements, err := r.Triangles()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &TriangleIndex{
+		vertices: r.Vertices,
+		size:     r.VertexSize,
+		tris:     tris,
+		elements: elements,
+		order:    make([]int, len(tris)/3),
+	}
+	boxes := make([]bbox, len(idx.order))
+	for i := range idx.order {
+		idx.order[i] = i
+		boxes[i] = idx.triangleBox(i)
+	}
+	if len(idx.order) > 0 {
+		idx.build(boxes, 0, len(idx.order))
+	}
+	return idx, nil
+}
+
+// Len returns the number of triangles in the index.
+func (idx *TriangleIndex) Len() int {
+	return len(idx.tris) / 3
+}
+
+// Triangle returns the output vertex indices of triangle i.
+func (idx *TriangleIndex) Triangle(i int) [3]int {
+	return [3]int{idx.tris[3*i], idx.tris[3*i+1], idx.tris[3*i+2]}
+}
+
+func (idx *TriangleIndex) point(v int) (float32, float32) {
+	return idx.vertices[v*idx.size], idx.vertices[v*idx.size+1]
+}
+
+func (idx *TriangleIndex) corners(i int) (ax, ay, bx, by, cx, cy float32) {
+	ax, ay = idx.point(idx.tris[3*i])
+	bx, by = idx.point(idx.tris[3*i+1])
+	cx, cy = idx.point(idx.tris[3*i+2])
+	return
+}
+
+func (idx *TriangleIndex) triangleBox(i int) bbox {
+	ax, ay, bx, by, cx, cy := idx.corners(i)
+	return bbox{min(ax, bx, cx), min(ay, by, cy), max(ax, bx, cx), max(ay, by, cy)}
+}
+
+// build creates the subtree over order[start:end] and returns its node.
+func (idx *TriangleIndex) build(boxes []bbox, start, end int) int {
+	node := len(idx.nodes)
+	idx.nodes = append(idx.nodes, bvhNode{})
+
+	box := emptyBox()
+	for _, t := range idx.order[start:end] {
+		box = box.union(boxes[t])
+	}
+	if end-start <= bvhLeafSize {
+		idx.nodes[node] = bvhNode{box: box, start: start, count: end - start}
+		return node
+	}
+
+	// Split at the median centroid along the longer axis.
+	byX := box.maxX-box.minX >= box.maxY-box.minY
+	centre := func(t int) float32 {
+		if byX {
+			return boxes[t].minX + boxes[t].maxX
+		}
+		return boxes[t].minY + boxes[t].maxY
+	}
+	part := idx.order[start:end]
+	sort.Slice(part, func(i, j int) bool { return centre(part[i]) < centre(part[j]) })
+
+	mid := (start + end) / 2
+	left := idx.build(boxes, start, mid)
+	right := idx.build(boxes, mid, end)
+	idx.nodes[node] = bvhNode{box: box, left: left, right: right}
+	return node
+}
+
+// visit calls fn for every triangle whose bounding box passes test, stopping
+// early when fn returns false.
+func (idx *TriangleIndex) visit(test func(bbox) bool, fn func(t int) bool) {
+	if len(idx.nodes) == 0 {
+		return
+	}
+	stack := []int{0}
+	for len(stack) > 0 {
+		n := idx.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if !test(n.box) {
+			continue
+		}
+		if n.count == 0 {
+			stack = append(stack, n.left, n.right)
+			continue
+		}
+		for _, t := range idx.order[n.start : n.start+n.count] {
+			if !fn(t) {
+				return
+			}
+		}
+	}
+}
+
+// barycentric returns the barycentric coordinates of (x, y) in triangle i.
+// ok is false for degenerate triangles.
+func (idx *TriangleIndex) barycentric(i int, x, y float32) (b [3]float64, ok bool) {
+	ax, ay, bx, by, cx, cy := idx.corners(i)
+	v0x, v0y := float64(bx-ax), float64(by-ay)
+	v1x, v1y := float64(cx-ax), float64(cy-ay)
+	v2x, v2y := float64(x-ax), float64(y-ay)
+	d := v0x*v1y - v1x*v0y
+	if d == 0 {
+		return b, false
+	}
+	b[1] = (v2x*v1y - v1x*v2y) / d
+	b[2] = (v0x*v2y - v2x*v0y) / d
+	b[0] = 1 - b[1] - b[2]
+	return b, true
+}
+
+// Locate finds a triangle containing the point (x, y). Points on a shared
+// edge are reported in one of the adjacent triangles.
+func (idx *TriangleIndex) Locate(x, y float32) (Hit, bool) {
+	const eps = 1e-6
+	p := bbox{x, y, x, y}
+
+	var hit Hit
+	found := false
+	idx.visit(p.overlaps, func(t int) bool {
+		b, ok := idx.barycentric(t, x, y)
+		if !ok || b[0] < -eps || b[1] < -eps || b[2] < -eps {
+			return true
+		}
+		hit = Hit{
+			Triangle:    t,
+			Element:     idx.elements[t],
+			Vertices:    idx.Triangle(t),
+			Barycentric: [3]float32{float32(b[0]), float32(b[1]), float32(b[2])},
+		}
+		found = true
+		return false
+	})
+	return hit, found
+}
+
+// QueryRect returns the triangles that intersect the rectangle, in
+// ascending order.
+func (idx *TriangleIndex) QueryRect(minX, minY, maxX, maxY float32) []int {
+	rect := bbox{minX, minY, maxX, maxY}
+	var out []int
+	idx.visit(rect.overlaps, func(t int) bool {
+		if idx.intersectsRect(t, rect) {
+			out = append(out, t)
+		}
+		return true
+	})
+	sort.Ints(out)
+	return out
+}
+
+// intersectsRect tests triangle t against a rectangle with the separating
+// axis theorem. The box axes are covered by the bounding box test, so only
+// the triangle edge normals remain.
+func (idx *TriangleIndex) intersectsRect(t int, rect bbox) bool {
+	if !idx.triangleBox(t).overlaps(rect) {
+		return false
+	}
+	ax, ay, bx, by, cx, cy := idx.corners(t)
+	pts := [3][2]float64{{float64(ax), float64(ay)}, {float64(bx), float64(by)}, {float64(cx), float64(cy)}}
+	corners := [4][2]float64{
+		{float64(rect.minX), float64(rect.minY)}, {float64(rect.maxX), float64(rect.minY)},
+		{float64(rect.maxX), float64(rect.maxY)}, {float64(rect.minX), float64(rect.maxY)},
+	}
+	for k := 0; k < 3; k++ {
+		p, q, o := pts[k], pts[(k+1)%3], pts[(k+2)%3]
+		nx, ny := q[1]-p[1], p[0]-q[0]
+		side := nx*(o[0]-p[0]) + ny*(o[1]-p[1])
+		separated := true
+		for _, c := range corners {
+			if s := nx*(c[0]-p[0]) + ny*(c[1]-p[1]); s*side >= 0 {
+				separated = false
+				break
+			}
+		}
+		if separated && side != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Nearest returns the triangle closest to the point (x, y) and its distance,
+// which is zero for points inside a triangle. ok is false for an empty index.
+func (idx *TriangleIndex) Nearest(x, y float32) (triangle int, distance float32, ok bool) {
+	if len(idx.nodes) == 0 {
+		return -1, 0, false
+	}
+
+	best, bestD2 := -1, math.Inf(1)
+	// Best-first traversal ordered by the distance to each node's box.
+	type entry struct {
+		node int
+		d2   float64
+	}
+	queue := []entry{{0, idx.nodes[0].box.distance2(x, y)}}
+	for len(queue) > 0 {
+		k := 0
+		for i := range queue {
+			if queue[i].d2 < queue[k].d2 {
+				k = i
+			}
+		}
+		e := queue[k]
+		queue[k] = queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if e.d2 >= bestD2 {
+			continue
+		}
+
+		n := idx.nodes[e.node]
+		if n.count == 0 {
+			for _, c := range []int{n.left, n.right} {
+				if d2 := idx.nodes[c].box.distance2(x, y); d2 < bestD2 {
+					queue = append(queue, entry{c, d2})
+				}
+			}
+			continue
+		}
+		for _, t := range idx.order[n.start : n.start+n.count] {
+			if d2 := idx.distance2(t, x, y); d2 < bestD2 {
+				best, bestD2 = t, d2
+			}
+		}
+	}
+	return best, float32(math.Sqrt(bestD2)), true
+}
+
+// distance2 returns the squared distance from (x, y) to triangle t.
+func (idx *TriangleIndex) distance2(t int, x, y float32) float64 {
+	if b, ok := idx.barycentric(t, x, y); ok && b[0] >= 0 && b[1] >= 0 && b[2] >= 0 {
+		return 0
+	}
+	ax, ay, bx, by, cx, cy := idx.corners(t)
+	p := [3]float64{float64(x), float64(y), 0}
+	a := [3]float64{float64(ax), float64(ay), 0}
+	b := [3]float64{float64(bx), float64(by), 0}
+	c := [3]float64{float64(cx), float64(cy), 0}
+	d := math.Min(segmentDistance(p, a, b), math.Min(segmentDistance(p, b, c), segmentDistance(p, c, a)))
+	return d * d
+}
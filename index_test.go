@@ -0,0 +1,216 @@
+package tess
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// gridResult tessellates a square with a square hole.
+func gridResult(t *testing.T, polySize int) *Result {
+	t.Helper()
+	tess := NewTessellator()
+	defer tess.Delete()
+
+	if err := tess.AddContour(2, []float32{0, 0, 10, 0, 10, 10, 0, 10}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	if err := tess.AddContour(2, []float32{4, 4, 6, 4, 6, 6, 4, 6}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, polySize, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	return r
+}
+
+// TestTessellateResult tests the fields of a full result
+func TestTessellateResult(t *testing.T) {
+	r := gridResult(t, 3)
+	if r.VertexCount() != 8 || len(r.VertexIndices) != 8 {
+		t.Fatalf("Expected 8 vertices with indices, got %d and %d", r.VertexCount(), len(r.VertexIndices))
+	}
+	for i, src := range r.VertexIndices {
+		if src < 0 || src >= 8 {
+			t.Errorf("Vertex %d has source %d", i, src)
+		}
+	}
+
+	tris, elements, err := r.Triangles()
+	if err != nil {
+		t.Fatalf("Triangles failed: %v", err)
+	}
+	if len(tris) != len(r.Indices) || len(elements) != len(tris)/3 {
+		t.Errorf("Expected triangles to match indices, got %d and %d", len(tris), len(r.Indices))
+	}
+
+	// Polygons with more vertices are split as fans covering the same area.
+	quads := gridResult(t, 6)
+	tris, elements, err = quads.Triangles()
+	if err != nil {
+		t.Fatalf("Triangles failed: %v", err)
+	}
+	if area := triangleArea(quads.Vertices, tris); math.Abs(area-96) > 1e-4 {
+		t.Errorf("Expected area 96, got %v", area)
+	}
+	if elements[len(elements)-1] != len(quads.Indices)/6-1 {
+		t.Errorf("Expected the last triangle to come from the last polygon")
+	}
+
+	contours := &Result{ElementType: ElementBoundaryContours}
+	if _, _, err := contours.Triangles(); err == nil {
+		t.Error("Expected error for boundary contours")
+	}
+}
+
+// TestTriangleIndexLocate tests point location against a brute force search
+func TestTriangleIndexLocate(t *testing.T) {
+	for _, polySize := range []int{3, 5} {
+		r := gridResult(t, polySize)
+		idx, err := NewTriangleIndex(r)
+		if err != nil {
+			t.Fatalf("NewTriangleIndex failed: %v", err)
+		}
+
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 1000; i++ {
+			x, y := rng.Float32()*12-1, rng.Float32()*12-1
+			inside := x > 0 && x < 10 && y > 0 && y < 10 && !(x > 4 && x < 6 && y > 4 && y < 6)
+			onEdge := false
+			for _, v := range []float32{0, 4, 6, 10} {
+				onEdge = onEdge || math.Abs(float64(x-v)) < 1e-4 || math.Abs(float64(y-v)) < 1e-4
+			}
+			if onEdge {
+				continue
+			}
+
+			hit, ok := idx.Locate(x, y)
+			if ok != inside {
+				t.Fatalf("Point (%v, %v): expected inside=%v, got %v", x, y, inside, ok)
+			}
+			if !ok {
+				continue
+			}
+
+			// The barycentric weights reproduce the query point.
+			var px, py float32
+			for k, v := range hit.Vertices {
+				px += hit.Barycentric[k] * r.Vertices[2*v]
+				py += hit.Barycentric[k] * r.Vertices[2*v+1]
+			}
+			if math.Abs(float64(px-x)) > 1e-4 || math.Abs(float64(py-y)) > 1e-4 {
+				t.Errorf("Barycentric %v of (%v, %v) gives (%v, %v)", hit.Barycentric, x, y, px, py)
+			}
+			if hit.Vertices != idx.Triangle(hit.Triangle) {
+				t.Errorf("Hit vertices %v do not match triangle %d", hit.Vertices, hit.Triangle)
+			}
+		}
+	}
+}
+
+// TestTriangleIndexDeep tests queries on a tree several levels deep against brute force
+func TestTriangleIndexDeep(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.AddContour(2, noisyCircle(500, 10, 0.5)); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	idx, err := NewTriangleIndex(r)
+	if err != nil {
+		t.Fatalf("NewTriangleIndex failed: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		x, y := rng.Float32()*24-12, rng.Float32()*24-12
+		inside := false
+		for tri := 0; tri < idx.Len(); tri++ {
+			if b, ok := idx.barycentric(tri, x, y); ok && b[0] >= 0 && b[1] >= 0 && b[2] >= 0 {
+				inside = true
+				break
+			}
+		}
+		if _, ok := idx.Locate(x, y); ok != inside {
+			t.Errorf("Point (%v, %v): expected inside=%v, got %v", x, y, inside, ok)
+		}
+		if got := idx.QueryRect(x, y, x+1, y+1); len(got) == 0 && inside {
+			t.Errorf("Point (%v, %v): expected triangles around an inside point", x, y)
+		}
+	}
+}
+
+// TestTriangleIndexQueries tests rectangle and nearest queries
+func TestTriangleIndexQueries(t *testing.T) {
+	r := gridResult(t, 3)
+	idx, err := NewTriangleIndex(r)
+	if err != nil {
+		t.Fatalf("NewTriangleIndex failed: %v", err)
+	}
+
+	if got := idx.QueryRect(4.5, 4.5, 5.5, 5.5); len(got) != 0 {
+		t.Errorf("Expected no triangles inside the hole, got %v", got)
+	}
+	if got := idx.QueryRect(-5, -5, 20, 20); len(got) != idx.Len() {
+		t.Errorf("Expected all %d triangles, got %d", idx.Len(), len(got))
+	}
+	got := idx.QueryRect(0.1, 0.1, 0.2, 0.2)
+	if len(got) == 0 {
+		t.Fatal("Expected a triangle at the corner")
+	}
+	for _, tri := range got {
+		if !idx.triangleBox(tri).overlaps(bbox{0.1, 0.1, 0.2, 0.2}) {
+			t.Errorf("Triangle %d does not overlap the query", tri)
+		}
+	}
+
+	if tri, d, ok := idx.Nearest(5, 5); !ok || math.Abs(float64(d)-1) > 1e-5 {
+		t.Errorf("Expected distance 1 from the hole centre, got triangle %d at %v", tri, d)
+	}
+	if _, d, ok := idx.Nearest(2, 2); !ok || d != 0 {
+		t.Errorf("Expected distance 0 inside, got %v", d)
+	}
+	if _, d, ok := idx.Nearest(13, 14); !ok || math.Abs(float64(d)-5) > 1e-5 {
+		t.Errorf("Expected distance 5 outside the corner, got %v", d)
+	}
+
+	empty, err := NewTriangleIndex(&Result{ElementType: ElementPolygons, PolySize: 3, VertexSize: 2})
+	if err != nil {
+		t.Fatalf("NewTriangleIndex failed: %v", err)
+	}
+	if _, ok := empty.Locate(0, 0); ok {
+		t.Error("Expected no hit in an empty index")
+	}
+	if _, _, ok := empty.Nearest(0, 0); ok {
+		t.Error("Expected no nearest triangle in an empty index")
+	}
+}
+
+// TestTriangleIndexConcurrent tests concurrent queries
+func TestTriangleIndexConcurrent(t *testing.T) {
+	idx, err := NewTriangleIndex(gridResult(t, 3))
+	if err != nil {
+		t.Fatalf("NewTriangleIndex failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < 500; i++ {
+				x, y := rng.Float32()*10, rng.Float32()*10
+				idx.Locate(x, y)
+				idx.Nearest(x, y)
+				idx.QueryRect(x, y, x+1, y+1)
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+}
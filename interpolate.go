@@ -0,0 +1,134 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+)
+
+// Interpolate returns n attribute values per output vertex of r, such as
+// colours, texture coordinates or elevations, given n values per input
+// vertex. Input vertices are numbered over all contours in the order they
+// were added, as in VertexIndices, and the contours must have r.VertexSize
+// coordinates per vertex, as for Verify.
+//
+// Output vertices that come from an input vertex copy its attributes.
+// Vertices created at intersections, reported as Undef, are interpolated
+// from the input edges passing through them the way GLU's combine callback
+// is weighted: each of the k edges contributes 1/k, split between its two
+// endpoints by the vertex's position along the edge. If no edge passes
+// through such a vertex, which can happen when SetSimplify moved the
+// outline, the nearest edges are used.
+func (r *Result) Interpolate(contours [][]float32, attributes []float32, n int) ([]float32, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("attribute count must be positive, got %d", n)
+	}
+	rings, err := planarContours(contours, r)
+	if err != nil {
+		return nil, err
+	}
+	inputs := 0
+	for _, ring := range rings {
+		inputs += len(ring)
+	}
+	if len(attributes) != inputs*n {
+		return nil, fmt.Errorf("expected %d attribute values for %d input vertices, got %d", inputs*n, inputs, len(attributes))
+	}
+	count := r.VertexCount()
+	if len(r.VertexIndices) != count {
+		return nil, fmt.Errorf("result has %d vertex indices for %d vertices", len(r.VertexIndices), count)
+	}
+
+	out := make([]float32, count*n)
+	var edges *edgeWeights
+	for v, src := range r.VertexIndices {
+		dst := out[v*n : (v+1)*n]
+		if src != Undef {
+			if src < 0 || src >= inputs {
+				return nil, fmt.Errorf("vertex %d references input vertex %d out of %d", v, src, inputs)
+			}
+			copy(dst, attributes[src*n:(src+1)*n])
+			continue
+		}
+
+		if edges == nil {
+			edges = newEdgeWeights(rings)
+		}
+		x, y := r.project(r.Vertices[v*r.VertexSize : (v+1)*r.VertexSize])
+		acc := make([]float64, n)
+		edges.combine(vec2{x, y}, func(i int, w float64) {
+			for k := range acc {
+				acc[k] += w * float64(attributes[i*n+k])
+			}
+		})
+		for k := range acc {
+			dst[k] = float32(acc[k])
+		}
+	}
+	return out, nil
+}
+
+// edgeWeights finds the input edges through a point and weights their
+// endpoints.
+type edgeWeights struct {
+	rings [][]vec2
+	base  []int // number of the first vertex of each ring
+	eps   float64
+}
+
+func newEdgeWeights(rings [][]vec2) *edgeWeights {
+	e := &edgeWeights{rings: rings, base: make([]int, len(rings))}
+	box := bounds64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	next := 0
+	for i, ring := range rings {
+		e.base[i] = next
+		next += len(ring)
+		for _, p := range ring {
+			box.minX, box.minY = math.Min(box.minX, p.x), math.Min(box.minY, p.y)
+			box.maxX, box.maxY = math.Max(box.maxX, p.x), math.Max(box.maxY, p.y)
+		}
+	}
+	e.eps = 1e-5 * math.Max(math.Hypot(box.maxX-box.minX, box.maxY-box.minY), 1e-12)
+	return e
+}
+
+// combine calls fn with the input vertices and weights that interpolate
+// point p from the edges within eps of the nearest edge.
+func (e *edgeWeights) combine(p vec2, fn func(vertex int, weight float64)) {
+	type hit struct {
+		a, b int
+		t, d float64
+	}
+	var hits []hit
+	nearest := math.Inf(1)
+	for r, ring := range e.rings {
+		for i := range ring {
+			j := (i + 1) % len(ring)
+			a, b := ring[i], ring[j]
+			ab := b.sub(a)
+			l2 := ab.dot(ab)
+			if l2 == 0 {
+				continue
+			}
+			t := math.Max(0, math.Min(1, p.sub(a).dot(ab)/l2))
+			d := p.sub(a.add(ab.scale(t))).length()
+			if d > nearest+e.eps {
+				continue
+			}
+			nearest = math.Min(nearest, d)
+			hits = append(hits, hit{e.base[r] + i, e.base[r] + j, t, d})
+		}
+	}
+
+	k := 0
+	for _, h := range hits {
+		if h.d <= nearest+e.eps {
+			k++
+		}
+	}
+	for _, h := range hits {
+		if h.d <= nearest+e.eps {
+			fn(h.a, (1-h.t)/float64(k))
+			fn(h.b, h.t/float64(k))
+		}
+	}
+}
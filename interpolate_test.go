@@ -0,0 +1,161 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// linearAttributes evaluates two linear functions of the first size
+// coordinates of every vertex.
+func linearAttributes(size int, contours [][]float32) []float32 {
+	var attrs []float32
+	for _, c := range contours {
+		for k := 0; k+size <= len(c); k += size {
+			x, y := c[k], c[k+1]
+			attrs = append(attrs, 2*x+3*y+1, x-y)
+		}
+	}
+	return attrs
+}
+
+// TestInterpolate tests that linear attributes are reproduced at intersections
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		name     string
+		contours [][]float32
+		minUndef int
+	}{
+		{"overlapping squares", [][]float32{{0, 0, 4, 0, 4, 4, 0, 4}, {2, 1, 6, 1, 6, 5, 2, 5}}, 2},
+		{"star", [][]float32{{0, 3, 6, 3, 1, 0, 3, 5, 5, 0}}, 5},
+		{"three lines through a point", [][]float32{{-2, -1, 2, 1, 2, 2}, {-2, 1, 2, -1, 2, -2}, {0, -3, 0, 3, 1, 3}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tessellateContours(t, 2, tt.contours, WindingNonZero)
+			attrs, err := r.Interpolate(tt.contours, linearAttributes(2, tt.contours), 2)
+			if err != nil {
+				t.Fatalf("Interpolate failed: %v", err)
+			}
+			if len(attrs) != 2*r.VertexCount() {
+				t.Fatalf("Expected %d values, got %d", 2*r.VertexCount(), len(attrs))
+			}
+
+			undef := 0
+			for v := 0; v < r.VertexCount(); v++ {
+				if r.VertexIndices[v] == Undef {
+					undef++
+				}
+				x, y := r.Vertices[2*v], r.Vertices[2*v+1]
+				if got, want := attrs[2*v], 2*x+3*y+1; math.Abs(float64(got-want)) > 1e-4 {
+					t.Errorf("Vertex %d (%v, %v): expected %v, got %v", v, x, y, want, got)
+				}
+				if got, want := attrs[2*v+1], x-y; math.Abs(float64(got-want)) > 1e-4 {
+					t.Errorf("Vertex %d (%v, %v): expected %v, got %v", v, x, y, want, got)
+				}
+			}
+			if undef < tt.minUndef {
+				t.Errorf("Expected at least %d intersection vertices, got %d", tt.minUndef, undef)
+			}
+		})
+	}
+}
+
+// TestInterpolateWeights tests the combine weights of an intersection vertex
+func TestInterpolateWeights(t *testing.T) {
+	// Two edges crossing at (1, 1).
+	contours := [][]float32{{0, 0, 2, 2, 0, 2}, {0, 4.0 / 3, 4, 0, 4, 4}}
+	r := tessellateContours(t, 2, contours, WindingOdd)
+	attrs, err := r.Interpolate(contours, []float32{1, 0, 0, 10, 0, 0}, 1)
+	if err != nil {
+		t.Fatalf("Interpolate failed: %v", err)
+	}
+	found := false
+	for v, src := range r.VertexIndices {
+		if src != Undef || math.Abs(float64(r.Vertices[2*v]-1)) > 1e-5 || math.Abs(float64(r.Vertices[2*v+1]-1)) > 1e-5 {
+			continue
+		}
+		found = true
+		// Each edge contributes half: vertex 0 is halfway along the first
+		// edge and vertex 3 a quarter of the way along the second.
+		if got, want := attrs[v], 0.5*0.5*1+0.5*0.75*10; math.Abs(float64(got)-want) > 1e-5 {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+	if !found {
+		t.Fatal("Expected an intersection vertex at x=1")
+	}
+}
+
+// TestInterpolate3D tests interpolation for a tilted plane and simplified input
+func TestInterpolate3D(t *testing.T) {
+	// Two overlapping squares in the plane z = y.
+	contours := [][]float32{
+		{0, 0, 0, 4, 0, 0, 4, 4, 4, 0, 4, 4},
+		{2, 1, 1, 6, 1, 1, 6, 5, 5, 2, 5, 5},
+	}
+	r := tessellateContours(t, 3, contours, WindingNonZero)
+	attrs, err := r.Interpolate(contours, linearAttributes(3, contours), 2)
+	if err != nil {
+		t.Fatalf("Interpolate failed: %v", err)
+	}
+	for v := 0; v < r.VertexCount(); v++ {
+		x, y := r.Vertices[3*v], r.Vertices[3*v+1]
+		if got, want := attrs[2*v], 2*x+3*y+1; math.Abs(float64(got-want)) > 1e-4 {
+			t.Errorf("Vertex %d (%v, %v): expected %v, got %v", v, x, y, want, got)
+		}
+	}
+
+	// A simplified contour no longer passes through the intersection, so
+	// the nearest edges are used.
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.SetSimplify(SimplifyDouglasPeucker, 0.2); err != nil {
+		t.Fatalf("SetSimplify failed: %v", err)
+	}
+	flat := [][]float32{{0, 0, 2, 0.1, 4, 0, 4, 4, 0, 4}, {2, -1, 3, -1, 3, 5, 2, 5}}
+	for _, c := range flat {
+		if err := tess.AddContour(2, c); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+	}
+	sr, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	attrs, err = sr.Interpolate(flat, linearAttributes(2, flat), 2)
+	if err != nil {
+		t.Fatalf("Interpolate failed: %v", err)
+	}
+	for v := 0; v < sr.VertexCount(); v++ {
+		x, y := sr.Vertices[2*v], sr.Vertices[2*v+1]
+		if got, want := attrs[2*v], 2*x+3*y+1; math.Abs(float64(got-want)) > 0.5 {
+			t.Errorf("Vertex %d (%v, %v): expected about %v, got %v", v, x, y, want, got)
+		}
+	}
+}
+
+// TestInterpolateErrors tests invalid arguments
+func TestInterpolateErrors(t *testing.T) {
+	contours := [][]float32{{0, 0, 4, 0, 4, 4}}
+	r := tessellateContours(t, 2, contours, WindingOdd)
+
+	if _, err := r.Interpolate(contours, []float32{1, 2, 3}, 0); err == nil {
+		t.Error("Expected error for zero attributes per vertex")
+	}
+	if _, err := r.Interpolate(contours, []float32{1, 2}, 1); err == nil {
+		t.Error("Expected error for too few attributes")
+	}
+	if _, err := r.Interpolate([][]float32{{0, 0, 1}}, []float32{1, 2, 3}, 1); err == nil {
+		t.Error("Expected error for contours of the wrong size")
+	}
+	bad := *r
+	bad.VertexIndices = []int{0, 1, 7}
+	if _, err := bad.Interpolate(contours, []float32{1, 2, 3}, 1); err == nil {
+		t.Error("Expected error for out of range vertex index")
+	}
+	bad.VertexIndices = nil
+	if _, err := bad.Interpolate(contours, []float32{1, 2, 3}, 1); err == nil {
+		t.Error("Expected error for missing vertex indices")
+	}
+}
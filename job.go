@@ -0,0 +1,53 @@
+package tess
+
+import "fmt"
+
+// Job describes one tessellation: the input contours together with every
+// parameter passed to SetOption and Tessellate.
+type Job struct {
+	// Size is the number of coordinates per input vertex, 2 or 3.
+	Size     int         `json:"size"`
+	Contours [][]float32 `json:"contours"`
+
+	WindingRule WindingRule `json:"windingRule"`
+	ElementType ElementType `json:"elementType"`
+	PolySize    int         `json:"polySize"`
+	VertexSize  int         `json:"vertexSize"`
+	// Normal is the projection normal, or nil to compute it.
+	Normal []float32 `json:"normal"`
+	// Options lists the enabled options. All others are disabled.
+	Options []Option `json:"options"`
+}
+
+// enabled reports whether the job enables option o.
+func (j *Job) enabled(o Option) bool {
+	for _, opt := range j.Options {
+		if opt == o {
+			return true
+		}
+	}
+	return false
+}
+
+// Run sets the job's options on t, adds its contours and tessellates them.
+// Settings that are not part of the job, such as SetSimplify, are left as
+// they are.
+func (j *Job) Run(t *Tessellator) (*Result, error) {
+	if t == nil || t.tess == nil {
+		return nil, fmt.Errorf("tessellator is nil or deleted")
+	}
+	for _, o := range options {
+		if err := t.SetOption(o, j.enabled(o)); err != nil {
+			return nil, err
+		}
+	}
+	if j.Normal == nil {
+		t.clearNormal()
+	}
+	for i, c := range j.Contours {
+		if err := t.AddContour(j.Size, c); err != nil {
+			return nil, fmt.Errorf("contour %d: %w", i, err)
+		}
+	}
+	return t.TessellateResult(j.WindingRule, j.ElementType, j.PolySize, j.VertexSize, j.Normal)
+}
@@ -0,0 +1,108 @@
+package tess
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WindingRule, ElementType, Option and Status are encoded in JSON as the
+// names returned by their String methods, which are matched
+// case-insensitively when decoding. Values without a name cannot be
+// encoded.
+
+var (
+	windingRules = []WindingRule{WindingOdd, WindingNonZero, WindingPositive, WindingNegative, WindingAbsGeqTwo}
+	elementTypes = []ElementType{ElementPolygons, ElementConnectedPolygons, ElementBoundaryContours}
+	statuses     = []Status{StatusOK, StatusOutOfMemory, StatusInvalidInput}
+)
+
+// String returns a string representation of the option.
+func (o Option) String() string {
+	switch o {
+	case OptionConstrainedDelaunay:
+		return "ConstrainedDelaunay"
+	case OptionReverseContours:
+		return "ReverseContours"
+	case OptionEdgeFlags:
+		return "EdgeFlags"
+	case OptionContourAttribution:
+		return "ContourAttribution"
+	case OptionCanonicalOrder:
+		return "CanonicalOrder"
+	default:
+		return "Unknown"
+	}
+}
+
+// MarshalJSON encodes the winding rule as its name.
+func (w WindingRule) MarshalJSON() ([]byte, error) {
+	return marshalName(w, windingRules, "winding rule")
+}
+
+// UnmarshalJSON decodes a winding rule from its name.
+func (w *WindingRule) UnmarshalJSON(data []byte) error {
+	return unmarshalName(data, w, windingRules, "winding rule")
+}
+
+// MarshalJSON encodes the element type as its name.
+func (e ElementType) MarshalJSON() ([]byte, error) {
+	return marshalName(e, elementTypes, "element type")
+}
+
+// UnmarshalJSON decodes an element type from its name.
+func (e *ElementType) UnmarshalJSON(data []byte) error {
+	return unmarshalName(data, e, elementTypes, "element type")
+}
+
+// MarshalJSON encodes the option as its name.
+func (o Option) MarshalJSON() ([]byte, error) {
+	return marshalName(o, options, "option")
+}
+
+// UnmarshalJSON decodes an option from its name.
+func (o *Option) UnmarshalJSON(data []byte) error {
+	return unmarshalName(data, o, options, "option")
+}
+
+// MarshalJSON encodes the status as its name.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return marshalName(s, statuses, "status")
+}
+
+// UnmarshalJSON decodes a status from its name.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	return unmarshalName(data, s, statuses, "status")
+}
+
+// named is implemented by the enumerations encoded by name.
+type named interface {
+	~int
+	String() string
+}
+
+func marshalName[T named](v T, values []T, kind string) ([]byte, error) {
+	for _, known := range values {
+		if v == known {
+			return json.Marshal(v.String())
+		}
+	}
+	return nil, fmt.Errorf("unknown %s %d", kind, int(v))
+}
+
+func unmarshalName[T named](data []byte, v *T, values []T, kind string) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("%s must be a string: %w", kind, err)
+	}
+	for _, known := range values {
+		if strings.EqualFold(name, known.String()) {
+			*v = known
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s %q", kind, name)
+}
@@ -0,0 +1,123 @@
+package tess
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestEnumJSON tests that enumerations are encoded by name
+func TestEnumJSON(t *testing.T) {
+	type enums struct {
+		WindingRule WindingRule
+		ElementType ElementType
+		Options     []Option
+		Status      Status
+	}
+	want := enums{WindingAbsGeqTwo, ElementConnectedPolygons, options, StatusOutOfMemory}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	const doc = `{"WindingRule":"AbsGeqTwo","ElementType":"ConnectedPolygons",` +
+		`"Options":["ConstrainedDelaunay","ReverseContours","EdgeFlags","ContourAttribution","CanonicalOrder"],` +
+		`"Status":"OutOfMemory"}`
+	if string(data) != doc {
+		t.Errorf("Expected %s, got %s", doc, data)
+	}
+	var got enums
+	if err := json.Unmarshal([]byte(strings.ToLower(doc)), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	for _, v := range []any{WindingRule(42), ElementType(42), Option(42), Status(42)} {
+		if _, err := json.Marshal(v); err == nil {
+			t.Errorf("Expected error marshalling %T(42)", v)
+		}
+	}
+	for _, doc := range []string{`{"WindingRule":"Even"}`, `{"ElementType":1}`, `{"Options":["Fast"]}`, `{"Status":"Done"}`} {
+		if err := json.Unmarshal([]byte(doc), &got); err == nil {
+			t.Errorf("Expected error unmarshalling %s", doc)
+		}
+	}
+	// null leaves the value unchanged, as for other types.
+	got = enums{Status: StatusInvalidInput}
+	if err := json.Unmarshal([]byte(`{"Status":null}`), &got); err != nil || got.Status != StatusInvalidInput {
+		t.Errorf("Expected null to be ignored, got %v (%v)", got.Status, err)
+	}
+	if s := Option(42).String(); s != "Unknown" {
+		t.Errorf("Expected Unknown, got %s", s)
+	}
+}
+
+// TestJobJSON tests that a Job survives a round trip
+func TestJobJSON(t *testing.T) {
+	job := Job{
+		Size:        2,
+		Contours:    canonicalContours(),
+		WindingRule: WindingNonZero,
+		ElementType: ElementConnectedPolygons,
+		PolySize:    4,
+		VertexSize:  3,
+		Normal:      []float32{0, 0, 1},
+		Options:     []Option{OptionEdgeFlags, OptionContourAttribution},
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got Job
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, job) {
+		t.Errorf("Expected %+v, got %+v", job, got)
+	}
+
+	// A nil normal, which computes the normal, stays nil.
+	data, _ = json.Marshal(Job{})
+	got = Job{Normal: []float32{1}}
+	if err := json.Unmarshal(data, &got); err != nil || got.Normal != nil {
+		t.Errorf("Expected a nil normal from %s, got %v (%v)", data, got.Normal, err)
+	}
+}
+
+// TestResultJSON tests that a Result survives a round trip
+func TestResultJSON(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	job := Job{
+		Size:        2,
+		Contours:    canonicalContours(),
+		WindingRule: WindingPositive,
+		ElementType: ElementConnectedPolygons,
+		PolySize:    5,
+		VertexSize:  3,
+		Options:     []Option{OptionEdgeFlags, OptionContourAttribution},
+	}
+	plain := job
+	plain.Options = nil
+	plain.ElementType = ElementBoundaryContours
+
+	for _, j := range []Job{job, plain} {
+		want, err := j.Run(tess)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		data, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var got *Result
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %+v, got %+v", j.ElementType, want, got)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+)
+
+// MassProperties describes the geometry of a tessellated region of uniform
+// surface density.
+type MassProperties struct {
+	// Area is the total unsigned area of the triangles.
+	Area float64
+	// SignedArea is the area measured along Normal: positive for triangles
+	// wound counter-clockwise when seen from the tip of Normal.
+	SignedArea float64
+	// Normal is the unit normal used for SignedArea.
+	Normal [3]float64
+	// Centroid is the area-weighted centre of the region.
+	Centroid [3]float64
+	// Perimeter is the total length of the region's boundary, i.e. of the
+	// triangle edges that are not shared by two triangles.
+	Perimeter float64
+	// SecondMoment is the second moment of area about the centroid,
+	// the integral of r·rᵀ over the region.
+	SecondMoment [3][3]float64
+	// Inertia is the inertia tensor about the centroid for unit surface
+	// density. For a 2D region Inertia[0][0] and Inertia[1][1] are the
+	// second moments about the x and y axes, Inertia[0][1] is minus the
+	// product moment and Inertia[2][2] is the polar moment.
+	Inertia [3][3]float64
+}
+
+// ComputeMassProperties computes the mass properties of triangles as
+// returned by Tessellate with ElementPolygons and polySize 3. vertexSize is 2
+// or 3; 3D triangles should lie in a common plane. normal selects the
+// direction SignedArea is measured along; if nil, +Z is used for 2D input
+// and the direction of the summed triangle normals for 3D input.
+func ComputeMassProperties(vertices []float32, indices []int, vertexSize int, normal []float32) (*MassProperties, error) {
+	if vertexSize != 2 && vertexSize != 3 {
+		return nil, fmt.Errorf("vertexSize must be 2 or 3, got %d", vertexSize)
+	}
+	if len(indices)%3 != 0 {
+		return nil, fmt.Errorf("len(indices)(%d) must be a multiple of 3", len(indices))
+	}
+	if normal != nil && len(normal) < 3 {
+		return nil, fmt.Errorf("normal vector must have at least 3 components, got %d", len(normal))
+	}
+
+	n := len(vertices) / vertexSize
+	for _, i := range indices {
+		if i < 0 || i >= n {
+			return nil, fmt.Errorf("index %d out of range for %d vertices", i, n)
+		}
+	}
+	pos := func(i int) [3]float64 {
+		var p [3]float64
+		for k := 0; k < vertexSize; k++ {
+			p[k] = float64(vertices[i*vertexSize+k])
+		}
+		return p
+	}
+
+	m := &MassProperties{}
+	if len(indices) == 0 {
+		m.Normal = [3]float64{0, 0, 1}
+		return m, nil
+	}
+
+	// Integrate relative to the first vertex to limit cancellation.
+	origin := pos(indices[0])
+	var vectorArea, first [3]float64
+	var second [3][3]float64
+	for t := 0; t < len(indices); t += 3 {
+		a := sub3(pos(indices[t]), origin)
+		b := sub3(pos(indices[t+1]), origin)
+		c := sub3(pos(indices[t+2]), origin)
+		cr := cross3(sub3(b, a), sub3(c, a))
+		area := math.Sqrt(dot3(cr, cr)) / 2
+		for k := 0; k < 3; k++ {
+			vectorArea[k] += cr[k] / 2
+		}
+		m.Area += area
+
+		sum := [3]float64{a[0] + b[0] + c[0], a[1] + b[1] + c[1], a[2] + b[2] + c[2]}
+		for i := 0; i < 3; i++ {
+			first[i] += area * sum[i] / 3
+			for j := 0; j < 3; j++ {
+				second[i][j] += area / 12 * (a[i]*a[j] + b[i]*b[j] + c[i]*c[j] + sum[i]*sum[j])
+			}
+		}
+	}
+
+	switch {
+	case normal != nil:
+		m.Normal = [3]float64{float64(normal[0]), float64(normal[1]), float64(normal[2])}
+	case vertexSize == 2:
+		m.Normal = [3]float64{0, 0, 1}
+	default:
+		m.Normal = vectorArea
+	}
+	if l := math.Sqrt(dot3(m.Normal, m.Normal)); l > 0 {
+		for k := range m.Normal {
+			m.Normal[k] /= l
+		}
+	} else {
+		m.Normal = [3]float64{0, 0, 1}
+	}
+	m.SignedArea = dot3(vectorArea, m.Normal)
+
+	if m.Area > 0 {
+		var c [3]float64
+		for i := 0; i < 3; i++ {
+			c[i] = first[i] / m.Area
+			m.Centroid[i] = origin[i] + c[i]
+		}
+		// Parallel axis theorem: move the second moment to the centroid.
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				m.SecondMoment[i][j] = second[i][j] - m.Area*c[i]*c[j]
+			}
+		}
+	}
+	trace := m.SecondMoment[0][0] + m.SecondMoment[1][1] + m.SecondMoment[2][2]
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m.Inertia[i][j] = -m.SecondMoment[i][j]
+		}
+		m.Inertia[i][i] += trace
+	}
+
+	m.Perimeter = boundaryLength(indices, pos)
+	return m, nil
+}
+
+// boundaryLength sums the lengths of triangle edges used by only one
+// triangle.
+func boundaryLength(indices []int, pos func(int) [3]float64) float64 {
+	uses := make(map[[2]int]int, len(indices))
+	for t := 0; t < len(indices); t += 3 {
+		for k := 0; k < 3; k++ {
+			a, b := indices[t+k], indices[t+(k+1)%3]
+			if a > b {
+				a, b = b, a
+			}
+			uses[[2]int{a, b}]++
+		}
+	}
+	length := 0.0
+	for e, n := range uses {
+		if n == 1 {
+			d := sub3(pos(e[1]), pos(e[0]))
+			length += math.Sqrt(dot3(d, d))
+		}
+	}
+	return length
+}
+
+// MassProperties computes the mass properties of a polygon result. See
+// ComputeMassProperties.
+func (r *Result) MassProperties(normal []float32) (*MassProperties, error) {
+	tris, _, err := r.Triangles()
+	if err != nil {
+		return nil, err
+	}
+	return ComputeMassProperties(r.Vertices, tris, r.VertexSize, normal)
+}
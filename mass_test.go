@@ -0,0 +1,144 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool { return math.Abs(a-b) <= tol }
+
+// TestMassProperties2D tests a square with a square hole
+func TestMassProperties2D(t *testing.T) {
+	r := gridResult(t, 3)
+	m, err := r.MassProperties(nil)
+	if err != nil {
+		t.Fatalf("MassProperties failed: %v", err)
+	}
+
+	if !near(m.Area, 96, 1e-6) || !near(m.SignedArea, 96, 1e-6) {
+		t.Errorf("Expected area 96, got %v (signed %v)", m.Area, m.SignedArea)
+	}
+	if !near(m.Centroid[0], 5, 1e-6) || !near(m.Centroid[1], 5, 1e-6) || m.Centroid[2] != 0 {
+		t.Errorf("Expected centroid (5, 5, 0), got %v", m.Centroid)
+	}
+	if !near(m.Perimeter, 48, 1e-6) {
+		t.Errorf("Expected perimeter 48, got %v", m.Perimeter)
+	}
+
+	// (10^4 - 2^4) / 12 about both axes through the centroid.
+	ix := (10000.0 - 16) / 12
+	if !near(m.Inertia[0][0], ix, 1e-3) || !near(m.Inertia[1][1], ix, 1e-3) {
+		t.Errorf("Expected Ix = Iy = %v, got %v and %v", ix, m.Inertia[0][0], m.Inertia[1][1])
+	}
+	if !near(m.Inertia[2][2], 2*ix, 1e-3) || !near(m.Inertia[0][1], 0, 1e-3) {
+		t.Errorf("Expected polar moment %v and no product, got %v", 2*ix, m.Inertia)
+	}
+
+	// Measured against -Z the signed area flips.
+	m, err = r.MassProperties([]float32{0, 0, -1})
+	if err != nil {
+		t.Fatalf("MassProperties failed: %v", err)
+	}
+	if !near(m.SignedArea, -96, 1e-6) || !near(m.Area, 96, 1e-6) {
+		t.Errorf("Expected signed area -96, got %v", m.SignedArea)
+	}
+}
+
+// TestMassPropertiesProduct tests the product moment of an asymmetric shape
+func TestMassPropertiesProduct(t *testing.T) {
+	// Right triangle with legs 3 along x and 6 along y.
+	vertices := []float32{0, 0, 3, 0, 0, 6}
+	m, err := ComputeMassProperties(vertices, []int{0, 1, 2}, 2, nil)
+	if err != nil {
+		t.Fatalf("ComputeMassProperties failed: %v", err)
+	}
+	if !near(m.Centroid[0], 1, 1e-9) || !near(m.Centroid[1], 2, 1e-9) {
+		t.Errorf("Expected centroid (1, 2), got %v", m.Centroid)
+	}
+	// About the centroid: Ix = b h^3 / 36, Iy = h b^3 / 36, Ixy = -b^2 h^2 / 72.
+	if !near(m.Inertia[0][0], 3*216.0/36, 1e-9) || !near(m.Inertia[1][1], 6*27.0/36, 1e-9) {
+		t.Errorf("Unexpected moments %v", m.Inertia)
+	}
+	if !near(m.SecondMoment[0][1], -9*36.0/72, 1e-9) || !near(m.Inertia[0][1], 9*36.0/72, 1e-9) {
+		t.Errorf("Unexpected product moment %v", m.SecondMoment[0][1])
+	}
+	if !near(m.Perimeter, 9+math.Sqrt(45), 1e-9) {
+		t.Errorf("Expected perimeter %v, got %v", 9+math.Sqrt(45), m.Perimeter)
+	}
+}
+
+// TestMassProperties3D tests a region in a tilted plane
+func TestMassProperties3D(t *testing.T) {
+	// A 4x2 rectangle in the plane x = z, centred at (2, 1, 2).
+	s := float32(math.Sqrt2)
+	rect := []float32{0, 0, 0, 2 * s, 0, 2 * s, 2 * s, 2, 2 * s, 0, 2, 0}
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.AddContour(3, rect); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 3, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+
+	m, err := r.MassProperties(nil)
+	if err != nil {
+		t.Fatalf("MassProperties failed: %v", err)
+	}
+	if !near(m.Area, 8, 1e-4) || !near(m.SignedArea, 8, 1e-4) {
+		t.Errorf("Expected area 8, got %v (signed %v)", m.Area, m.SignedArea)
+	}
+	if !near(m.Perimeter, 12, 1e-4) {
+		t.Errorf("Expected perimeter 12, got %v", m.Perimeter)
+	}
+	want := [3]float64{math.Sqrt2, 1, math.Sqrt2}
+	for k := range want {
+		if !near(m.Centroid[k], want[k], 1e-4) {
+			t.Errorf("Expected centroid %v, got %v", want, m.Centroid)
+			break
+		}
+	}
+	if !near(math.Abs(m.Normal[0]), math.Sqrt(0.5), 1e-4) || !near(m.Normal[1], 0, 1e-4) {
+		t.Errorf("Expected normal perpendicular to x = z, got %v", m.Normal)
+	}
+	// Polar moment about the plane normal: A (w^2 + h^2) / 12.
+	polar := 0.0
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			polar += m.Normal[i] * m.Inertia[i][j] * m.Normal[j]
+		}
+	}
+	if !near(polar, 8*(16+4)/12.0, 1e-3) {
+		t.Errorf("Expected polar moment %v, got %v", 8*20/12.0, polar)
+	}
+}
+
+// TestMassPropertiesWinding tests overlapping input resolved by a winding rule
+func TestMassPropertiesWinding(t *testing.T) {
+	tess := NewTessellator()
+	defer tess.Delete()
+	tess.AddContour(2, []float32{0, 0, 2, 0, 2, 2, 0, 2})
+	tess.AddContour(2, []float32{1, 1, 3, 1, 3, 3, 1, 3})
+	r, err := tess.TessellateResult(WindingNonZero, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	m, err := r.MassProperties(nil)
+	if err != nil {
+		t.Fatalf("MassProperties failed: %v", err)
+	}
+	if !near(m.Area, 7, 1e-5) || !near(m.Perimeter, 12, 1e-5) {
+		t.Errorf("Expected area 7 and perimeter 12, got %v and %v", m.Area, m.Perimeter)
+	}
+	if !near(m.Centroid[0], 1.5, 1e-5) || !near(m.Centroid[1], 1.5, 1e-5) {
+		t.Errorf("Expected centroid (1.5, 1.5), got %v", m.Centroid)
+	}
+
+	if _, err := ComputeMassProperties([]float32{0, 0}, []int{0, 0, 1}, 2, nil); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+	if _, err := ComputeMassProperties(nil, []int{0, 1}, 2, nil); err == nil {
+		t.Error("Expected error for partial triangle")
+	}
+}
@@ -0,0 +1,290 @@
+package tess
+
+/*
+#include <setjmp.h>
+#include <stdlib.h>
+#include <string.h>
+#include "tesselator.h"
+#include "tess.h"
+#include "sweep.h"
+
+// goTessBlock is the header in front of every block, linking the live
+// blocks so that those leaked by an aborted tessellation can be freed. It is
+// padded to keep the alignment of malloc.
+typedef union goTessBlock {
+	struct {
+		size_t size;
+		union goTessBlock* prev;
+		union goTessBlock* next;
+		// gen is the tessellation the block was allocated in, or 0 if it
+		// was allocated outside tessTesselate.
+		unsigned int gen;
+	} h;
+	char pad[32];
+} goTessBlock;
+
+// goTessAccount counts the bytes libtess2 holds for one tessellator.
+typedef struct {
+	size_t current;
+	size_t peak;
+	// limit is the most bytes that may be held, or 0 for no limit. It is
+	// only enforced while enforce is set, during tessTesselate.
+	size_t limit;
+	int enforce;
+	// refused is set when an allocation was refused because of limit.
+	int refused;
+	// gen counts the tessellations run with the limit enforced.
+	unsigned int gen;
+	TESStesselator* tess;
+	goTessBlock blocks;
+} goTessAccount;
+
+// goTessCheck aborts the tessellation in progress if growing a block from
+// old to size bytes would exceed the limit. libtess2 does not check every
+// allocation, so it is aborted the way it handles running out of memory
+// during the sweep, by jumping back to tessTesselate.
+static void goTessCheck(goTessAccount* a, size_t old, size_t size) {
+	if (a->enforce && a->limit != 0 && size > old && a->current - old + size > a->limit) {
+		a->refused = 1;
+		a->enforce = 0;
+		longjmp(a->tess->env, 1);
+	}
+}
+
+static void goTessLink(goTessAccount* a, goTessBlock* b, size_t old, size_t size) {
+	b->h.size = size;
+	b->h.gen = a->enforce ? a->gen : 0;
+	b->h.prev = &a->blocks;
+	b->h.next = a->blocks.h.next;
+	b->h.next->h.prev = b;
+	a->blocks.h.next = b;
+	a->current = a->current - old + size;
+	if (a->current > a->peak)
+		a->peak = a->current;
+}
+
+static void goTessUnlink(goTessAccount* a, goTessBlock* b) {
+	b->h.prev->h.next = b->h.next;
+	b->h.next->h.prev = b->h.prev;
+}
+
+static void* goTessAlloc(void* userData, unsigned int size) {
+	goTessAccount* a = (goTessAccount*)userData;
+	goTessBlock* b;
+	goTessCheck(a, 0, size);
+	b = (goTessBlock*)malloc(sizeof(goTessBlock) + (size_t)size);
+	if (b == NULL)
+		return NULL;
+	goTessLink(a, b, 0, size);
+	return b + 1;
+}
+
+static void* goTessRealloc(void* userData, void* ptr, unsigned int size) {
+	goTessAccount* a = (goTessAccount*)userData;
+	goTessBlock* b;
+	goTessBlock* r;
+	size_t old;
+	if (ptr == NULL)
+		return goTessAlloc(userData, size);
+	b = (goTessBlock*)ptr - 1;
+	old = b->h.size;
+	goTessCheck(a, old, size);
+	goTessUnlink(a, b);
+	r = (goTessBlock*)realloc(b, sizeof(goTessBlock) + (size_t)size);
+	if (r == NULL) {
+		goTessLink(a, b, old, old);
+		return NULL;
+	}
+	goTessLink(a, r, old, size);
+	return r + 1;
+}
+
+static void goTessFree(void* userData, void* ptr) {
+	goTessAccount* a = (goTessAccount*)userData;
+	goTessBlock* b;
+	if (ptr == NULL)
+		return;
+	b = (goTessBlock*)ptr - 1;
+	goTessUnlink(a, b);
+	a->current -= b->h.size;
+	free(b);
+}
+
+static goTessAccount* goTessNewAccount(void) {
+	goTessAccount* a = (goTessAccount*)calloc(1, sizeof(goTessAccount));
+	if (a == NULL)
+		return NULL;
+	a->blocks.h.prev = a->blocks.h.next = &a->blocks;
+	return a;
+}
+
+static TESStesselator* goTessNew(goTessAccount* a) {
+	TESSalloc alloc;
+	memset(&alloc, 0, sizeof(alloc));
+	alloc.memalloc = goTessAlloc;
+	alloc.memrealloc = goTessRealloc;
+	alloc.memfree = goTessFree;
+	alloc.userData = a;
+	a->tess = tessNewTess(&alloc);
+	return a->tess;
+}
+
+// goTessRecover frees what a tessellation aborted by the limit left behind,
+// the mesh and the sweep state allocated in it, and clears the error, so
+// that the tessellator can be used again. The region pool may hold buckets
+// allocated in the aborted tessellation, so it is replaced. It returns 0 if
+// the tessellator could not be recovered.
+static int goTessRecover(goTessAccount* a) {
+	TESStesselator* tess = a->tess;
+	struct BucketAlloc* regionPool;
+	goTessBlock* b;
+	goTessBlock* next;
+
+	regionPool = createBucketAlloc(&tess->alloc, "Regions", sizeof(ActiveRegion), tess->alloc.regionBucketSize);
+	if (regionPool == NULL)
+		return 0;
+	if (tess->mesh != NULL) {
+		tessMeshDeleteMesh(&tess->alloc, tess->mesh);
+		tess->mesh = NULL;
+	}
+	deleteBucketAlloc(tess->regionPool);
+	tess->regionPool = regionPool;
+	for (b = a->blocks.h.next; b != &a->blocks; b = next) {
+		next = b->h.next;
+		if (b->h.gen == a->gen)
+			goTessFree(a, b + 1);
+	}
+
+	tess->dict = NULL;
+	tess->pq = NULL;
+	tess->event = NULL;
+	tess->vertices = NULL;
+	tess->vertexIndices = NULL;
+	tess->elements = NULL;
+	tess->vertexCount = 0;
+	tess->elementCount = 0;
+	tess->status = TESS_STATUS_OK;
+	a->refused = 0;
+	return 1;
+}
+
+// goTessFreeAccount frees the blocks still live after tessDeleteTess, which
+// were leaked by aborted tessellations, and then the account.
+static void goTessFreeAccount(goTessAccount* a) {
+	while (a->blocks.h.next != &a->blocks)
+		goTessFree(a, a->blocks.h.next + 1);
+	free(a);
+}
+*/
+import "C"
+
+import "fmt"
+
+// MemoryStats describes the C heap memory libtess2 holds for a Tessellator.
+// This memory is not seen by the Go runtime.
+type MemoryStats struct {
+	// Current is the number of bytes allocated now and Peak the most
+	// allocated at once since creation or ResetPeakMemory.
+	Current int64
+	Peak    int64
+	// Limit is the cap set with SetMemoryLimit, or 0 if there is none.
+	Limit int64
+}
+
+// memoryAccount tracks the allocations of one libtess2 tessellator. The
+// counters live in C memory since libtess2 keeps a pointer to them.
+type memoryAccount struct {
+	a *C.goTessAccount
+}
+
+// newAccountedTess creates a libtess2 tessellator that allocates through a
+// new memoryAccount.
+func newAccountedTess() (*C.TESStesselator, memoryAccount) {
+	a := C.goTessNewAccount()
+	if a == nil {
+		return nil, memoryAccount{}
+	}
+	tess := C.goTessNew(a)
+	if tess == nil {
+		C.goTessFreeAccount(a)
+		return nil, memoryAccount{}
+	}
+	return tess, memoryAccount{a}
+}
+
+// free releases the counters, and any memory leaked by aborted
+// tessellations, once the tessellator has been deleted.
+func (m *memoryAccount) free() {
+	if m.a != nil {
+		C.goTessFreeAccount(m.a)
+		m.a = nil
+	}
+}
+
+// MemoryStats returns the C heap memory held by the Tessellator. It returns
+// zero stats for a deleted Tessellator.
+func (t *Tessellator) MemoryStats() MemoryStats {
+	if t == nil || t.tess == nil || t.memory.a == nil {
+		return MemoryStats{}
+	}
+	a := t.memory.a
+	return MemoryStats{Current: int64(a.current), Peak: int64(a.peak), Limit: int64(a.limit)}
+}
+
+// ResetPeakMemory sets the peak reported by MemoryStats to the current
+// usage, so that the peak of the next tessellation can be measured.
+func (t *Tessellator) ResetPeakMemory() {
+	if t == nil || t.tess == nil || t.memory.a == nil {
+		return
+	}
+	t.memory.a.peak = t.memory.a.current
+}
+
+// SetMemoryLimit caps the C heap memory the Tessellator may hold at bytes,
+// or removes the cap if bytes is 0. The cap only applies within Tessellate:
+// an allocation beyond it aborts the tessellation, which fails with
+// StatusOutOfMemory. Allocations made by AddContour to store its contour
+// are not limited, though they count towards the cap, so input beyond it
+// makes the next Tessellate fail. The contours of an aborted tessellation
+// are discarded and its memory is freed, leaving the Tessellator ready for
+// new contours.
+func (t *Tessellator) SetMemoryLimit(bytes int64) error {
+	if t == nil || t.tess == nil {
+		return fmt.Errorf("tessellator is nil or deleted")
+	}
+	if bytes < 0 {
+		return fmt.Errorf("memory limit must not be negative, got %d", bytes)
+	}
+	t.memory.a.limit = C.size_t(bytes)
+	return nil
+}
+
+// enforce turns enforcement of the limit on or off. Turning it on starts a
+// new generation of blocks, which are freed if the tessellation is aborted.
+func (m *memoryAccount) enforce(on bool) {
+	if m.a == nil {
+		return
+	}
+	m.a.enforce = 0
+	if on {
+		m.a.enforce = 1
+		m.a.gen++
+		if m.a.gen == 0 {
+			m.a.gen = 1
+		}
+	}
+}
+
+// recoverRefused reports whether the last tessellation was aborted by the
+// limit. If so, it frees what the aborted tessellation left behind and
+// clears the libtess2 status, which libtess2 never resets, so that the
+// Tessellator can be used again.
+func (t *Tessellator) recoverRefused() bool {
+	if t.memory.a == nil || t.memory.a.refused == 0 {
+		return false
+	}
+	if C.goTessRecover(t.memory.a) == 0 {
+		t.tess.status = C.TESS_STATUS_OUT_OF_MEMORY
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package tess
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// zigzag returns a contour with n spikes, whose tessellation needs memory
+// growing with n.
+func zigzag(n int) []float32 {
+	c := make([]float32, 0, 4*n+4)
+	for i := 0; i < n; i++ {
+		x := float32(i)
+		c = append(c, x, 0, x+0.5, 10)
+	}
+	return append(c, float32(n), 0, float32(n), -1, 0, -1)
+}
+
+// TestMemoryStats tests that allocations are counted and released
+func TestMemoryStats(t *testing.T) {
+	tess := NewTessellator()
+	created := tess.MemoryStats()
+	if created.Current <= 0 || created.Peak != created.Current || created.Limit != 0 {
+		t.Fatalf("Unexpected stats for a new tessellator %+v", created)
+	}
+
+	if err := tess.AddContour(2, zigzag(200)); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	added := tess.MemoryStats()
+	if added.Current <= created.Current {
+		t.Errorf("Expected the mesh to add memory, got %d after %d", added.Current, created.Current)
+	}
+
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil); err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	done := tess.MemoryStats()
+	if done.Peak <= added.Current || done.Current >= done.Peak {
+		t.Errorf("Expected a peak during the sweep above the output, got %+v", done)
+	}
+
+	tess.ResetPeakMemory()
+	if s := tess.MemoryStats(); s.Peak != s.Current {
+		t.Errorf("Expected the peak to be reset, got %+v", s)
+	}
+
+	// A second tessellation frees the first output.
+	tess.AddContour(2, []float32{0, 0, 1, 0, 1, 1})
+	tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil)
+	if s := tess.MemoryStats(); s.Current >= done.Current {
+		t.Errorf("Expected less memory for a smaller output, got %d after %d", s.Current, done.Current)
+	}
+
+	tess.Delete()
+	if s := tess.MemoryStats(); s != (MemoryStats{}) {
+		t.Errorf("Expected zero stats after Delete, got %+v", s)
+	}
+	if err := tess.SetMemoryLimit(1); err == nil {
+		t.Error("Expected error for a deleted tessellator")
+	}
+}
+
+// TestMemoryLimit tests that exceeding the limit fails with StatusOutOfMemory
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		elementType ElementType
+		polySize    int
+		cdt         bool
+	}{
+		{"triangles", ElementPolygons, 3, false},
+		{"delaunay polygons", ElementPolygons, 6, true},
+		{"connected", ElementConnectedPolygons, 4, false},
+		{"contours", ElementBoundaryContours, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(limit int64) (*Tessellator, error) {
+				tess := NewTessellator()
+				tess.SetOption(OptionConstrainedDelaunay, tt.cdt)
+				if err := tess.SetMemoryLimit(limit); err != nil {
+					t.Fatalf("SetMemoryLimit failed: %v", err)
+				}
+				if err := tess.AddContour(2, zigzag(500)); err != nil {
+					t.Fatalf("AddContour failed: %v", err)
+				}
+				tess.ResetPeakMemory()
+				_, _, err := tess.Tessellate(WindingOdd, tt.elementType, tt.polySize, 2, nil)
+				return tess, err
+			}
+
+			// Measure the peak of an unlimited tessellation first.
+			tess, err := run(0)
+			if err != nil {
+				t.Fatalf("Tessellate failed: %v", err)
+			}
+			peak := tess.MemoryStats().Peak
+			tess.Delete()
+			fresh := NewTessellator()
+			base := fresh.MemoryStats().Current
+			fresh.Delete()
+
+			// Limits below the peak abort at varying points.
+			failed := 0
+			for i := 0; i <= 40; i++ {
+				limit := peak * int64(i+40) / 80
+				tess, err := run(limit)
+				s := tess.MemoryStats()
+				switch {
+				case err == nil:
+					if s.Peak > limit {
+						t.Errorf("Limit %d: peak %d above the limit", limit, s.Peak)
+					}
+				case !strings.Contains(err.Error(), StatusOutOfMemory.String()) || tess.getStatus() != StatusOutOfMemory:
+					t.Errorf("Limit %d: expected an out of memory error, got %v", limit, err)
+				default:
+					failed++
+					if limit == peak {
+						t.Errorf("Expected success within the measured peak, got %v", err)
+					}
+					// The aborted tessellation is freed and the
+					// Tessellator recovers.
+					if s.Current != base {
+						t.Errorf("Limit %d: expected %d bytes after the abort, got %d", limit, base, s.Current)
+					}
+					tess.SetMemoryLimit(0)
+					tess.AddContour(2, []float32{0, 0, 1, 0, 1, 1})
+					if _, indices, err := tess.Tessellate(WindingOdd, tt.elementType, tt.polySize, 2, nil); err != nil || len(indices) == 0 {
+						t.Errorf("Limit %d: expected the tessellator to recover, got %v", limit, err)
+					}
+					tess.AddContour(2, zigzag(500))
+					if _, _, err := tess.Tessellate(WindingOdd, tt.elementType, tt.polySize, 2, nil); err != nil {
+						t.Errorf("Limit %d: expected the tessellator to recover, got %v", limit, err)
+					}
+				}
+				tess.Delete()
+			}
+			if failed == 0 {
+				t.Error("Expected tessellations to fail below the peak")
+			}
+		})
+	}
+
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.SetMemoryLimit(-1); err == nil {
+		t.Error("Expected error for a negative limit")
+	}
+	if err := tess.SetMemoryLimit(math.MaxInt64); err != nil {
+		t.Errorf("SetMemoryLimit failed: %v", err)
+	}
+}
+
+// TestPoolMemoryLimit tests that a Pool keeps recovered Tessellators and resets limits
+func TestPoolMemoryLimit(t *testing.T) {
+	p := NewPool(1)
+	defer p.Close()
+
+	tess, _ := p.Get()
+	tess.SetMemoryLimit(tess.MemoryStats().Current + 1024)
+	tess.AddContour(2, zigzag(500))
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil); err == nil {
+		t.Fatal("Expected tessellation to fail")
+	}
+	p.Put(tess)
+	if p.Idle() != 1 || tess.tess == nil {
+		t.Fatal("Expected the recovered tessellator to be kept")
+	}
+
+	tess, _ = p.Get()
+	tess.AddContour(2, zigzag(500))
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil); err != nil {
+		t.Fatalf("Expected the pooled tessellator to work, got %v", err)
+	}
+	tess.SetMemoryLimit(1 << 30)
+	p.Put(tess)
+	tess, _ = p.Get()
+	defer p.Put(tess)
+	if s := tess.MemoryStats(); s.Limit != 0 {
+		t.Errorf("Expected no limit after Put, got %d", s.Limit)
+	}
+}
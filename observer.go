@@ -0,0 +1,66 @@
+package tess
+
+import "time"
+
+// Event describes one call to Tessellate or TessellateResult.
+type Event struct {
+	// Start is when the call began and Duration how long it took.
+	Start    time.Time
+	Duration time.Duration
+	// InputVertices and InputContours count the input added since the
+	// previous tessellation, before simplification.
+	InputVertices int
+	InputContours int
+	// OutputVertices and OutputElements are zero if the call failed.
+	OutputVertices int
+	OutputElements int
+	WindingRule    WindingRule
+	ElementType    ElementType
+	// Status is the libtess2 status after the call. Err is the error
+	// returned to the caller, which may be set while Status is StatusOK for
+	// arguments rejected before reaching libtess2.
+	Status Status
+	Err    error
+}
+
+// Observer receives an Event for every tessellation, for example to record
+// metrics or tracing spans. Observe is called synchronously before
+// Tessellate returns; an Observer shared between Tessellators, such as one
+// set on a Pool, must be safe for concurrent use.
+type Observer interface {
+	Observe(Event)
+}
+
+// SetObserver sets the Observer notified of each tessellation, or removes it
+// if o is nil.
+func (t *Tessellator) SetObserver(o Observer) {
+	t.observer = o
+}
+
+// startEvent captures the input of a tessellation about to run.
+func (t *Tessellator) startEvent(windingRule WindingRule, elementType ElementType) Event {
+	e := Event{
+		Start:         time.Now(),
+		InputVertices: t.inputCount,
+		WindingRule:   windingRule,
+		ElementType:   elementType,
+	}
+	if t.inputCount > 0 {
+		// contourCount is only reset when the next contour is added.
+		e.InputContours = t.contourCount
+	}
+	return e
+}
+
+// finishEvent completes e with the outcome of the tessellation and passes
+// it to the observer.
+func (t *Tessellator) finishEvent(e Event, err error) {
+	e.Duration = time.Since(e.Start)
+	e.Err = err
+	e.Status = t.getStatus()
+	if err == nil {
+		e.OutputVertices = t.getVertexCount()
+		e.OutputElements = t.getElementCount()
+	}
+	t.observer.Observe(e)
+}
@@ -0,0 +1,109 @@
+package tess
+
+import (
+	"sync"
+	"testing"
+)
+
+// recorder is an Observer that keeps every event.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Observe(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// TestObserver tests the events of successful and failed tessellations
+func TestObserver(t *testing.T) {
+	var rec recorder
+	tess := NewTessellator()
+	defer tess.Delete()
+	tess.SetObserver(&rec)
+
+	tess.AddContour(2, []float32{0, 0, 4, 0, 4, 4, 0, 4})
+	tess.AddContour(2, []float32{1, 1, 2, 1, 2, 2})
+	r, err := tess.TessellateResult(WindingOdd, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	if len(rec.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(rec.events))
+	}
+	e := rec.events[0]
+	if e.InputVertices != 7 || e.InputContours != 2 {
+		t.Errorf("Expected 7 vertices in 2 contours, got %d in %d", e.InputVertices, e.InputContours)
+	}
+	if e.OutputVertices != r.VertexCount() || e.OutputElements != len(r.Indices)/3 {
+		t.Errorf("Expected %d vertices and %d elements, got %d and %d", r.VertexCount(), len(r.Indices)/3, e.OutputVertices, e.OutputElements)
+	}
+	if e.WindingRule != WindingOdd || e.ElementType != ElementPolygons || e.Status != StatusOK || e.Err != nil {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if e.Start.IsZero() || e.Duration < 0 {
+		t.Errorf("Unexpected timing %v %v", e.Start, e.Duration)
+	}
+
+	// A repeated call sees no new input; a rejected call reports its error.
+	tess.Tessellate(WindingNonZero, ElementBoundaryContours, 0, 2, nil)
+	tess.AddContour(2, []float32{0, 0, 1, 0, 1, 1})
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 4, nil); err == nil {
+		t.Fatal("Expected error for vertexSize 4")
+	}
+	if len(rec.events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(rec.events))
+	}
+	if e := rec.events[1]; e.InputVertices != 0 || e.InputContours != 0 || e.ElementType != ElementBoundaryContours || e.OutputElements != 0 {
+		t.Errorf("Unexpected event for a call without input %+v", e)
+	}
+	if e := rec.events[2]; e.Err == nil || e.OutputVertices != 0 || e.InputVertices != 3 || e.InputContours != 1 {
+		t.Errorf("Unexpected event for a failed call %+v", e)
+	}
+
+	tess.SetObserver(nil)
+	tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil)
+	if len(rec.events) != 3 {
+		t.Errorf("Expected no event after removing the observer, got %d", len(rec.events))
+	}
+}
+
+// TestPoolObserver tests that pooled Tessellators report to the pool's observer
+func TestPoolObserver(t *testing.T) {
+	var rec recorder
+	p := NewPool(4)
+	defer p.Close()
+	p.SetObserver(&rec)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tess, err := p.Get()
+			if err != nil {
+				t.Errorf("Get failed: %v", err)
+				return
+			}
+			defer p.Put(tess)
+			if _, err := squareJob(2).Run(tess); err != nil {
+				t.Errorf("Run failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(rec.events) != 8 {
+		t.Fatalf("Expected 8 events, got %d", len(rec.events))
+	}
+
+	// A Tessellator put back loses the observer it was given.
+	p.SetObserver(nil)
+	tess, _ := p.Get()
+	defer p.Put(tess)
+	squareJob(2).Run(tess)
+	if len(rec.events) != 8 {
+		t.Errorf("Expected no event after removing the pool observer, got %d", len(rec.events))
+	}
+}
@@ -0,0 +1,171 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// offsetMiterLimit is the maximum ratio of miter length to offset
+	// distance. Sharper corners are beveled.
+	offsetMiterLimit = 4
+	// offsetTolerance is the maximum distance between round joins and their
+	// polygonal approximation, relative to the offset distance.
+	offsetTolerance = 1.0 / 500
+)
+
+// Offset grows (delta > 0) or shrinks (delta < 0) the region enclosed by 2D
+// contours (x, y pairs) by delta. The input is resolved with WindingNonZero,
+// so contour orientation does not matter. Corners are joined according to
+// join; miters longer than 4*|delta| are beveled.
+//
+// The raw offset rings are cleaned with WindingPositive, which removes
+// self-intersections and holes or islands that collapse. Returns the
+// boundary of the result with outer contours counter-clockwise and holes
+// clockwise.
+func Offset(contours [][]float32, delta float32, join LineJoin) ([][]float32, error) {
+	raw, err := offsetContours(contours, delta, join)
+	if err != nil || len(raw) == 0 {
+		return raw, err
+	}
+
+	t := NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	for _, c := range raw {
+		if err := t.AddContour(2, c); err != nil {
+			return nil, err
+		}
+	}
+	vertices, elements, err := t.Tessellate(WindingPositive, ElementBoundaryContours, 0, 2, []float32{0, 0, 1})
+	if err != nil {
+		return nil, err
+	}
+
+	rings := make([][]float32, 0, len(elements)/2)
+	for i := 0; i+1 < len(elements); i += 2 {
+		base, count := elements[i], elements[i+1]
+		if count < 3 {
+			continue
+		}
+		rings = append(rings, vertices[2*base:2*(base+count)])
+	}
+	return rings, nil
+}
+
+// OffsetTriangles offsets contours like Offset and tessellates the result.
+// Returns 2D vertices and three indices per triangle.
+func OffsetTriangles(contours [][]float32, delta float32, join LineJoin) (vertices []float32, indices []int, err error) {
+	raw, err := offsetContours(contours, delta, join)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 {
+		return []float32{}, []int{}, nil
+	}
+
+	t := NewTessellator()
+	if t == nil {
+		return nil, nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	for _, c := range raw {
+		if err := t.AddContour(2, c); err != nil {
+			return nil, nil, err
+		}
+	}
+	return t.Tessellate(WindingPositive, ElementPolygons, 3, 2, []float32{0, 0, 1})
+}
+
+// offsetContours returns the raw, uncleaned offset rings of contours.
+func offsetContours(contours [][]float32, delta float32, join LineJoin) ([][]float32, error) {
+	if math.IsNaN(float64(delta)) || math.IsInf(float64(delta), 0) {
+		return nil, fmt.Errorf("offset distance must be finite, got %v", delta)
+	}
+	for i, c := range contours {
+		if len(c)%2 != 0 {
+			return nil, fmt.Errorf("contour %d has an odd number of coordinates", i)
+		}
+	}
+
+	rings, err := boundaryRings(contours, WindingNonZero)
+	if err != nil {
+		return nil, err
+	}
+	if delta == 0 {
+		return rings, nil
+	}
+
+	raw := make([][]float32, 0, len(rings))
+	for _, ring := range rings {
+		if r := offsetRing(polylinePoints(ring, true), float64(delta), join); len(r) >= 6 {
+			raw = append(raw, r)
+		}
+	}
+	return raw, nil
+}
+
+// offsetRing moves every edge of a ring, which has its region on the left,
+// outwards by delta. Where neighbouring edges move apart the gap is filled
+// with a join; where they move together the ring is routed back through the
+// original vertex, producing small loops that WindingPositive discards.
+func offsetRing(pts []vec2, delta float64, join LineJoin) []float32 {
+	n := len(pts)
+	if n < 3 {
+		return nil
+	}
+	tolerance := math.Abs(delta) * offsetTolerance
+
+	var out []float32
+	add := func(p vec2) { out = append(out, float32(p.x), float32(p.y)) }
+
+	for i := 0; i < n; i++ {
+		a, b, c := pts[(i+n-1)%n], pts[i], pts[(i+1)%n]
+		d1 := b.sub(a).normalized()
+		d2 := c.sub(b).normalized()
+		// Outward is to the right of the edge direction.
+		n1 := d1.perp().scale(-delta)
+		n2 := d2.perp().scale(-delta)
+		cross := d1.cross(d2)
+		cosTurn := d1.dot(d2)
+
+		if math.Abs(cross) < 1e-12 && cosTurn > 0 {
+			add(b.add(n1))
+			continue
+		}
+		if cross*delta < 0 {
+			add(b.add(n1))
+			add(b)
+			add(b.add(n2))
+			continue
+		}
+
+		dir := math.Copysign(1, cross)
+		if cross == 0 {
+			dir = math.Copysign(1, delta)
+		}
+		j := join
+		if j == JoinMiter {
+			cosHalf := math.Sqrt(math.Max(0, (1+cosTurn)/2))
+			if cosHalf == 0 || 1/cosHalf > offsetMiterLimit {
+				j = JoinBevel
+			}
+		}
+		switch j {
+		case JoinMiter:
+			add(b.add(n1.add(n2).scale(1 / (1 + cosTurn))))
+		case JoinRound:
+			for _, p := range arcPoints(b, math.Abs(delta), n1.angle(), n2.angle(), dir, tolerance) {
+				add(p)
+			}
+		default:
+			add(b.add(n1))
+			add(b.add(n2))
+		}
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// ringArea returns the signed area of a 2D ring.
+func ringArea(ring []float32) float64 {
+	area := 0.0
+	for i := 0; i < len(ring); i += 2 {
+		j := (i + 2) % len(ring)
+		area += float64(ring[i])*float64(ring[j+1]) - float64(ring[j])*float64(ring[i+1])
+	}
+	return area / 2
+}
+
+// totalArea returns the net signed area of a set of rings.
+func totalArea(rings [][]float32) float64 {
+	area := 0.0
+	for _, r := range rings {
+		area += ringArea(r)
+	}
+	return area
+}
+
+// TestOffsetSquare tests growing and shrinking a square with each join type
+func TestOffsetSquare(t *testing.T) {
+	square := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+	squareCW := []float32{0, 0, 0, 4, 4, 4, 4, 0}
+
+	tests := []struct {
+		name  string
+		delta float32
+		join  LineJoin
+		area  float64
+		tol   float64
+	}{
+		{"miter grow", 1, JoinMiter, 36, 1e-3},
+		{"bevel grow", 1, JoinBevel, 34, 1e-3},
+		{"round grow", 1, JoinRound, 32 + math.Pi, 0.01},
+		{"miter shrink", -1, JoinMiter, 4, 1e-3},
+		{"round shrink", -1, JoinRound, 4, 1e-3},
+		{"collapse", -2.5, JoinMiter, 0, 1e-6},
+		{"zero", 0, JoinMiter, 16, 1e-6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range [][]float32{square, squareCW} {
+				rings, err := Offset([][]float32{c}, tt.delta, tt.join)
+				if err != nil {
+					t.Fatalf("Offset failed: %v", err)
+				}
+				if area := totalArea(rings); math.Abs(area-tt.area) > tt.tol {
+					t.Errorf("Expected area %v, got %v", tt.area, area)
+				}
+
+				vertices, indices, err := OffsetTriangles([][]float32{c}, tt.delta, tt.join)
+				if err != nil {
+					t.Fatalf("OffsetTriangles failed: %v", err)
+				}
+				if area := triangleArea(vertices, indices); math.Abs(area-tt.area) > tt.tol {
+					t.Errorf("Expected triangle area %v, got %v", tt.area, area)
+				}
+			}
+		})
+	}
+}
+
+// TestOffsetHole tests that holes shrink when the region grows and vanish when they collapse
+func TestOffsetHole(t *testing.T) {
+	outer := []float32{0, 0, 10, 0, 10, 10, 0, 10}
+	// Holes must wind opposite to their outer contour under WindingNonZero.
+	hole := []float32{4, 4, 4, 6, 6, 6, 6, 4}
+
+	rings, err := Offset([][]float32{outer, hole}, 0.5, JoinMiter)
+	if err != nil {
+		t.Fatalf("Offset failed: %v", err)
+	}
+	if len(rings) != 2 {
+		t.Fatalf("Expected outer ring and hole, got %d rings", len(rings))
+	}
+	// 11x11 minus a 1x1 hole.
+	if area := totalArea(rings); math.Abs(area-120) > 1e-3 {
+		t.Errorf("Expected area 120, got %v", area)
+	}
+
+	rings, err = Offset([][]float32{outer, hole}, 1.5, JoinMiter)
+	if err != nil {
+		t.Fatalf("Offset failed: %v", err)
+	}
+	if len(rings) != 1 {
+		t.Fatalf("Expected collapsed hole to disappear, got %d rings", len(rings))
+	}
+	if area := totalArea(rings); math.Abs(area-169) > 1e-3 {
+		t.Errorf("Expected area 169, got %v", area)
+	}
+}
+
+// TestOffsetConcave tests shrinking an L shape, whose reflex corner produces a loop in the raw offset
+func TestOffsetConcave(t *testing.T) {
+	l := []float32{0, 0, 4, 0, 4, 2, 2, 2, 2, 4, 0, 4}
+
+	rings, err := Offset([][]float32{l}, -0.5, JoinMiter)
+	if err != nil {
+		t.Fatalf("Offset failed: %v", err)
+	}
+	if len(rings) != 1 {
+		t.Fatalf("Expected a single ring, got %d", len(rings))
+	}
+	// An L with arms 1 wide: 3x1 + 1x2.
+	if area := totalArea(rings); math.Abs(area-5) > 1e-3 {
+		t.Errorf("Expected area 5, got %v", area)
+	}
+
+	// Growing fills the reflex corner with a rounded fillet outside the L.
+	rings, err = Offset([][]float32{l}, 0.5, JoinRound)
+	if err != nil {
+		t.Fatalf("Offset failed: %v", err)
+	}
+	if len(rings) != 1 {
+		t.Fatalf("Expected a single ring, got %d", len(rings))
+	}
+	// 5x5 bounding square minus the 2x2 notch, with five rounded convex
+	// corners (the notch corner is sharp).
+	want := 25 - 4 - 5*(1-math.Pi/4)*0.25
+	if area := totalArea(rings); math.Abs(area-want) > 0.01 {
+		t.Errorf("Expected area %v, got %v", want, area)
+	}
+}
+
+// TestOffsetInvalidInput tests error handling
+func TestOffsetInvalidInput(t *testing.T) {
+	if _, err := Offset([][]float32{{0, 0, 1}}, 1, JoinMiter); err == nil {
+		t.Error("Expected error for odd coordinate count")
+	}
+	if _, err := Offset([][]float32{{0, 0, 1, 0, 0, 1}}, float32(math.NaN()), JoinMiter); err == nil {
+		t.Error("Expected error for NaN distance")
+	}
+
+	rings, err := Offset(nil, 1, JoinRound)
+	if err != nil || len(rings) != 0 {
+		t.Errorf("Expected no rings for no input, got %v, %v", rings, err)
+	}
+}
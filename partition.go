@@ -0,0 +1,57 @@
+package tess
+
+import "fmt"
+
+// ConvexPartition tessellates the contours added since the last tessellation
+// into convex polygons of at most maxVerts vertices, as Tessellate does for
+// ElementPolygons, and returns each polygon as a slice of vertex indices
+// without Undef padding. Polygons are wound counter-clockwise around the
+// projection normal. The result suits engines that only accept convex
+// shapes, such as physics engines building collision shapes.
+func (t *Tessellator) ConvexPartition(windingRule WindingRule, maxVerts, vertexSize int, normal []float32) (vertices []float32, polygons [][]int, err error) {
+	if maxVerts < 3 {
+		return nil, nil, fmt.Errorf("maxVerts must be at least 3, got %d", maxVerts)
+	}
+	vertices, indices, err := t.Tessellate(windingRule, ElementPolygons, maxVerts, vertexSize, normal)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vertices, unpadPolygons(indices, maxVerts, maxVerts), nil
+}
+
+// Polygons returns the polygons of an ElementPolygons or
+// ElementConnectedPolygons result as slices of vertex indices, without the
+// Undef padding of Indices. The slices share one backing array.
+func (r *Result) Polygons() ([][]int, error) {
+	stride := r.PolySize
+	switch r.ElementType {
+	case ElementPolygons:
+	case ElementConnectedPolygons:
+		stride = 2 * r.PolySize
+	default:
+		return nil, fmt.Errorf("result does not contain polygons: %s", r.ElementType)
+	}
+	if r.PolySize < 3 {
+		return nil, fmt.Errorf("polySize must be at least 3, got %d", r.PolySize)
+	}
+	return unpadPolygons(r.Indices, stride, r.PolySize), nil
+}
+
+// unpadPolygons splits elements laid out every stride indices into polygons
+// of up to polySize vertices, dropping the padding.
+func unpadPolygons(indices []int, stride, polySize int) [][]int {
+	count := len(indices) / stride
+	flat := make([]int, 0, count*polySize)
+	polygons := make([][]int, 0, count)
+	for e := 0; e < count; e++ {
+		start := len(flat)
+		for _, v := range indices[e*stride : e*stride+polySize] {
+			if v == Undef {
+				break
+			}
+			flat = append(flat, v)
+		}
+		polygons = append(polygons, flat[start:len(flat):len(flat)])
+	}
+	return polygons
+}
@@ -0,0 +1,105 @@
+package tess
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestConvexPartition tests that polygons are convex, unpadded and cover the input
+func TestConvexPartition(t *testing.T) {
+	contours := [][]float32{
+		noisyCircle(60, 10, 0.5),
+		{-3, -3, -3, 3, 3, 3, 3, -3},
+	}
+	var area float64
+	for _, c := range contours {
+		area += ringArea(c)
+	}
+
+	for _, maxVerts := range []int{3, 4, 6, 12} {
+		tess := NewTessellator()
+		for _, c := range contours {
+			tess.AddContour(2, c)
+		}
+		vertices, polygons, err := tess.ConvexPartition(WindingOdd, maxVerts, 2, nil)
+		tess.Delete()
+		if err != nil {
+			t.Fatalf("ConvexPartition failed: %v", err)
+		}
+
+		var total float64
+		for i, poly := range polygons {
+			if len(poly) < 3 || len(poly) > maxVerts || slices.Contains(poly, Undef) {
+				t.Fatalf("%d: polygon %d has invalid vertices %v", maxVerts, i, poly)
+			}
+			ring := pickVertices(2, vertices, poly)
+			for k := range poly {
+				a, b, c := k, (k+1)%len(poly), (k+2)%len(poly)
+				ux, uy := ring[2*b]-ring[2*a], ring[2*b+1]-ring[2*a+1]
+				vx, vy := ring[2*c]-ring[2*b], ring[2*c+1]-ring[2*b+1]
+				if ux*vy-uy*vx < -1e-4 {
+					t.Errorf("%d: polygon %d is not convex at vertex %d", maxVerts, i, b)
+				}
+			}
+			total += ringArea(ring)
+		}
+		if math.Abs(total-area) > 1e-3*area {
+			t.Errorf("%d: expected area %v, got %v", maxVerts, area, total)
+		}
+		if maxVerts > 3 && len(polygons) >= len(vertices)/2 {
+			t.Errorf("%d: expected triangles to be merged, got %d polygons", maxVerts, len(polygons))
+		}
+	}
+
+	tess := NewTessellator()
+	defer tess.Delete()
+	tess.AddContour(2, contours[1])
+	if _, _, err := tess.ConvexPartition(WindingOdd, 2, 2, nil); err == nil {
+		t.Error("Expected error for maxVerts 2")
+	}
+	// A square is a single convex polygon.
+	_, polygons, err := tess.ConvexPartition(WindingOdd, 8, 2, nil)
+	if err != nil || len(polygons) != 1 || len(polygons[0]) != 4 {
+		t.Errorf("Expected a single quad, got %v (%v)", polygons, err)
+	}
+}
+
+// TestResultPolygons tests unpadding the polygons of a Result
+func TestResultPolygons(t *testing.T) {
+	r := &Result{
+		Indices:     []int{0, 1, 2, Undef, 2, 3, 4, 5},
+		ElementType: ElementPolygons,
+		PolySize:    4,
+	}
+	polygons, err := r.Polygons()
+	if err != nil {
+		t.Fatalf("Polygons failed: %v", err)
+	}
+	if len(polygons) != 2 || !slices.Equal(polygons[0], []int{0, 1, 2}) || !slices.Equal(polygons[1], []int{2, 3, 4, 5}) {
+		t.Errorf("Unexpected polygons %v", polygons)
+	}
+	// Appending to a polygon does not overwrite the next one.
+	_ = append(polygons[0], 9)
+	if polygons[1][0] != 2 {
+		t.Error("Expected polygons not to share capacity")
+	}
+
+	r = &Result{
+		Indices:     []int{0, 1, 2, 1, Undef, Undef},
+		ElementType: ElementConnectedPolygons,
+		PolySize:    3,
+	}
+	if polygons, err := r.Polygons(); err != nil || len(polygons) != 1 || !slices.Equal(polygons[0], []int{0, 1, 2}) {
+		t.Errorf("Expected the neighbours to be skipped, got %v (%v)", polygons, err)
+	}
+
+	for _, bad := range []*Result{
+		{ElementType: ElementBoundaryContours},
+		{ElementType: ElementPolygons, PolySize: 2},
+	} {
+		if _, err := bad.Polygons(); err == nil {
+			t.Errorf("Expected error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package tess
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Polygon is an outer ring together with the holes directly inside it. Rings
+// hold the coordinates of their vertices.
+type Polygon struct {
+	Outer []float32
+	Holes [][]float32
+	// Parent is the index of the polygon with the hole this polygon is an
+	// island in, or -1 if it is not inside another polygon.
+	Parent int
+}
+
+// PolygonTree groups 2D rings, given as x, y pairs, into polygons with
+// holes. Rings must be oriented as ElementBoundaryContours produces them:
+// outer rings counter-clockwise and holes clockwise. Each hole belongs to
+// the smallest outer ring containing it, and outer rings inside a hole
+// become polygons of their own, listed after the polygon they are an island
+// in.
+func PolygonTree(contours [][]float32) ([]Polygon, error) {
+	rings := make([][]vec2, len(contours))
+	for i, c := range contours {
+		if len(c)%2 != 0 {
+			return nil, fmt.Errorf("contour %d: len(vertices)(%d) must be multiple of size (2)", i, len(c))
+		}
+		for k := 0; k+1 < len(c); k += 2 {
+			rings[i] = append(rings[i], vec2{float64(c[k]), float64(c[k+1])})
+		}
+	}
+	return buildPolygonTree(contours, rings)
+}
+
+// PolygonTree groups the boundary contours of an ElementBoundaryContours
+// result into polygons with holes, as the function PolygonTree does. Rings
+// hold VertexSize coordinates per vertex and are nested in the sweep plane.
+func (r *Result) PolygonTree() ([]Polygon, error) {
+	if r.ElementType != ElementBoundaryContours {
+		return nil, fmt.Errorf("result does not contain boundary contours: %s", r.ElementType)
+	}
+	if r.VertexSize != 2 && r.VertexSize != 3 {
+		return nil, fmt.Errorf("vertexSize must be 2 or 3, got %d", r.VertexSize)
+	}
+	n := r.VertexCount()
+	contours := make([][]float32, 0, len(r.Indices)/2)
+	rings := make([][]vec2, 0, len(r.Indices)/2)
+	for i := 0; i+1 < len(r.Indices); i += 2 {
+		base, count := r.Indices[i], r.Indices[i+1]
+		if base < 0 || count < 0 || base+count > n {
+			return nil, fmt.Errorf("contour %d references vertices %d to %d out of %d", i/2, base, base+count, n)
+		}
+		c := r.Vertices[base*r.VertexSize : (base+count)*r.VertexSize]
+		ring := make([]vec2, count)
+		for k := range ring {
+			x, y := r.project(c[k*r.VertexSize : (k+1)*r.VertexSize])
+			ring[k] = vec2{x, y}
+		}
+		contours = append(contours, slices.Clone(c))
+		rings = append(rings, ring)
+	}
+	return buildPolygonTree(contours, rings)
+}
+
+// treeRing is a ring being placed in the tree.
+type treeRing struct {
+	points []vec2
+	area   float64
+	box    [4]float64
+	// parent is the smallest ring of the other orientation containing the
+	// ring, or -1.
+	parent int
+	depth  int
+}
+
+func buildPolygonTree(contours [][]float32, points [][]vec2) ([]Polygon, error) {
+	rings := make([]treeRing, len(points))
+	for i, p := range points {
+		if len(p) < 3 {
+			return nil, fmt.Errorf("contour %d has fewer than 3 vertices", i)
+		}
+		r := treeRing{points: p, parent: -1, box: [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}}
+		for k, a := range p {
+			r.area += a.cross(p[(k+1)%len(p)]) / 2
+			r.box = [4]float64{min(r.box[0], a.x), min(r.box[1], a.y), max(r.box[2], a.x), max(r.box[3], a.y)}
+		}
+		if r.area == 0 {
+			return nil, fmt.Errorf("contour %d has no area", i)
+		}
+		rings[i] = r
+	}
+
+	for i := range rings {
+		r := &rings[i]
+		for j := range rings {
+			c := &rings[j]
+			if (c.area > 0) == (r.area > 0) || math.Abs(c.area) <= math.Abs(r.area) {
+				continue
+			}
+			if r.parent >= 0 && math.Abs(c.area) >= math.Abs(rings[r.parent].area) {
+				continue
+			}
+			if c.box[0] <= r.box[0] && c.box[1] <= r.box[1] && c.box[2] >= r.box[2] && c.box[3] >= r.box[3] && c.contains(r.points) {
+				r.parent = j
+			}
+		}
+		if r.area < 0 && r.parent < 0 {
+			return nil, fmt.Errorf("hole %d is not inside an outer contour", i)
+		}
+	}
+
+	// Polygons are listed by depth, so that islands follow their parents.
+	var depth func(i int) int
+	depth = func(i int) int {
+		if p := rings[i].parent; p >= 0 && rings[i].depth == 0 {
+			rings[i].depth = depth(p) + 1
+		}
+		return rings[i].depth
+	}
+	var outers []int
+	for i := range rings {
+		depth(i)
+		if rings[i].area > 0 {
+			outers = append(outers, i)
+		}
+	}
+	slices.SortStableFunc(outers, func(a, b int) int {
+		return cmp.Compare(rings[a].depth, rings[b].depth)
+	})
+
+	polygon := make(map[int]int, len(outers))
+	polygons := make([]Polygon, len(outers))
+	for k, i := range outers {
+		polygon[i] = k
+		polygons[k] = Polygon{Outer: contours[i], Parent: -1}
+		if hole := rings[i].parent; hole >= 0 {
+			polygons[k].Parent = polygon[rings[hole].parent]
+		}
+	}
+	for i, r := range rings {
+		if r.area < 0 {
+			k := polygon[r.parent]
+			polygons[k].Holes = append(polygons[k].Holes, contours[i])
+		}
+	}
+	return polygons, nil
+}
+
+// contains reports whether the ring contains the ring with the given
+// points. Rings may touch but not cross, so the first point not on the
+// ring's boundary decides; edge midpoints are tried if all vertices are.
+func (r *treeRing) contains(points []vec2) bool {
+	for pass := 0; pass < 2; pass++ {
+		for k, p := range points {
+			if pass == 1 {
+				p = p.add(points[(k+1)%len(points)]).scale(0.5)
+			}
+			if !r.onBoundary(p) {
+				return ringWinding(r.points, p) != 0
+			}
+		}
+	}
+	return false
+}
+
+func (r *treeRing) onBoundary(p vec2) bool {
+	for k, a := range r.points {
+		b := r.points[(k+1)%len(r.points)]
+		ab, ap := b.sub(a), p.sub(a)
+		if ab.cross(ap) == 0 && ap.dot(ab) >= 0 && ap.dot(ab) <= ab.dot(ab) {
+			return true
+		}
+	}
+	return false
+}
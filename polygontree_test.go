@@ -0,0 +1,104 @@
+package tess
+
+import (
+	"reflect"
+	"testing"
+)
+
+// square returns a counter-clockwise square, or a clockwise one if cw is set.
+func square(x0, y0, x1, y1 float32, cw bool) []float32 {
+	if cw {
+		return []float32{x0, y0, x0, y1, x1, y1, x1, y0}
+	}
+	return []float32{x0, y0, x1, y0, x1, y1, x0, y1}
+}
+
+// TestPolygonTree tests nesting of holes and islands
+func TestPolygonTree(t *testing.T) {
+	outer := square(0, 0, 20, 20, false)
+	hole := square(2, 2, 18, 18, true)
+	island := square(4, 4, 16, 16, false)
+	islandHole := square(6, 6, 8, 8, true)
+	// Touches the island at a corner.
+	islandHole2 := square(12, 12, 16, 16, true)
+	other := square(30, 0, 40, 10, false)
+
+	polygons, err := PolygonTree([][]float32{islandHole, other, island, hole, outer, islandHole2})
+	if err != nil {
+		t.Fatalf("PolygonTree failed: %v", err)
+	}
+	want := []Polygon{
+		{Outer: other, Parent: -1},
+		{Outer: outer, Holes: [][]float32{hole}, Parent: -1},
+		{Outer: island, Holes: [][]float32{islandHole, islandHole2}, Parent: 1},
+	}
+	if !reflect.DeepEqual(polygons, want) {
+		t.Errorf("Expected %v, got %v", want, polygons)
+	}
+
+	for _, bad := range [][][]float32{
+		{hole},
+		{outer, square(30, 0, 40, 10, true)},
+		{outer, {0, 0, 1, 1, 2, 2}},
+		{outer, {0, 0, 1, 1}},
+		{{0, 0, 1}},
+	} {
+		if _, err := PolygonTree(bad); err == nil {
+			t.Errorf("Expected error for %v", bad)
+		}
+	}
+}
+
+// TestResultPolygonTree tests the tree of tessellated boundary contours
+func TestResultPolygonTree(t *testing.T) {
+	// Rings added in any orientation; the boundary output is normalised.
+	contours := [][]float32{
+		square(0, 0, 20, 20, true),
+		square(2, 2, 18, 18, false),
+		square(4, 4, 16, 16, true),
+		square(6, 6, 8, 8, false),
+		square(30, 0, 40, 10, true),
+	}
+	for _, vertexSize := range []int{2, 3} {
+		tess := NewTessellator()
+		for _, c := range contours {
+			tess.AddContour(2, c)
+		}
+		r, err := tess.TessellateResult(WindingOdd, ElementBoundaryContours, 0, vertexSize, nil)
+		tess.Delete()
+		if err != nil {
+			t.Fatalf("TessellateResult failed: %v", err)
+		}
+		polygons, err := r.PolygonTree()
+		if err != nil {
+			t.Fatalf("PolygonTree failed: %v", err)
+		}
+		if len(polygons) != 3 {
+			t.Fatalf("Expected 3 polygons, got %v", polygons)
+		}
+		var holes, islands int
+		for _, p := range polygons {
+			if len(p.Outer) != 4*vertexSize {
+				t.Errorf("Expected square outer rings, got %v", p.Outer)
+			}
+			holes += len(p.Holes)
+			if p.Parent >= 0 {
+				islands++
+				if len(polygons[p.Parent].Holes) != 1 || len(p.Holes) != 1 {
+					t.Errorf("Expected the island and its parent to have a hole each, got %v", polygons)
+				}
+			}
+		}
+		if holes != 2 || islands != 1 {
+			t.Errorf("Expected 2 holes and 1 island, got %d and %d", holes, islands)
+		}
+	}
+
+	if _, err := (&Result{ElementType: ElementPolygons, VertexSize: 2}).PolygonTree(); err == nil {
+		t.Error("Expected error for a polygon result")
+	}
+	bad := &Result{ElementType: ElementBoundaryContours, VertexSize: 2, Vertices: []float32{0, 0, 1, 0, 1, 1}, Indices: []int{0, 4}}
+	if _, err := bad.PolygonTree(); err == nil {
+		t.Error("Expected error for a contour out of range")
+	}
+}
@@ -0,0 +1,153 @@
+package tess
+
+/*
+#include "tesselator.h"
+#include "tess.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Pool keeps idle Tessellators for reuse, avoiding the cost of creating a
+// new libtess2 context for every tessellation. A Pool is safe for concurrent
+// use; each Tessellator obtained from it must only be used by one goroutine
+// at a time.
+type Pool struct {
+	mu      sync.Mutex
+	idle    []*Tessellator
+	maxIdle int
+	closed  bool
+
+	observer Observer
+}
+
+// NewPool creates a pool that keeps at most maxIdle idle Tessellators.
+func NewPool(maxIdle int) *Pool {
+	if maxIdle < 0 {
+		maxIdle = 0
+	}
+	return &Pool{maxIdle: maxIdle}
+}
+
+// Get returns an idle Tessellator, or a new one if none is available. The
+// Tessellator is in the same state as one returned by NewTessellator, except
+// that it reports to the pool's Observer.
+func (p *Pool) Get() (*Tessellator, error) {
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		t := p.idle[n-1]
+		p.idle[n-1] = nil
+		p.idle = p.idle[:n-1]
+		t.observer = p.observer
+		p.mu.Unlock()
+		return t, nil
+	}
+	observer := p.observer
+	p.mu.Unlock()
+
+	t := NewTessellator()
+	if t == nil {
+		return nil, fmt.Errorf("failed to create tessellator")
+	}
+	t.observer = observer
+	return t, nil
+}
+
+// SetObserver sets the Observer given to Tessellators returned by Get, or
+// removes it if o is nil. Tessellators already handed out keep their
+// observer until they are put back.
+func (p *Pool) SetObserver(o Observer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observer = o
+}
+
+// Put returns a Tessellator to the pool. Its options are reset to their
+// defaults. A Tessellator with contours that were never tessellated cannot
+// be cleared and is deleted instead, as is one left in a failed state, any
+// Tessellator beyond maxIdle and any put after Close.
+func (p *Pool) Put(t *Tessellator) {
+	if t == nil || t.tess == nil {
+		return
+	}
+	// A tessellation aborted by the memory limit leaves the Tessellator
+	// recovered, so only the failure it still reports is cleared.
+	t.outOfMemory = false
+	if t.inputCount > 0 || t.getStatus() != StatusOK {
+		t.Delete()
+		return
+	}
+	t.reset()
+
+	p.mu.Lock()
+	if !p.closed && len(p.idle) < p.maxIdle {
+		p.idle = append(p.idle, t)
+		t = nil
+	}
+	p.mu.Unlock()
+
+	if t != nil {
+		t.Delete()
+	}
+}
+
+// Idle returns the number of idle Tessellators in the pool.
+func (p *Pool) Idle() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.idle)
+}
+
+// Close deletes the idle Tessellators. Tessellators put afterwards are
+// deleted immediately; Get keeps working but no longer reuses instances.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, t := range idle {
+		t.Delete()
+	}
+}
+
+// reset restores the settings of a Tessellator with no pending input to
+// those of a new one.
+func (t *Tessellator) reset() {
+	C.tessSetOption(t.tess, C.TESS_CONSTRAINED_DELAUNAY_TRIANGULATION, 0)
+	C.tessSetOption(t.tess, C.TESS_REVERSE_CONTOURS, 0)
+	t.clearNormal()
+
+	t.simplifyMode = SimplifyNone
+	t.simplifyTolerance = 0
+	t.cleanupEpsilon = 0
+	t.welder = nil
+	t.cleanupReport = CleanupReport{}
+	t.sourceIndex = nil
+	t.planarityTolerance = 0
+	t.strictPlanarity = false
+	t.projection = Projection{}
+	t.edgeFlags = false
+	t.edgeMasks = nil
+	t.attribution = false
+	t.reverseContours = false
+	t.contourIDs = nil
+	t.windings = nil
+	t.canonical = false
+	t.canonicalIndices = nil
+	t.observer = nil
+	t.memory.a.limit = 0
+}
+
+// clearNormal makes the next tessellation without a normal compute one.
+// libtess2 otherwise keeps the last normal passed to tessTesselate and uses
+// it when a later call passes none.
+func (t *Tessellator) clearNormal() {
+	for k := 0; k < 3; k++ {
+		t.tess.normal[k] = 0
+	}
+}
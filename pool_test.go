@@ -0,0 +1,128 @@
+package tess
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestPoolReuse tests that pooled tessellators are reused with default settings
+func TestPoolReuse(t *testing.T) {
+	p := NewPool(2)
+	defer p.Close()
+
+	a, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if err := a.SetOption(OptionReverseContours, true); err != nil {
+		t.Fatalf("SetOption failed: %v", err)
+	}
+	if err := a.SetSimplify(SimplifyDouglasPeucker, 10); err != nil {
+		t.Fatalf("SetSimplify failed: %v", err)
+	}
+	if err := a.AddContour(2, []float32{0, 0, 4, 0, 4, 4, 0, 4}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	if _, _, err := a.Tessellate(WindingPositive, ElementPolygons, 3, 2, []float32{0, 0, -1}); err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	p.Put(a)
+	if p.Idle() != 1 {
+		t.Fatalf("Expected 1 idle tessellator, got %d", p.Idle())
+	}
+
+	b, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if b != a {
+		t.Error("Expected the idle tessellator to be reused")
+	}
+	if err := b.AddContour(2, []float32{0, 0, 4, 0, 4, 4, 0, 4}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	r, err := b.TessellateResult(WindingPositive, ElementPolygons, 3, 2, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	// Neither the reversal nor the previous normal may carry over.
+	if len(r.Indices) != 6 {
+		t.Errorf("Expected two triangles, got %d indices", len(r.Indices))
+	}
+	if !r.Projection.Computed {
+		t.Error("Expected the normal to be computed")
+	}
+	if r.VertexCount() != 4 {
+		t.Errorf("Expected simplification to be off, got %d vertices", r.VertexCount())
+	}
+	p.Put(b)
+}
+
+// TestPoolDiscard tests that pending input, excess and closed pools delete tessellators
+func TestPoolDiscard(t *testing.T) {
+	p := NewPool(1)
+
+	pending, _ := p.Get()
+	if err := pending.AddContour(2, []float32{0, 0, 1, 0, 1, 1}); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	p.Put(pending)
+	if pending.tess != nil || p.Idle() != 0 {
+		t.Error("Expected a tessellator with pending input to be deleted")
+	}
+
+	a, _ := p.Get()
+	b, _ := p.Get()
+	p.Put(a)
+	p.Put(b)
+	if p.Idle() != 1 || b.tess != nil {
+		t.Errorf("Expected one idle tessellator and the excess deleted, got %d idle", p.Idle())
+	}
+
+	p.Close()
+	if a.tess != nil || p.Idle() != 0 {
+		t.Error("Expected Close to delete idle tessellators")
+	}
+	c, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get after Close failed: %v", err)
+	}
+	p.Put(c)
+	if c.tess != nil {
+		t.Error("Expected Put after Close to delete the tessellator")
+	}
+
+	p.Put(nil)
+}
+
+// TestPoolConcurrent tests concurrent use of a pool
+func TestPoolConcurrent(t *testing.T) {
+	p := NewPool(4)
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				tess, err := p.Get()
+				if err != nil {
+					t.Errorf("Get failed: %v", err)
+					return
+				}
+				if err := tess.AddContour(2, []float32{0, 0, 4, 0, 4, 4, 0, 4, 2, 2}); err != nil {
+					t.Errorf("AddContour failed: %v", err)
+				}
+				if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil); err != nil {
+					t.Errorf("Tessellate failed: %v", err)
+				}
+				p.Put(tess)
+			}
+		}()
+	}
+	wg.Wait()
+	if p.Idle() > 4 {
+		t.Errorf("Expected at most 4 idle tessellators, got %d", p.Idle())
+	}
+}
@@ -0,0 +1,247 @@
+package tess
+
+/*
+#include "tesselator.h"
+#include "tess.h"
+#include "mesh.h"
+#include <math.h>
+
+#define GO_ABS(x) ((x) < 0 ? -(x) : (x))
+
+static int goShortAxis(TESSreal v[3]) {
+	int i = 0;
+	if (GO_ABS(v[1]) < GO_ABS(v[0])) { i = 1; }
+	if (GO_ABS(v[2]) < GO_ABS(v[i])) { i = 2; }
+	return i;
+}
+
+// goComputeNormal mirrors ComputeNormal in tess.c, which is static.
+static void goComputeNormal(TESSmesh *mesh, TESSreal norm[3]) {
+	TESSvertex *v, *v1, *v2;
+	TESSreal c, tLen2, maxLen2;
+	TESSreal maxVal[3], minVal[3], d1[3], d2[3], tNorm[3];
+	TESSvertex *maxVert[3], *minVert[3];
+	TESSvertex *vHead = &mesh->vHead;
+	int i;
+
+	norm[0] = 0; norm[1] = 0; norm[2] = 1;
+	v = vHead->next;
+	if (v == vHead) {
+		return;
+	}
+	for (i = 0; i < 3; ++i) {
+		c = v->coords[i];
+		minVal[i] = c; minVert[i] = v;
+		maxVal[i] = c; maxVert[i] = v;
+	}
+	for (v = vHead->next; v != vHead; v = v->next) {
+		for (i = 0; i < 3; ++i) {
+			c = v->coords[i];
+			if (c < minVal[i]) { minVal[i] = c; minVert[i] = v; }
+			if (c > maxVal[i]) { maxVal[i] = c; maxVert[i] = v; }
+		}
+	}
+
+	i = 0;
+	if (maxVal[1] - minVal[1] > maxVal[0] - minVal[0]) { i = 1; }
+	if (maxVal[2] - minVal[2] > maxVal[i] - minVal[i]) { i = 2; }
+	if (minVal[i] >= maxVal[i]) {
+		return;
+	}
+
+	maxLen2 = 0;
+	v1 = minVert[i];
+	v2 = maxVert[i];
+	d1[0] = v1->coords[0] - v2->coords[0];
+	d1[1] = v1->coords[1] - v2->coords[1];
+	d1[2] = v1->coords[2] - v2->coords[2];
+	for (v = vHead->next; v != vHead; v = v->next) {
+		d2[0] = v->coords[0] - v2->coords[0];
+		d2[1] = v->coords[1] - v2->coords[1];
+		d2[2] = v->coords[2] - v2->coords[2];
+		tNorm[0] = d1[1]*d2[2] - d1[2]*d2[1];
+		tNorm[1] = d1[2]*d2[0] - d1[0]*d2[2];
+		tNorm[2] = d1[0]*d2[1] - d1[1]*d2[0];
+		tLen2 = tNorm[0]*tNorm[0] + tNorm[1]*tNorm[1] + tNorm[2]*tNorm[2];
+		if (tLen2 > maxLen2) {
+			maxLen2 = tLen2;
+			norm[0] = tNorm[0]; norm[1] = tNorm[1]; norm[2] = tNorm[2];
+		}
+	}
+	if (maxLen2 <= 0) {
+		norm[0] = norm[1] = norm[2] = 0;
+		norm[goShortAxis(d1)] = 1;
+	}
+}
+
+// goInputPlane determines the normal tessTesselate will use for the current
+// input and measures how far the input vertices lie from the plane through
+// their centroid. It must be called before tessTesselate consumes the mesh.
+// Returns 1 if the normal was computed rather than specified.
+static int goInputPlane(TESStesselator *tess, const TESSreal *normal, TESSreal norm[3], TESSreal *deviation, TESSreal *extent) {
+	TESSvertex *v, *vHead;
+	TESSreal centroid[3] = {0, 0, 0}, bmin[3], bmax[3], len, d;
+	int i, n = 0, computed = 0;
+
+	*deviation = 0;
+	*extent = 0;
+	if (normal == NULL) {
+		normal = tess->normal;
+	}
+	norm[0] = normal[0]; norm[1] = normal[1]; norm[2] = normal[2];
+	if (tess->mesh == NULL) {
+		if (norm[0] == 0 && norm[1] == 0 && norm[2] == 0) {
+			norm[2] = 1;
+			computed = 1;
+		}
+		return computed;
+	}
+	if (norm[0] == 0 && norm[1] == 0 && norm[2] == 0) {
+		goComputeNormal(tess->mesh, norm);
+		computed = 1;
+	}
+
+	len = norm[0]*norm[0] + norm[1]*norm[1] + norm[2]*norm[2];
+	if (len <= 0) {
+		return computed;
+	}
+	len = (TESSreal)sqrt(len);
+
+	vHead = &tess->mesh->vHead;
+	for (v = vHead->next; v != vHead; v = v->next) {
+		for (i = 0; i < 3; ++i) {
+			centroid[i] += v->coords[i];
+			if (n == 0 || v->coords[i] < bmin[i]) bmin[i] = v->coords[i];
+			if (n == 0 || v->coords[i] > bmax[i]) bmax[i] = v->coords[i];
+		}
+		n++;
+	}
+	if (n == 0) {
+		return computed;
+	}
+	for (i = 0; i < 3; ++i) {
+		centroid[i] /= n;
+		*extent += (bmax[i] - bmin[i]) * (bmax[i] - bmin[i]);
+	}
+	*extent = (TESSreal)sqrt(*extent);
+	for (v = vHead->next; v != vHead; v = v->next) {
+		d = ((v->coords[0] - centroid[0]) * norm[0] +
+			(v->coords[1] - centroid[1]) * norm[1] +
+			(v->coords[2] - centroid[2]) * norm[2]) / len;
+		if (GO_ABS(d) > *deviation) *deviation = GO_ABS(d);
+	}
+	return computed;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+)
+
+// defaultPlanarityTolerance is the distance from the projection plane, relative
+// to the size of the input, beyond which input is reported as non-planar.
+const defaultPlanarityTolerance = 1e-4
+
+// Projection describes the plane libtess2 projected the input onto.
+type Projection struct {
+	// Normal is the unit normal of the projection plane. Output polygons are
+	// wound counter-clockwise when seen from its tip.
+	Normal [3]float32 `json:"normal"`
+	// Computed is true if the normal was computed from the input rather
+	// than passed to Tessellate.
+	Computed bool `json:"computed"`
+	// BasisS and BasisT are the axes of the 2D sweep coordinates. libtess2
+	// projects along a coordinate axis, so they are unit coordinate axes
+	// rather than exactly orthogonal to Normal.
+	BasisS [3]float32 `json:"basisS"`
+	BasisT [3]float32 `json:"basisT"`
+	// Deviation is the largest distance of an input vertex from the plane
+	// through the input centroid with the given normal.
+	Deviation float32 `json:"deviation"`
+	// NonPlanar is true if Deviation exceeded the planarity tolerance.
+	NonPlanar bool `json:"nonPlanar"`
+}
+
+// SetPlanarityTolerance sets the distance from the projection plane beyond
+// which input is considered non-planar. A tolerance of 0 selects the default
+// of 1e-4 times the diagonal of the input bounding box. In strict mode
+// Tessellate fails for non-planar input instead of projecting it.
+func (t *Tessellator) SetPlanarityTolerance(tolerance float32, strict bool) error {
+	if t == nil || t.tess == nil {
+		return fmt.Errorf("tessellator is nil or deleted")
+	}
+	if tolerance < 0 || math.IsNaN(float64(tolerance)) {
+		return fmt.Errorf("planarity tolerance must not be negative, got %v", tolerance)
+	}
+	t.planarityTolerance = tolerance
+	t.strictPlanarity = strict
+	return nil
+}
+
+// Projection returns the projection used by the last tessellation.
+func (t *Tessellator) Projection() Projection {
+	return t.projection
+}
+
+// inputPlane measures the pending input before it is tessellated.
+func (t *Tessellator) inputPlane(normalPtr *C.TESSreal) error {
+	var norm [3]C.TESSreal
+	var deviation, extent C.TESSreal
+	computed := C.goInputPlane(t.tess, normalPtr, &norm[0], &deviation, &extent) != 0
+
+	p := Projection{Computed: computed, Deviation: float32(deviation)}
+	l := math.Sqrt(float64(norm[0]*norm[0] + norm[1]*norm[1] + norm[2]*norm[2]))
+	if l == 0 {
+		l = 1
+	}
+	for k := range p.Normal {
+		p.Normal[k] = float32(float64(norm[k]) / l)
+	}
+	positiveZero(&p.Normal)
+
+	tolerance := t.planarityTolerance
+	if tolerance == 0 {
+		tolerance = float32(defaultPlanarityTolerance * float64(extent))
+	}
+	p.NonPlanar = p.Deviation > tolerance
+	t.projection = p
+
+	if p.NonPlanar && t.strictPlanarity {
+		return fmt.Errorf("input is not planar: deviation %v exceeds tolerance %v", p.Deviation, tolerance)
+	}
+	return nil
+}
+
+// projectionBasis completes the projection after a successful tessellation
+// with the sweep axes chosen by libtess2.
+func (t *Tessellator) projectionBasis() {
+	p := &t.projection
+	for k := 0; k < 3; k++ {
+		p.BasisS[k] = float32(t.tess.sUnit[k])
+		p.BasisT[k] = float32(t.tess.tUnit[k])
+	}
+
+	// A computed normal is oriented so that the contours have positive area,
+	// which libtess2 records by flipping BasisT. Match that orientation.
+	s, u := p.BasisS, p.BasisT
+	axis := [3]float32{s[1]*u[2] - s[2]*u[1], s[2]*u[0] - s[0]*u[2], s[0]*u[1] - s[1]*u[0]}
+	if p.Computed && axis[0]*p.Normal[0]+axis[1]*p.Normal[1]+axis[2]*p.Normal[2] < 0 {
+		for k := range p.Normal {
+			p.Normal[k] = -p.Normal[k]
+		}
+	}
+	positiveZero(&p.Normal)
+	positiveZero(&p.BasisS)
+	positiveZero(&p.BasisT)
+}
+
+// positiveZero turns -0 components of v, left by negation and by libtess2,
+// into +0, which encodes as "0" rather than "-0". Adding +0 leaves every
+// other value unchanged.
+func positiveZero(v *[3]float32) {
+	for k := range v {
+		v[k] += 0
+	}
+}
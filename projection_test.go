@@ -0,0 +1,154 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+func projectionOf(t *testing.T, size int, contour []float32, normal []float32) (*Result, error) {
+	t.Helper()
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.AddContour(size, contour); err != nil {
+		t.Fatalf("AddContour failed: %v", err)
+	}
+	return tess.TessellateResult(WindingOdd, ElementPolygons, 3, 3, normal)
+}
+
+// triangleNormal returns the unnormalized normal of triangle i of a 3D result.
+func triangleNormal(r *Result, i int) [3]float64 {
+	p := func(k int) [3]float64 {
+		v := r.Indices[3*i+k]
+		return [3]float64{float64(r.Vertices[3*v]), float64(r.Vertices[3*v+1]), float64(r.Vertices[3*v+2])}
+	}
+	a, b, c := p(0), p(1), p(2)
+	return cross3(sub3(b, a), sub3(c, a))
+}
+
+// hasNegativeZero reports whether any component of the vectors is -0.
+func hasNegativeZero(vs ...[3]float32) bool {
+	for _, v := range vs {
+		for _, c := range v {
+			if c == 0 && math.Signbit(float64(c)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestProjectionComputedNormal tests the reported normal for 2D input of either orientation
+func TestProjectionComputedNormal(t *testing.T) {
+	tests := []struct {
+		name    string
+		contour []float32
+		normalZ float32
+	}{
+		{"ccw", []float32{0, 0, 1, 0, 1, 1, 0, 1}, 1},
+		{"cw", []float32{0, 0, 0, 1, 1, 1, 1, 0}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := projectionOf(t, 2, tt.contour, nil)
+			if err != nil {
+				t.Fatalf("Tessellate failed: %v", err)
+			}
+			p := r.Projection
+			if !p.Computed || p.NonPlanar || p.Deviation != 0 {
+				t.Errorf("Unexpected projection %+v", p)
+			}
+			if p.Normal != [3]float32{0, 0, tt.normalZ} {
+				t.Errorf("Expected normal (0, 0, %v), got %v", tt.normalZ, p.Normal)
+			}
+			// Output triangles are counter-clockwise around the normal.
+			for i := 0; i < len(r.Indices)/3; i++ {
+				if n := triangleNormal(r, i); n[2]*float64(tt.normalZ) <= 0 {
+					t.Errorf("Triangle %d has normal %v", i, n)
+				}
+			}
+			if p.BasisS[2] != 0 || p.BasisT[2] != 0 {
+				t.Errorf("Expected a basis in the XY plane, got %v and %v", p.BasisS, p.BasisT)
+			}
+			// Zero components are +0, so that they do not encode as -0.
+			if hasNegativeZero(p.Normal, p.BasisS, p.BasisT) {
+				t.Errorf("Expected no -0 components, got %v, %v and %v", p.Normal, p.BasisS, p.BasisT)
+			}
+		})
+	}
+}
+
+// TestProjectionTilted tests a planar contour that is not axis aligned
+func TestProjectionTilted(t *testing.T) {
+	// A square in the plane z = x.
+	square := []float32{0, 0, 0, 1, 0, 1, 1, 1, 1, 0, 1, 0}
+	r, err := projectionOf(t, 3, square, nil)
+	if err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	p := r.Projection
+	h := float32(math.Sqrt(0.5))
+	if math.Abs(float64(p.Normal[0]+h)) > 1e-6 || p.Normal[1] != 0 || math.Abs(float64(p.Normal[2]-h)) > 1e-6 {
+		t.Errorf("Expected normal (-0.707, 0, 0.707), got %v", p.Normal)
+	}
+	if p.NonPlanar || p.Deviation > 1e-6 {
+		t.Errorf("Expected planar input, got deviation %v", p.Deviation)
+	}
+
+	// A specified normal is reported as given, normalized.
+	r, err = projectionOf(t, 3, square, []float32{-2, 0, 2})
+	if err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	if r.Projection.Computed || math.Abs(float64(r.Projection.Normal[2]-h)) > 1e-6 {
+		t.Errorf("Expected the specified normal, got %+v", r.Projection)
+	}
+}
+
+// TestProjectionNonPlanar tests detection and strict rejection of non-planar input
+func TestProjectionNonPlanar(t *testing.T) {
+	bent := []float32{0, 0, 0, 10, 0, 0, 10, 10, 1, 0, 10, 0}
+
+	r, err := projectionOf(t, 3, bent, nil)
+	if err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	// The deviation is measured against the normal libtess2 picks, which is
+	// the normal of the largest triangle spanned by the input vertices.
+	if !r.Projection.NonPlanar || r.Projection.Deviation < 0.25 || r.Projection.Deviation > 1 {
+		t.Errorf("Expected non-planar input, got %+v", r.Projection)
+	}
+
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.SetPlanarityTolerance(0, true); err != nil {
+		t.Fatalf("SetPlanarityTolerance failed: %v", err)
+	}
+	tess.AddContour(3, bent)
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 3, nil); err == nil {
+		t.Error("Expected strict mode to reject non-planar input")
+	}
+	// The normal reported for rejected input has no -0 components either.
+	negZero := float32(math.Copysign(0, -1))
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 3, []float32{negZero, negZero, 1}); err == nil {
+		t.Error("Expected strict mode to reject non-planar input")
+	}
+	if p := tess.Projection(); hasNegativeZero(p.Normal) {
+		t.Errorf("Expected no -0 components, got %v", p.Normal)
+	}
+
+	// A larger tolerance accepts the input.
+	if err := tess.SetPlanarityTolerance(1, true); err != nil {
+		t.Fatalf("SetPlanarityTolerance failed: %v", err)
+	}
+	if _, _, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 3, nil); err != nil {
+		t.Errorf("Expected input within tolerance to be accepted: %v", err)
+	}
+	if p := tess.Projection(); p.NonPlanar {
+		t.Errorf("Expected planar within tolerance, got %+v", p)
+	}
+
+	if err := tess.SetPlanarityTolerance(-1, false); err == nil {
+		t.Error("Expected error for negative tolerance")
+	}
+}
@@ -0,0 +1,126 @@
+package tess
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Undef is the index libtess2 reports for vertices that do not correspond to
+// an input vertex, such as those created at self-intersections, and the
+// padding index of polygons with fewer than polySize vertices.
+const Undef = -1
+
+// Result holds the complete output of a tessellation.
+type Result struct {
+	// Vertices holds VertexSize coordinates per output vertex.
+	Vertices []float32 `json:"vertices"`
+	// Indices holds the elements, laid out as described by ElementType.
+	Indices []int `json:"indices"`
+	// VertexIndices maps each output vertex to the input vertex it came
+	// from, counting vertices over all contours in the order they were
+	// added, or Undef for vertices created by the tessellator.
+	VertexIndices []int       `json:"vertexIndices"`
+	VertexSize    int         `json:"vertexSize"`
+	ElementType   ElementType `json:"elementType"`
+	PolySize      int         `json:"polySize"`
+	// Projection describes the plane the input was projected onto.
+	Projection Projection `json:"projection"`
+	// EdgeFlags holds a mask per polygon when OptionEdgeFlags is enabled,
+	// and is nil otherwise. Bit k is set if the edge from the polygon's
+	// vertex k to vertex k+1, wrapping around, lies on the boundary of the
+	// output region rather than between two polygons.
+	EdgeFlags []uint32 `json:"edgeFlags"`
+	// ContourIDs lists for each polygon, when OptionContourAttribution is
+	// enabled, the input contours with a nonzero winding number around it,
+	// numbered in the order they were added. Windings holds each polygon's
+	// total winding number. Both are nil otherwise.
+	ContourIDs [][]int `json:"contourIDs"`
+	Windings   []int   `json:"windings"`
+}
+
+// TessellateResult tessellates like Tessellate but returns the full Result,
+// including the mapping from output to input vertices.
+func (t *Tessellator) TessellateResult(windingRule WindingRule, elementType ElementType, polySize, vertexSize int, normal []float32) (*Result, error) {
+	vertices, indices, err := t.Tessellate(windingRule, elementType, polySize, vertexSize, normal)
+	if err != nil {
+		return nil, err
+	}
+
+	vertexIndices := t.canonicalIndices
+	if !t.canonical {
+		vertexIndices = t.getVertexIndices()
+	}
+	if vertexIndices == nil {
+		vertexIndices = []int{}
+	}
+	return &Result{
+		Vertices:      vertices,
+		Indices:       indices,
+		VertexIndices: vertexIndices,
+		VertexSize:    vertexSize,
+		ElementType:   elementType,
+		PolySize:      polySize,
+		Projection:    t.projection,
+		EdgeFlags:     t.edgeMasks,
+		ContourIDs:    t.contourIDs,
+		Windings:      t.windings,
+	}, nil
+}
+
+// Clone returns a deep copy of r.
+func (r *Result) Clone() *Result {
+	c := *r
+	c.Vertices = slices.Clone(r.Vertices)
+	c.Indices = slices.Clone(r.Indices)
+	c.VertexIndices = slices.Clone(r.VertexIndices)
+	c.EdgeFlags = slices.Clone(r.EdgeFlags)
+	c.Windings = slices.Clone(r.Windings)
+	if r.ContourIDs != nil {
+		c.ContourIDs = make([][]int, len(r.ContourIDs))
+		for i, ids := range r.ContourIDs {
+			c.ContourIDs[i] = slices.Clone(ids)
+		}
+	}
+	return &c
+}
+
+// VertexCount returns the number of output vertices.
+func (r *Result) VertexCount() int {
+	if r.VertexSize == 0 {
+		return 0
+	}
+	return len(r.Vertices) / r.VertexSize
+}
+
+// Triangles returns the polygons of an ElementPolygons or
+// ElementConnectedPolygons result split into triangles, three vertex indices
+// per triangle. Polygons are convex, so they are split as fans.
+// elements[i] is the polygon that triangle i was cut from.
+func (r *Result) Triangles() (triangles []int, elements []int, err error) {
+	stride := r.PolySize
+	switch r.ElementType {
+	case ElementPolygons:
+	case ElementConnectedPolygons:
+		stride = 2 * r.PolySize
+	default:
+		return nil, nil, fmt.Errorf("result does not contain polygons: %s", r.ElementType)
+	}
+	if r.PolySize < 3 {
+		return nil, nil, fmt.Errorf("polySize must be at least 3, got %d", r.PolySize)
+	}
+
+	n := r.VertexCount()
+	for e := 0; e+stride <= len(r.Indices); e += stride {
+		poly := r.Indices[e : e+r.PolySize]
+		for k := 2; k < len(poly) && poly[k] != Undef; k++ {
+			for _, v := range []int{poly[0], poly[k-1], poly[k]} {
+				if v < 0 || v >= n {
+					return nil, nil, fmt.Errorf("polygon %d references vertex %d out of %d", e/stride, v, n)
+				}
+			}
+			triangles = append(triangles, poly[0], poly[k-1], poly[k])
+			elements = append(elements, e/stride)
+		}
+	}
+	return triangles, elements, nil
+}
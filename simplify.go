@@ -0,0 +1,273 @@
+package tess
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+// SimplifyMode selects the contour simplification algorithm.
+type SimplifyMode int
+
+const (
+	// SimplifyNone adds contours unchanged.
+	SimplifyNone SimplifyMode = iota
+	// SimplifyDouglasPeucker removes vertices that lie within tolerance of
+	// the simplified outline (Ramer-Douglas-Peucker).
+	SimplifyDouglasPeucker
+	// SimplifyVisvalingam repeatedly removes the vertex forming the
+	// smallest triangle with its neighbours while that area is below
+	// tolerance (Visvalingam-Whyatt).
+	SimplifyVisvalingam
+)
+
+// SetSimplify enables simplification of contours passed to AddContour.
+// For SimplifyDouglasPeucker tolerance is a distance, for SimplifyVisvalingam
+// it is an area. Contours are never reduced below three vertices, and the
+// vertex indices reported for the output still refer to the vertices as
+// passed to AddContour. Use SimplifyNone to disable simplification.
+func (t *Tessellator) SetSimplify(mode SimplifyMode, tolerance float32) error {
+	if t == nil || t.tess == nil {
+		return fmt.Errorf("tessellator is nil or deleted")
+	}
+	if mode < SimplifyNone || mode > SimplifyVisvalingam {
+		return fmt.Errorf("unknown simplify mode: %d", mode)
+	}
+	if tolerance < 0 || math.IsNaN(float64(tolerance)) {
+		return fmt.Errorf("simplify tolerance must not be negative, got %v", tolerance)
+	}
+	t.simplifyMode = mode
+	t.simplifyTolerance = tolerance
+	return nil
+}
+
+// Simplify reduces a closed contour of size-dimensional vertices with the
+// given mode and tolerance, as SetSimplify does for AddContour.
+func Simplify(size int, vertices []float32, mode SimplifyMode, tolerance float32) ([]float32, error) {
+	if size != 2 && size != 3 {
+		return nil, fmt.Errorf("size must be 2 or 3, got %d", size)
+	}
+	if len(vertices)%size != 0 {
+		return nil, fmt.Errorf("len(vertices)(%d) must be multiple of size (%d)", len(vertices), size)
+	}
+	if mode < SimplifyNone || mode > SimplifyVisvalingam {
+		return nil, fmt.Errorf("unknown simplify mode: %d", mode)
+	}
+	return pickVertices(size, vertices, simplifyContour(size, vertices, mode, float64(tolerance))), nil
+}
+
+// simplifyContour returns the indices of the vertices kept by simplification,
+// in contour order.
+func simplifyContour(size int, vertices []float32, mode SimplifyMode, tolerance float64) []int {
+	n := len(vertices) / size
+	if mode == SimplifyNone || n <= 3 || tolerance <= 0 {
+		keep := make([]int, n)
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+
+	pt := func(i int) [3]float64 {
+		var p [3]float64
+		for k := 0; k < size; k++ {
+			p[k] = float64(vertices[i*size+k])
+		}
+		return p
+	}
+	points := make([][3]float64, n)
+	for i := range points {
+		points[i] = pt(i)
+	}
+
+	if mode == SimplifyVisvalingam {
+		return visvalingam(points, tolerance)
+	}
+	return douglasPeucker(points, tolerance)
+}
+
+// pickVertices returns the vertices with the given indices.
+func pickVertices(size int, vertices []float32, keep []int) []float32 {
+	out := make([]float32, 0, len(keep)*size)
+	for _, i := range keep {
+		out = append(out, vertices[i*size:(i+1)*size]...)
+	}
+	return out
+}
+
+func sub3(a, b [3]float64) [3]float64 { return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func dot3(a, b [3]float64) float64    { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func cross3(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+// segmentDistance returns the distance from p to segment ab.
+func segmentDistance(p, a, b [3]float64) float64 {
+	ab, ap := sub3(b, a), sub3(p, a)
+	l2 := dot3(ab, ab)
+	if l2 == 0 {
+		return math.Sqrt(dot3(ap, ap))
+	}
+	s := math.Max(0, math.Min(1, dot3(ap, ab)/l2))
+	d := sub3(ap, [3]float64{ab[0] * s, ab[1] * s, ab[2] * s})
+	return math.Sqrt(dot3(d, d))
+}
+
+// triangleArea3 returns the area of triangle abc.
+func triangleArea3(a, b, c [3]float64) float64 {
+	n := cross3(sub3(b, a), sub3(c, a))
+	return math.Sqrt(dot3(n, n)) / 2
+}
+
+// douglasPeucker simplifies a closed ring. The ring is split at vertex 0 and
+// the vertex farthest from it, and each half is simplified as a polyline.
+func douglasPeucker(points [][3]float64, tolerance float64) []int {
+	n := len(points)
+	far, farDist := 0, -1.0
+	for i := 1; i < n; i++ {
+		d := sub3(points[i], points[0])
+		if l := dot3(d, d); l > farDist {
+			far, farDist = i, l
+		}
+	}
+
+	keep := make([]bool, n)
+	keep[0], keep[far] = true, true
+
+	var simplify func(first, last int)
+	simplify = func(first, last int) {
+		// last may wrap past the end of the ring.
+		best, bestDist := -1, tolerance
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(points[i%n], points[first%n], points[last%n]); d > bestDist {
+				best, bestDist = i, d
+			}
+		}
+		if best < 0 {
+			return
+		}
+		keep[best%n] = true
+		simplify(first, best)
+		simplify(best, last)
+	}
+	simplify(0, far)
+	simplify(far, n)
+
+	out := make([]int, 0, n)
+	for i, k := range keep {
+		if k {
+			out = append(out, i)
+		}
+	}
+	if len(out) < 3 {
+		return keepLargest(points, out)
+	}
+	return out
+}
+
+// keepLargest adds back the vertex farthest from the kept ones so that a
+// ring reduced to two vertices keeps its area.
+func keepLargest(points [][3]float64, kept []int) []int {
+	a, b := points[kept[0]], points[kept[len(kept)-1]]
+	best, bestArea := -1, -1.0
+	for i, p := range points {
+		if i == kept[0] || i == kept[len(kept)-1] {
+			continue
+		}
+		if area := triangleArea3(a, b, p); area > bestArea {
+			best, bestArea = i, area
+		}
+	}
+	out := append([]int{}, kept...)
+	for i, k := range out {
+		if best < k {
+			return append(out[:i], append([]int{best}, out[i:]...)...)
+		}
+	}
+	return append(out, best)
+}
+
+// vwVertex is a ring vertex in the Visvalingam-Whyatt queue.
+type vwVertex struct {
+	prev, next int
+	area       float64
+	index      int // position in the heap
+}
+
+type vwQueue struct {
+	vertices []vwVertex
+	order    []int
+}
+
+func (q *vwQueue) Len() int { return len(q.order) }
+func (q *vwQueue) Less(i, j int) bool {
+	a, b := q.vertices[q.order[i]].area, q.vertices[q.order[j]].area
+	if a == b {
+		return q.order[i] < q.order[j]
+	}
+	return a < b
+}
+func (q *vwQueue) Swap(i, j int) {
+	q.order[i], q.order[j] = q.order[j], q.order[i]
+	q.vertices[q.order[i]].index = i
+	q.vertices[q.order[j]].index = j
+}
+func (q *vwQueue) Push(x any) {
+	v := x.(int)
+	q.vertices[v].index = len(q.order)
+	q.order = append(q.order, v)
+}
+func (q *vwQueue) Pop() any {
+	v := q.order[len(q.order)-1]
+	q.order = q.order[:len(q.order)-1]
+	q.vertices[v].index = -1
+	return v
+}
+
+// visvalingam simplifies a closed ring by repeatedly removing the vertex with
+// the smallest effective area.
+func visvalingam(points [][3]float64, tolerance float64) []int {
+	n := len(points)
+	q := &vwQueue{vertices: make([]vwVertex, n)}
+	area := func(i int) float64 {
+		v := q.vertices[i]
+		return triangleArea3(points[v.prev], points[i], points[v.next])
+	}
+	for i := range q.vertices {
+		q.vertices[i].prev = (i + n - 1) % n
+		q.vertices[i].next = (i + 1) % n
+	}
+	for i := range q.vertices {
+		q.vertices[i].area = area(i)
+		heap.Push(q, i)
+	}
+
+	// maxArea keeps effective areas monotonic, so that removing a vertex
+	// never makes a neighbour cheaper to remove than the vertex itself.
+	remaining, maxArea := n, 0.0
+	for remaining > 3 {
+		i := q.order[0]
+		if q.vertices[i].area >= tolerance {
+			break
+		}
+		heap.Pop(q)
+		maxArea = math.Max(maxArea, q.vertices[i].area)
+		remaining--
+
+		prev, next := q.vertices[i].prev, q.vertices[i].next
+		q.vertices[prev].next = next
+		q.vertices[next].prev = prev
+		for _, v := range []int{prev, next} {
+			q.vertices[v].area = math.Max(area(v), maxArea)
+			heap.Fix(q, q.vertices[v].index)
+		}
+	}
+
+	out := make([]int, 0, remaining)
+	for i := range q.vertices {
+		if q.vertices[i].index >= 0 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// noisyCircle returns a closed 2D circle of n vertices with small radial noise.
+func noisyCircle(n int, r, noise float64) []float32 {
+	out := make([]float32, 0, 2*n)
+	for i := 0; i < n; i++ {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		rr := r + noise*math.Sin(float64(i)*7.3)
+		out = append(out, float32(rr*math.Cos(a)), float32(rr*math.Sin(a)))
+	}
+	return out
+}
+
+// TestSimplify tests both simplification modes on a dense contour
+func TestSimplify(t *testing.T) {
+	circle := noisyCircle(2000, 10, 0.01)
+
+	tests := []struct {
+		name      string
+		mode      SimplifyMode
+		tolerance float32
+	}{
+		{"douglas-peucker", SimplifyDouglasPeucker, 0.05},
+		{"visvalingam", SimplifyVisvalingam, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Simplify(2, circle, tt.mode, tt.tolerance)
+			if err != nil {
+				t.Fatalf("Simplify failed: %v", err)
+			}
+			if n := len(out) / 2; n >= 200 || n < 3 {
+				t.Errorf("Expected a strong reduction of 2000 vertices, got %d", n)
+			}
+			if a, b := ringArea(out), ringArea(circle); math.Abs(a-b)/b > 0.01 {
+				t.Errorf("Expected area %v to be preserved, got %v", b, a)
+			}
+		})
+	}
+
+	// Tiny contours are never reduced below a triangle.
+	for _, mode := range []SimplifyMode{SimplifyDouglasPeucker, SimplifyVisvalingam} {
+		quad := []float32{0, 0, 1, 0, 1, 0.001, 0, 0.001}
+		out, err := Simplify(2, quad, mode, 100)
+		if err != nil {
+			t.Fatalf("Simplify failed: %v", err)
+		}
+		if len(out) != 6 {
+			t.Errorf("Mode %d: expected 3 vertices, got %d", mode, len(out)/2)
+		}
+	}
+
+	// 3D contours take all coordinates into account.
+	zigzag := []float32{0, 0, 0, 1, 0, 1, 2, 0, 0, 2, 2, 0, 0, 2, 0}
+	out, err := Simplify(3, zigzag, SimplifyDouglasPeucker, 0.5)
+	if err != nil {
+		t.Fatalf("Simplify failed: %v", err)
+	}
+	if len(out) != len(zigzag) {
+		t.Errorf("Expected the out-of-plane vertex to be kept, got %v", out)
+	}
+
+	if _, err := Simplify(2, circle, SimplifyMode(42), 1); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
+
+// TestTessellatorSimplify tests simplification of Tessellator input
+func TestTessellatorSimplify(t *testing.T) {
+	circle := noisyCircle(2000, 10, 0.01)
+
+	triangles := func(mode SimplifyMode) ([]float32, []int, []int) {
+		tess := NewTessellator()
+		defer tess.Delete()
+		if err := tess.SetSimplify(mode, 0.05); err != nil {
+			t.Fatalf("SetSimplify failed: %v", err)
+		}
+		// The square is unaffected by simplification and is added first, so
+		// that the circle's source indices are offset.
+		if err := tess.AddContour(2, []float32{20, 0, 21, 0, 21, 1, 20, 1}); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+		if err := tess.AddContour(2, circle); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+		vertices, indices, err := tess.Tessellate(WindingOdd, ElementPolygons, 3, 2, nil)
+		if err != nil {
+			t.Fatalf("Tessellate failed: %v", err)
+		}
+		return vertices, indices, tess.getVertexIndices()
+	}
+
+	_, full, _ := triangles(SimplifyNone)
+	vertices, reduced, sources := triangles(SimplifyDouglasPeucker)
+	if len(reduced) >= len(full)/5 {
+		t.Errorf("Expected far fewer triangles, got %d of %d", len(reduced)/3, len(full)/3)
+	}
+
+	// Output vertices map back to the original input vertices.
+	for i, src := range sources {
+		if src < 4 {
+			continue
+		}
+		j := src - 4
+		if vertices[2*i] != circle[2*j] || vertices[2*i+1] != circle[2*j+1] {
+			t.Errorf("Vertex %d maps to input %d at a different position", i, src)
+		}
+	}
+
+	tess := NewTessellator()
+	defer tess.Delete()
+	if err := tess.SetSimplify(SimplifyVisvalingam, -1); err == nil {
+		t.Error("Expected error for negative tolerance")
+	}
+}
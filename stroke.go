@@ -0,0 +1,300 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+)
+
+// LineJoin selects how corners between segments are joined.
+type LineJoin int
+
+const (
+	JoinMiter LineJoin = iota
+	JoinRound
+	JoinBevel
+)
+
+// LineCap selects how the ends of open polylines are drawn.
+type LineCap int
+
+const (
+	CapButt LineCap = iota
+	CapRound
+	CapSquare
+)
+
+// Polyline is a 2D polyline given as x, y pairs. A closed polyline has an
+// implicit segment from its last point back to the first.
+type Polyline struct {
+	Points []float32
+	Closed bool
+}
+
+// StrokeOptions configures Stroke and StrokeContours.
+type StrokeOptions struct {
+	// Width is the full stroke width.
+	Width float32
+	Join  LineJoin
+	Cap   LineCap
+	// MiterLimit is the maximum ratio of miter length to stroke width, as in
+	// SVG. Miter joins exceeding it are drawn as bevels. Defaults to 4.
+	MiterLimit float32
+	// Tolerance is the maximum distance between round joins or caps and
+	// their polygonal approximation. Defaults to Width/50.
+	Tolerance float32
+}
+
+// Stroke outlines polylines with StrokeOptions and tessellates the outline
+// into triangles. Overlapping parts of the stroke, including self-overlaps,
+// are filled once using WindingNonZero.
+// Returns 2D vertices and three indices per triangle.
+func Stroke(lines []Polyline, opts StrokeOptions) (vertices []float32, indices []int, err error) {
+	contours, err := StrokeContours(lines, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(contours) == 0 {
+		return []float32{}, []int{}, nil
+	}
+
+	t := NewTessellator()
+	if t == nil {
+		return nil, nil, fmt.Errorf("failed to create tessellator")
+	}
+	defer t.Delete()
+
+	for _, c := range contours {
+		if err := t.AddContour(2, c); err != nil {
+			return nil, nil, err
+		}
+	}
+	return t.Tessellate(WindingNonZero, ElementPolygons, 3, 2, []float32{0, 0, 1})
+}
+
+// StrokeContours converts polylines into closed fill contours that cover the
+// stroke. Every contour is counter-clockwise, so the union of the stroke is
+// obtained by tessellating them with WindingNonZero or WindingPositive.
+func StrokeContours(lines []Polyline, opts StrokeOptions) ([][]float32, error) {
+	if !(opts.Width > 0) {
+		return nil, fmt.Errorf("stroke width must be positive, got %v", opts.Width)
+	}
+	if opts.MiterLimit <= 0 {
+		opts.MiterLimit = 4
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = opts.Width / 50
+	}
+
+	s := stroker{opts: opts, h: float64(opts.Width) / 2}
+	for i, line := range lines {
+		if len(line.Points)%2 != 0 {
+			return nil, fmt.Errorf("polyline %d has an odd number of coordinates", i)
+		}
+		s.polyline(line)
+	}
+	return s.contours, nil
+}
+
+// stroker accumulates the pieces of a stroke outline.
+type stroker struct {
+	opts     StrokeOptions
+	h        float64
+	contours [][]float32
+}
+
+type vec2 struct{ x, y float64 }
+
+func (a vec2) add(b vec2) vec2          { return vec2{a.x + b.x, a.y + b.y} }
+func (a vec2) sub(b vec2) vec2          { return vec2{a.x - b.x, a.y - b.y} }
+func (a vec2) scale(s float64) vec2     { return vec2{a.x * s, a.y * s} }
+func (a vec2) dot(b vec2) float64       { return a.x*b.x + a.y*b.y }
+func (a vec2) cross(b vec2) float64     { return a.x*b.y - a.y*b.x }
+func (a vec2) length() float64          { return math.Hypot(a.x, a.y) }
+func (a vec2) perp() vec2               { return vec2{-a.y, a.x} }
+func (a vec2) angle() float64           { return math.Atan2(a.y, a.x) }
+func (a vec2) normalized() vec2         { return a.scale(1 / a.length()) }
+func (a vec2) equal(b vec2) bool        { return a.x == b.x && a.y == b.y }
+func pointAt(p vec2, a, r float64) vec2 { return vec2{p.x + r*math.Cos(a), p.y + r*math.Sin(a)} }
+
+// polylinePoints converts flat coordinates to points, dropping consecutive
+// duplicates (and the closing duplicate of closed polylines).
+func polylinePoints(coords []float32, closed bool) []vec2 {
+	pts := make([]vec2, 0, len(coords)/2)
+	for i := 0; i+1 < len(coords); i += 2 {
+		p := vec2{float64(coords[i]), float64(coords[i+1])}
+		if n := len(pts); n > 0 && pts[n-1].equal(p) {
+			continue
+		}
+		pts = append(pts, p)
+	}
+	if closed {
+		for len(pts) > 1 && pts[len(pts)-1].equal(pts[0]) {
+			pts = pts[:len(pts)-1]
+		}
+	}
+	return pts
+}
+
+func (s *stroker) polyline(line Polyline) {
+	pts := polylinePoints(line.Points, line.Closed)
+	switch {
+	case len(pts) == 0:
+		return
+	case len(pts) == 1:
+		s.dot(pts[0])
+		return
+	}
+
+	n := len(pts)
+	segments := n - 1
+	if line.Closed {
+		segments = n
+	}
+	for i := 0; i < segments; i++ {
+		s.segment(pts[i], pts[(i+1)%n])
+	}
+
+	if line.Closed {
+		for i := 0; i < n; i++ {
+			s.join(pts[(i+n-1)%n], pts[i], pts[(i+1)%n])
+		}
+		return
+	}
+	for i := 1; i < n-1; i++ {
+		s.join(pts[i-1], pts[i], pts[i+1])
+	}
+	s.cap(pts[0], pts[0].sub(pts[1]).normalized())
+	s.cap(pts[n-1], pts[n-1].sub(pts[n-2]).normalized())
+}
+
+// segment emits the rectangle covering one segment.
+func (s *stroker) segment(p, q vec2) {
+	n := q.sub(p).normalized().perp().scale(s.h)
+	s.emit([]vec2{p.sub(n), q.sub(n), q.add(n), p.add(n)})
+}
+
+// join emits the piece filling the outer side of the corner at b.
+func (s *stroker) join(a, b, c vec2) {
+	d1 := b.sub(a).normalized()
+	d2 := c.sub(b).normalized()
+	cross := d1.cross(d2)
+	cosTurn := d1.dot(d2)
+	if math.Abs(cross) < 1e-12 && cosTurn > 0 {
+		return // Straight continuation.
+	}
+
+	// The outer side is to the right of a left turn and vice versa.
+	side := -1.0
+	if cross < 0 {
+		side = 1
+	}
+	n1 := d1.perp().scale(side * s.h)
+	n2 := d2.perp().scale(side * s.h)
+	p1, p2 := b.add(n1), b.add(n2)
+
+	join := s.opts.Join
+	if join == JoinMiter {
+		// Miter length over stroke width is 1/cos(turn/2).
+		cosHalf := math.Sqrt(math.Max(0, (1+cosTurn)/2))
+		if cosHalf == 0 || 1/cosHalf > float64(s.opts.MiterLimit) {
+			join = JoinBevel
+		}
+	}
+
+	switch join {
+	case JoinMiter:
+		tip := b.add(n1.add(n2).scale(1 / (1 + cosTurn)))
+		s.emit([]vec2{b, p1, tip, p2})
+	case JoinRound:
+		s.emit(append([]vec2{b}, s.arc(b, n1.angle(), n2.angle(), -side)...))
+	default:
+		s.emit([]vec2{b, p1, p2})
+	}
+}
+
+// cap emits the end cap at p for a line leaving p in direction dir.
+func (s *stroker) cap(p, dir vec2) {
+	n := dir.perp().scale(s.h)
+	switch s.opts.Cap {
+	case CapSquare:
+		ext := dir.scale(s.h)
+		s.emit([]vec2{p.add(n), p.sub(n), p.sub(n).add(ext), p.add(n).add(ext)})
+	case CapRound:
+		// Half circle from the left side over the tip to the right side.
+		a := n.angle()
+		s.emit(append([]vec2{p}, s.arc(p, a, a+math.Pi, -1)...))
+	}
+}
+
+// dot emits the cap shape of a zero-length polyline.
+func (s *stroker) dot(p vec2) {
+	switch s.opts.Cap {
+	case CapSquare:
+		h := s.h
+		s.emit([]vec2{{p.x - h, p.y - h}, {p.x + h, p.y - h}, {p.x + h, p.y + h}, {p.x - h, p.y + h}})
+	case CapRound:
+		s.emit(s.arc(p, 0, 2*math.Pi, 1)[1:])
+	}
+}
+
+// arc returns points on the circle of radius h around c from angle a0 to a1,
+// sweeping clockwise when dir < 0 and counter-clockwise otherwise.
+func (s *stroker) arc(c vec2, a0, a1, dir float64) []vec2 {
+	return arcPoints(c, s.h, a0, a1, dir, float64(s.opts.Tolerance))
+}
+
+// arcPoints returns points on a circle from angle a0 to a1 inclusive, with
+// the chord error bounded by tolerance. dir selects the sweep direction:
+// counter-clockwise when positive, clockwise when negative.
+func arcPoints(c vec2, r, a0, a1, dir, tolerance float64) []vec2 {
+	sweep := a1 - a0
+	if dir >= 0 {
+		for sweep < 0 {
+			sweep += 2 * math.Pi
+		}
+	} else {
+		for sweep > 0 {
+			sweep -= 2 * math.Pi
+		}
+	}
+
+	step := math.Pi / 2
+	if tolerance < r {
+		step = 2 * math.Acos(1-tolerance/r)
+	}
+	n := int(math.Ceil(math.Abs(sweep) / step))
+	if n < 1 {
+		n = 1
+	}
+
+	pts := make([]vec2, 0, n+1)
+	for i := 0; i <= n; i++ {
+		pts = append(pts, pointAt(c, a0+sweep*float64(i)/float64(n), r))
+	}
+	return pts
+}
+
+// emit appends a piece as a counter-clockwise contour.
+func (s *stroker) emit(pts []vec2) {
+	if len(pts) < 3 {
+		return
+	}
+	area := 0.0
+	for i := range pts {
+		area += pts[i].cross(pts[(i+1)%len(pts)])
+	}
+	if area == 0 {
+		return
+	}
+	out := make([]float32, 0, 2*len(pts))
+	if area > 0 {
+		for _, p := range pts {
+			out = append(out, float32(p.x), float32(p.y))
+		}
+	} else {
+		for i := len(pts) - 1; i >= 0; i-- {
+			out = append(out, float32(pts[i].x), float32(pts[i].y))
+		}
+	}
+	s.contours = append(s.contours, out)
+}
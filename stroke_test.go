@@ -0,0 +1,144 @@
+package tess
+
+import (
+	"math"
+	"testing"
+)
+
+// triangleArea returns the total signed area of 2D triangles.
+func triangleArea(vertices []float32, indices []int) float64 {
+	area := 0.0
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := indices[i], indices[i+1], indices[i+2]
+		ax, ay := float64(vertices[2*a]), float64(vertices[2*a+1])
+		bx, by := float64(vertices[2*b]), float64(vertices[2*b+1])
+		cx, cy := float64(vertices[2*c]), float64(vertices[2*c+1])
+		area += ((bx-ax)*(cy-ay) - (cx-ax)*(by-ay)) / 2
+	}
+	return area
+}
+
+// TestStrokeCaps tests the area covered by each cap style
+func TestStrokeCaps(t *testing.T) {
+	line := []Polyline{{Points: []float32{0, 0, 10, 0}}}
+
+	tests := []struct {
+		name string
+		cap  LineCap
+		area float64
+		tol  float64
+	}{
+		{"butt", CapButt, 20, 1e-4},
+		{"square", CapSquare, 24, 1e-4},
+		{"round", CapRound, 20 + math.Pi, 0.02},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vertices, indices, err := Stroke(line, StrokeOptions{Width: 2, Cap: tt.cap, Tolerance: 0.001})
+			if err != nil {
+				t.Fatalf("Stroke failed: %v", err)
+			}
+			if area := triangleArea(vertices, indices); math.Abs(area-tt.area) > tt.tol {
+				t.Errorf("Expected area %v, got %v", tt.area, area)
+			}
+		})
+	}
+}
+
+// TestStrokeJoins tests the area covered by each join style on a closed square
+func TestStrokeJoins(t *testing.T) {
+	square := []Polyline{{Points: []float32{0, 0, 10, 0, 10, 10, 0, 10}, Closed: true}}
+
+	tests := []struct {
+		name string
+		opts StrokeOptions
+		area float64
+		tol  float64
+	}{
+		{"miter", StrokeOptions{Width: 2, Join: JoinMiter}, 80, 1e-3},
+		{"bevel", StrokeOptions{Width: 2, Join: JoinBevel}, 78, 1e-3},
+		{"round", StrokeOptions{Width: 2, Join: JoinRound, Tolerance: 0.001}, 76 + math.Pi, 0.02},
+		// A right angle needs a miter ratio of sqrt(2), so this falls back to bevel.
+		{"miter limit", StrokeOptions{Width: 2, Join: JoinMiter, MiterLimit: 1.2}, 78, 1e-3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vertices, indices, err := Stroke(square, tt.opts)
+			if err != nil {
+				t.Fatalf("Stroke failed: %v", err)
+			}
+			if area := triangleArea(vertices, indices); math.Abs(area-tt.area) > tt.tol {
+				t.Errorf("Expected area %v, got %v", tt.area, area)
+			}
+		})
+	}
+}
+
+// TestStrokeSelfOverlap tests that overlapping strokes are filled only once
+func TestStrokeSelfOverlap(t *testing.T) {
+	cross := []Polyline{
+		{Points: []float32{-5, 0, 5, 0}},
+		{Points: []float32{0, -5, 0, 5}},
+	}
+	vertices, indices, err := Stroke(cross, StrokeOptions{Width: 2})
+	if err != nil {
+		t.Fatalf("Stroke failed: %v", err)
+	}
+	// Two 10x2 bars overlapping in a 2x2 square.
+	if area := triangleArea(vertices, indices); math.Abs(area-36) > 1e-3 {
+		t.Errorf("Expected area 36, got %v", area)
+	}
+
+	// A polyline that doubles back on itself.
+	zigzag := []Polyline{{Points: []float32{0, 0, 10, 0, 0, 0}}}
+	vertices, indices, err = Stroke(zigzag, StrokeOptions{Width: 2, Join: JoinRound, Tolerance: 0.001})
+	if err != nil {
+		t.Fatalf("Stroke failed: %v", err)
+	}
+	if area := triangleArea(vertices, indices); math.Abs(area-(20+math.Pi/2)) > 0.02 {
+		t.Errorf("Expected area %v, got %v", 20+math.Pi/2, area)
+	}
+}
+
+// TestStrokeContours tests contour generation and orientation
+func TestStrokeContours(t *testing.T) {
+	lines := []Polyline{
+		{Points: []float32{0, 0, 5, 0, 5, 5, 5, 5}},
+		{Points: []float32{20, 20}},
+	}
+	contours, err := StrokeContours(lines, StrokeOptions{Width: 1, Cap: CapRound, Join: JoinRound})
+	if err != nil {
+		t.Fatalf("StrokeContours failed: %v", err)
+	}
+	if len(contours) == 0 {
+		t.Fatal("Expected contours")
+	}
+	for i, c := range contours {
+		area := 0.0
+		for j := 0; j < len(c); j += 2 {
+			k := (j + 2) % len(c)
+			area += float64(c[j]*c[k+1] - c[k]*c[j+1])
+		}
+		if area <= 0 {
+			t.Errorf("Contour %d is not counter-clockwise (area %v)", i, area)
+		}
+	}
+}
+
+// TestStrokeInvalidInput tests error handling
+func TestStrokeInvalidInput(t *testing.T) {
+	line := []Polyline{{Points: []float32{0, 0, 1, 0}}}
+	if _, _, err := Stroke(line, StrokeOptions{}); err == nil {
+		t.Error("Expected error for zero width")
+	}
+	if _, _, err := Stroke([]Polyline{{Points: []float32{0, 0, 1}}}, StrokeOptions{Width: 1}); err == nil {
+		t.Error("Expected error for odd coordinate count")
+	}
+
+	vertices, indices, err := Stroke(nil, StrokeOptions{Width: 1})
+	if err != nil || len(vertices) != 0 || len(indices) != 0 {
+		t.Errorf("Expected empty output for no polylines, got %v, %v, %v", vertices, indices, err)
+	}
+}
@@ -46,8 +46,23 @@ type Option int
 const (
 	OptionConstrainedDelaunay Option = C.TESS_CONSTRAINED_DELAUNAY_TRIANGULATION
 	OptionReverseContours     Option = C.TESS_REVERSE_CONTOURS
+	// OptionEdgeFlags records in Result.EdgeFlags which polygon edges lie
+	// on the boundary of the output region. It is handled in Go rather than
+	// passed to libtess2.
+	OptionEdgeFlags Option = 1 << 16
+	// OptionContourAttribution records in Result.ContourIDs and
+	// Result.Windings which input contours enclose each output polygon. It
+	// must be enabled before the contours are added and is handled in Go.
+	OptionContourAttribution Option = 1 << 17
+	// OptionCanonicalOrder reorders every output with Result.Canonicalize.
+	// It is handled in Go.
+	OptionCanonicalOrder Option = 1 << 18
 )
 
+// options lists every Option. Job.Run sets each of them, jobKey hashes them
+// and JSON decoding accepts their names, so a new option is added here.
+var options = []Option{OptionConstrainedDelaunay, OptionReverseContours, OptionEdgeFlags, OptionContourAttribution, OptionCanonicalOrder}
+
 // Status represents the tessellation status.
 type Status int
 
@@ -60,17 +75,64 @@ const (
 // Tessellator represents a tessellation context.
 type Tessellator struct {
 	tess *C.TESStesselator
+
+	simplifyMode      SimplifyMode
+	simplifyTolerance float32
+
+	// cleanupEpsilon enables contour cleanup when positive. welder holds the
+	// vertices added since the last tessellation, to weld later ones onto.
+	cleanupEpsilon float32
+	welder         *welder
+	cleanupReport  CleanupReport
+
+	// inputCount is the number of vertices passed to AddContour since the
+	// last tessellation. sourceIndex maps vertices added to libtess2 back to
+	// those input vertices; it is nil while no vertex has been dropped.
+	inputCount  int
+	sourceIndex []int
+
+	planarityTolerance float32
+	strictPlanarity    bool
+	projection         Projection
+
+	// edgeFlags is set by OptionEdgeFlags. edgeMasks holds the masks of the
+	// last tessellation.
+	edgeFlags bool
+	edgeMasks []uint32
+
+	// attribution is set by OptionContourAttribution. contourCount is the
+	// number of contours added since the last tessellation and
+	// attribContours holds their copies while attribution is enabled.
+	// contourIDs and windings hold the attribution of the last tessellation.
+	attribution     bool
+	reverseContours bool
+	contourCount    int
+	attribContours  []attribContour
+	contourIDs      [][]int
+	windings        []int
+
+	// canonical is set by OptionCanonicalOrder. canonicalIndices holds the
+	// reordered vertex indices of the last tessellation.
+	canonical        bool
+	canonicalIndices []int
+
+	observer Observer
+	memory   memoryAccount
+	// outOfMemory is set when the last tessellation was aborted by the
+	// memory limit. The Tessellator has been recovered, but the failure is
+	// reported until the next tessellation or contour.
+	outOfMemory bool
 }
 
 // NewTessellator creates a new tessellator instance.
 // Returns nil if allocation fails.
 func NewTessellator() *Tessellator {
-	tess := C.tessNewTess(nil)
+	tess, memory := newAccountedTess()
 	if tess == nil {
 		return nil
 	}
 
-	t := &Tessellator{tess: tess}
+	t := &Tessellator{tess: tess, memory: memory}
 	runtime.SetFinalizer(t, (*Tessellator).Delete)
 	return t
 }
@@ -80,6 +142,7 @@ func (t *Tessellator) Delete() {
 	if t.tess != nil {
 		C.tessDeleteTess(t.tess)
 		t.tess = nil
+		t.memory.free()
 	}
 }
 
@@ -90,6 +153,7 @@ func (t *Tessellator) AddContour(size int, vertices []float32) error {
 	if t == nil || t.tess == nil {
 		return fmt.Errorf("tessellator is nil or deleted")
 	}
+	t.outOfMemory = false
 
 	if size != 2 && size != 3 {
 		return fmt.Errorf("size must be 2 or 3, got %d", size)
@@ -101,6 +165,69 @@ func (t *Tessellator) AddContour(size int, vertices []float32) error {
 		return fmt.Errorf("len(vertices)(%d) must be multiple of size (%d)", len(vertices), size)
 	}
 
+	if t.inputCount == 0 {
+		// The previous tessellation's mapping is kept until new input arrives.
+		t.sourceIndex = nil
+		t.contourCount = 0
+		t.attribContours = nil
+		t.cleanupReport = CleanupReport{}
+		t.welder = nil
+	}
+	base := t.inputCount
+	t.inputCount += len(vertices) / size
+
+	// keep lists the vertices passed on to libtess2, or is nil if all are.
+	var keep []int
+	dropped := false
+	if t.cleanupEpsilon > 0 {
+		if t.welder == nil {
+			t.welder = newWelder(float64(t.cleanupEpsilon))
+		}
+		var kept []int
+		vertices, kept = t.welder.clean(size, vertices, &t.cleanupReport)
+		if len(kept) < 3 {
+			t.cleanupReport.DroppedContours = append(t.cleanupReport.DroppedContours, t.contourCount)
+			keep, dropped = []int{}, true
+		} else if len(kept) < t.inputCount-base {
+			keep = kept
+		}
+	}
+	if t.simplifyMode != SimplifyNone && !dropped {
+		kept := simplifyContour(size, vertices, t.simplifyMode, float64(t.simplifyTolerance))
+		if len(kept) < len(vertices)/size {
+			vertices = pickVertices(size, vertices, kept)
+			if keep != nil {
+				for i, k := range kept {
+					kept[i] = keep[k]
+				}
+			}
+			keep = kept
+		}
+	}
+	if keep != nil && t.sourceIndex == nil {
+		t.sourceIndex = make([]int, base)
+		for i := range t.sourceIndex {
+			t.sourceIndex[i] = i
+		}
+	}
+	if keep != nil {
+		for _, i := range keep {
+			t.sourceIndex = append(t.sourceIndex, base+i)
+		}
+	} else if t.sourceIndex != nil {
+		for i := 0; i < len(vertices)/size; i++ {
+			t.sourceIndex = append(t.sourceIndex, base+i)
+		}
+	}
+
+	t.contourCount++
+	if t.attribution {
+		t.recordContour(size, vertices)
+	}
+	if dropped {
+		return nil
+	}
+
 	// stride := uintptr(unsafe.Pointer(&vertices[size])) - uintptr(unsafe.Pointer(&vertices[0]))
 	stride := 4 * size
 	// fmt.Printf("size:%d len:%d stride:%d\n", size, len(vertices)/2, stride)
@@ -126,6 +253,20 @@ func (t *Tessellator) SetOption(option Option, enabled bool) error {
 		return fmt.Errorf("tessellator is nil or deleted")
 	}
 
+	switch option {
+	case OptionEdgeFlags:
+		t.edgeFlags = enabled
+		return nil
+	case OptionContourAttribution:
+		t.attribution = enabled
+		return nil
+	case OptionCanonicalOrder:
+		t.canonical = enabled
+		return nil
+	case OptionReverseContours:
+		t.reverseContours = enabled
+	}
+
 	value := 0
 	if enabled {
 		value = 1
@@ -149,6 +290,10 @@ func (t *Tessellator) Tessellate(windingRule WindingRule, elementType ElementTyp
 	if t == nil || t.tess == nil {
 		return nil, nil, fmt.Errorf("tessellator is nil or deleted")
 	}
+	if t.observer != nil {
+		e := t.startEvent(windingRule, elementType)
+		defer func() { t.finishEvent(e, err) }()
+	}
 
 	// Perform tessellation
 	err = t.internalTessellate(windingRule, elementType, polySize, vertexSize, normal)
@@ -179,17 +324,35 @@ func (t *Tessellator) Tessellate(windingRule WindingRule, elementType ElementTyp
 	}
 
 	// Get indices based on element type
+	t.edgeMasks = nil
+	t.canonicalIndices = nil
 	switch elementType {
 	case ElementPolygons:
-		indices = t.getElementsWithSize(elementType, polySize)
+		if t.edgeFlags {
+			// libtess2 was asked for connected polygons; the neighbours
+			// give the flags and are then dropped.
+			indices, t.edgeMasks = connectedEdgeFlags(t.getElementsWithSize(ElementConnectedPolygons, polySize), polySize, false)
+		} else {
+			indices = t.getElementsWithSize(elementType, polySize)
+		}
 	case ElementConnectedPolygons:
 		indices = t.getElementsWithSize(elementType, polySize)
+		if t.edgeFlags {
+			_, t.edgeMasks = connectedEdgeFlags(indices, polySize, true)
+		}
 	case ElementBoundaryContours:
 		indices = t.getElementsWithSize(elementType, polySize)
 	default:
 		return nil, nil, fmt.Errorf("unsupported element type: %v", elementType)
 	}
 
+	t.contourIDs, t.windings = nil, nil
+	if t.attribution && elementType != ElementBoundaryContours {
+		if err := t.attribute(vertices, vertexSize, indices, elementType, polySize); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if indices == nil {
 		status := t.getStatus()
 		if status != StatusOK {
@@ -198,6 +361,24 @@ func (t *Tessellator) Tessellate(windingRule WindingRule, elementType ElementTyp
 		indices = []int{}
 	}
 
+	if t.canonical {
+		r := &Result{
+			Vertices:      vertices,
+			Indices:       indices,
+			VertexIndices: t.getVertexIndices(),
+			VertexSize:    vertexSize,
+			ElementType:   elementType,
+			PolySize:      polySize,
+			EdgeFlags:     t.edgeMasks,
+			ContourIDs:    t.contourIDs,
+			Windings:      t.windings,
+		}
+		r.Canonicalize()
+		vertices, indices = r.Vertices, r.Indices
+		t.canonicalIndices = r.VertexIndices
+		t.edgeMasks, t.contourIDs, t.windings = r.EdgeFlags, r.ContourIDs, r.Windings
+	}
+
 	return vertices, indices, nil
 }
 
@@ -225,12 +406,36 @@ func (t *Tessellator) internalTessellate(windingRule WindingRule, elementType El
 		normalPtr = &normalArray[0]
 	}
 
+	if t.edgeFlags && elementType != ElementBoundaryContours && polySize > maxEdgeFlagsPolySize {
+		return fmt.Errorf("polySize must be at most %d with OptionEdgeFlags, got %d", maxEdgeFlagsPolySize, polySize)
+	}
+	if t.attribution && len(t.attribContours) != t.contourCount {
+		return fmt.Errorf("OptionContourAttribution must be enabled before contours are added")
+	}
+	if t.inputCount > 0 && len(t.cleanupReport.DroppedContours) == t.contourCount {
+		// libtess2 has no mesh to tessellate and would fail without a reason.
+		t.inputCount = 0
+		return fmt.Errorf("no contours left after cleanup: all %d were dropped", t.contourCount)
+	}
+	if err := t.inputPlane(normalPtr); err != nil {
+		return err
+	}
+
+	if t.edgeFlags && elementType == ElementPolygons {
+		elementType = ElementConnectedPolygons
+	}
+	t.outOfMemory = false
+	t.memory.enforce(true)
 	result := C.tessTesselate(t.tess, C.int(windingRule), C.int(elementType), C.int(polySize), C.int(vertexSize), normalPtr)
+	t.memory.enforce(false)
+	t.inputCount = 0
 
 	if result == 0 {
+		t.outOfMemory = t.recoverRefused()
 		status := t.getStatus()
 		return fmt.Errorf("tessellation failed with status: %v", status)
 	}
+	t.projectionBasis()
 
 	return nil
 }
@@ -296,10 +501,13 @@ func (t *Tessellator) getVertexIndices() []int {
 	ptr := unsafe.Pointer(indexPtr)
 	indices := unsafe.Slice((*C.TESSindex)(ptr), count)
 
-	// Convert to int slice
+	// Convert to int slice, mapping simplified input back to AddContour vertices
 	result := make([]int, count)
 	for i := 0; i < count; i++ {
 		result[i] = int(indices[i])
+		if t.sourceIndex != nil && result[i] >= 0 && result[i] < len(t.sourceIndex) {
+			result[i] = t.sourceIndex[result[i]]
+		}
 	}
 
 	return result
@@ -413,6 +621,9 @@ func (t *Tessellator) getStatus() Status {
 	if t == nil || t.tess == nil {
 		return StatusInvalidInput
 	}
+	if t.outOfMemory {
+		return StatusOutOfMemory
+	}
 	return Status(C.tessGetStatus(t.tess))
 }
 
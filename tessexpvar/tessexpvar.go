@@ -0,0 +1,82 @@
+// Package tessexpvar exposes tessellation metrics through the standard
+// expvar package.
+//
+// An Observer publishes a map of counters that is served with the other
+// expvar variables at /debug/vars:
+//
+//	obs := tessexpvar.New("tess")
+//	pool.SetObserver(obs)
+//
+// The map holds the number of calls and errors, the total duration in
+// nanoseconds, the total input and output sizes, and the number of calls per
+// winding rule, element type and status.
+package tessexpvar
+
+import (
+	"expvar"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+// Observer is a tess.Observer that adds every event to expvar counters. It
+// is safe for concurrent use.
+type Observer struct {
+	m *expvar.Map
+
+	calls          expvar.Int
+	errors         expvar.Int
+	nanoseconds    expvar.Int
+	inputVertices  expvar.Int
+	inputContours  expvar.Int
+	outputVertices expvar.Int
+	outputElements expvar.Int
+	windingRules   expvar.Map
+	elementTypes   expvar.Map
+	statuses       expvar.Map
+}
+
+// New creates an Observer and publishes its counters under name. Like
+// expvar.Publish, it panics if name is already in use.
+func New(name string) *Observer {
+	o := NewUnpublished()
+	expvar.Publish(name, o.m)
+	return o
+}
+
+// NewUnpublished creates an Observer whose counters are not published, for
+// embedding them in another expvar.Map with Map.
+func NewUnpublished() *Observer {
+	o := &Observer{m: new(expvar.Map)}
+	o.m.Set("calls", &o.calls)
+	o.m.Set("errors", &o.errors)
+	o.m.Set("nanoseconds", &o.nanoseconds)
+	o.m.Set("input_vertices", &o.inputVertices)
+	o.m.Set("input_contours", &o.inputContours)
+	o.m.Set("output_vertices", &o.outputVertices)
+	o.m.Set("output_elements", &o.outputElements)
+	o.m.Set("winding_rules", &o.windingRules)
+	o.m.Set("element_types", &o.elementTypes)
+	o.m.Set("statuses", &o.statuses)
+	return o
+}
+
+// Map returns the map holding the counters.
+func (o *Observer) Map() *expvar.Map {
+	return o.m
+}
+
+// Observe implements tess.Observer.
+func (o *Observer) Observe(e tess.Event) {
+	o.calls.Add(1)
+	if e.Err != nil {
+		o.errors.Add(1)
+	}
+	o.nanoseconds.Add(int64(e.Duration))
+	o.inputVertices.Add(int64(e.InputVertices))
+	o.inputContours.Add(int64(e.InputContours))
+	o.outputVertices.Add(int64(e.OutputVertices))
+	o.outputElements.Add(int64(e.OutputElements))
+	o.windingRules.Add(e.WindingRule.String(), 1)
+	o.elementTypes.Add(e.ElementType.String(), 1)
+	o.statuses.Add(e.Status.String(), 1)
+}
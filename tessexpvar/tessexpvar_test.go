@@ -0,0 +1,69 @@
+package tessexpvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	tess "github.com/mikijov/go-libtess2"
+)
+
+// counters decodes the JSON form of an expvar.Map.
+func counters(t *testing.T, m *expvar.Map) map[string]any {
+	t.Helper()
+	var v map[string]any
+	if err := json.Unmarshal([]byte(m.String()), &v); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", m.String(), err)
+	}
+	return v
+}
+
+// TestObserver tests counters after successful and failed tessellations
+func TestObserver(t *testing.T) {
+	obs := New("tessexpvar_test")
+	if expvar.Get("tessexpvar_test") != obs.Map() {
+		t.Fatal("Expected the map to be published")
+	}
+
+	tr := tess.NewTessellator()
+	defer tr.Delete()
+	tr.SetObserver(obs)
+	tr.AddContour(2, []float32{0, 0, 4, 0, 4, 4, 0, 4})
+	if _, _, err := tr.Tessellate(tess.WindingOdd, tess.ElementPolygons, 3, 2, nil); err != nil {
+		t.Fatalf("Tessellate failed: %v", err)
+	}
+	tr.AddContour(2, []float32{0, 0, 1, 0, 1, 1})
+	tr.Tessellate(tess.WindingNonZero, tess.ElementPolygons, 3, 5, nil)
+
+	v := counters(t, obs.Map())
+	want := map[string]float64{
+		"calls":           2,
+		"errors":          1,
+		"input_vertices":  7,
+		"input_contours":  2,
+		"output_vertices": 4,
+		"output_elements": 2,
+	}
+	for k, n := range want {
+		if v[k] != n {
+			t.Errorf("%s: expected %v, got %v", k, n, v[k])
+		}
+	}
+	rules := v["winding_rules"].(map[string]any)
+	if rules["Odd"] != 1.0 || rules["NonZero"] != 1.0 {
+		t.Errorf("Unexpected winding rule counts %v", rules)
+	}
+	if types := v["element_types"].(map[string]any); types["Polygons"] != 2.0 {
+		t.Errorf("Unexpected element type counts %v", types)
+	}
+	if statuses := v["statuses"].(map[string]any); statuses["OK"] != 2.0 {
+		t.Errorf("Unexpected status counts %v", statuses)
+	}
+	if v["nanoseconds"].(float64) <= 0 {
+		t.Errorf("Expected a positive duration, got %v", v["nanoseconds"])
+	}
+
+	if NewUnpublished().Map() == nil {
+		t.Error("Expected a map")
+	}
+}
@@ -0,0 +1,379 @@
+package tess
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ViolationKind classifies a problem found by Verify.
+type ViolationKind int
+
+const (
+	// ViolationOverlap reports two output triangles whose interiors overlap.
+	ViolationOverlap ViolationKind = iota
+	// ViolationOrientation reports a triangle wound against the others.
+	ViolationOrientation
+	// ViolationCoverage reports a sample point where the output disagrees
+	// with the winding rule.
+	ViolationCoverage
+	// ViolationArea reports output area that does not match the area the
+	// winding rule selects.
+	ViolationArea
+)
+
+// String returns a string representation of the violation kind.
+func (k ViolationKind) String() string {
+	switch k {
+	case ViolationOverlap:
+		return "Overlap"
+	case ViolationOrientation:
+		return "Orientation"
+	case ViolationCoverage:
+		return "Coverage"
+	case ViolationArea:
+		return "Area"
+	default:
+		return "Unknown"
+	}
+}
+
+// Violation is a single problem found by Verify. X and Y locate it in the
+// sweep plane, with coordinates along Projection.BasisS and BasisT; for 2D
+// data these are x and y, with y negated when BasisT points along -Y.
+type Violation struct {
+	Kind    ViolationKind
+	X, Y    float64
+	Message string
+}
+
+// VerifyError is returned by Verify when the result is not valid.
+type VerifyError struct {
+	Violations []Violation
+}
+
+func (e *VerifyError) Error() string {
+	const shown = 5
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "tessellation has %d violations", len(e.Violations))
+	for i, v := range e.Violations {
+		if i == shown {
+			fmt.Fprintf(&sb, "; ...")
+			break
+		}
+		fmt.Fprintf(&sb, "; %s at (%g, %g): %s", v.Kind, v.X, v.Y, v.Message)
+	}
+	return sb.String()
+}
+
+// VerifyOptions configures Verify.
+type VerifyOptions struct {
+	// Samples is the number of grid points per axis used to compare
+	// coverage with the winding rule. Defaults to 64.
+	Samples int
+	// MaxViolations stops verification after this many violations.
+	// Defaults to 100.
+	MaxViolations int
+}
+
+// Verify checks that a polygon result is a valid tessellation of contours
+// under windingRule. The contours must have r.VertexSize coordinates per
+// vertex. It checks that
+//
+//   - no two output triangles overlap,
+//   - all triangles are wound counter-clockwise around the projection normal,
+//   - on a grid of sample points, a point is covered by the output exactly
+//     when the winding number of the input selects it, and
+//   - the output area matches the area estimated from the samples.
+//
+// Points within a small distance of an input edge are not sampled. Verify
+// returns a *VerifyError listing the violations, or nil.
+func Verify(contours [][]float32, windingRule WindingRule, r *Result, opts *VerifyOptions) error {
+	if opts == nil {
+		opts = &VerifyOptions{}
+	}
+	samples, maxViolations := opts.Samples, opts.MaxViolations
+	if samples <= 0 {
+		samples = 64
+	}
+	if maxViolations <= 0 {
+		maxViolations = 100
+	}
+
+	flat, err := r.planar()
+	if err != nil {
+		return err
+	}
+	rings, err := planarContours(contours, r)
+	if err != nil {
+		return err
+	}
+	idx, err := NewTriangleIndex(flat)
+	if err != nil {
+		return err
+	}
+
+	v := verifier{idx: idx, rings: rings, rule: windingRule, max: maxViolations}
+	v.scale()
+	v.orientation()
+	v.overlaps()
+	v.coverage(samples)
+
+	if len(v.violations) > 0 {
+		return &VerifyError{Violations: v.violations}
+	}
+	return nil
+}
+
+// planar returns a copy of a polygon result with 2D vertices in the sweep
+// plane, where output polygons are counter-clockwise.
+func (r *Result) planar() (*Result, error) {
+	if r.VertexSize != 2 && r.VertexSize != 3 {
+		return nil, fmt.Errorf("vertexSize must be 2 or 3, got %d", r.VertexSize)
+	}
+	flat := *r
+	flat.VertexSize = 2
+	flat.Vertices = make([]float32, 0, 2*r.VertexCount())
+	for i := 0; i < r.VertexCount(); i++ {
+		x, y := r.project(r.Vertices[i*r.VertexSize : (i+1)*r.VertexSize])
+		flat.Vertices = append(flat.Vertices, float32(x), float32(y))
+	}
+	return &flat, nil
+}
+
+// project maps a vertex with r.VertexSize coordinates onto the sweep plane.
+// Results built by hand without a projection use x and y.
+func (r *Result) project(v []float32) (float64, float64) {
+	s, t := r.Projection.BasisS, r.Projection.BasisT
+	if s == ([3]float32{}) || t == ([3]float32{}) {
+		return float64(v[0]), float64(v[1])
+	}
+	x, y := float64(v[0])*float64(s[0])+float64(v[1])*float64(s[1]), float64(v[0])*float64(t[0])+float64(v[1])*float64(t[1])
+	if len(v) == 3 {
+		x += float64(v[2]) * float64(s[2])
+		y += float64(v[2]) * float64(t[2])
+	}
+	return x, y
+}
+
+// planarContours projects input contours into the sweep plane of the result.
+func planarContours(contours [][]float32, r *Result) ([][]vec2, error) {
+	size := r.VertexSize
+	rings := make([][]vec2, 0, len(contours))
+	for i, c := range contours {
+		if len(c)%size != 0 {
+			return nil, fmt.Errorf("contour %d: len(vertices)(%d) must be multiple of size (%d)", i, len(c), size)
+		}
+		ring := make([]vec2, 0, len(c)/size)
+		for k := 0; k+size <= len(c); k += size {
+			x, y := r.project(c[k : k+size])
+			ring = append(ring, vec2{x, y})
+		}
+		rings = append(rings, ring)
+	}
+	return rings, nil
+}
+
+type verifier struct {
+	idx        *TriangleIndex
+	rings      [][]vec2
+	rule       WindingRule
+	max        int
+	eps        float64
+	violations []Violation
+}
+
+func (v *verifier) report(kind ViolationKind, x, y float64, format string, args ...any) bool {
+	if len(v.violations) >= v.max {
+		return false
+	}
+	v.violations = append(v.violations, Violation{kind, x, y, fmt.Sprintf(format, args...)})
+	return len(v.violations) < v.max
+}
+
+// scale sets the distance tolerance from the size of the input.
+func (v *verifier) scale() {
+	box := v.bounds()
+	v.eps = 1e-5 * math.Max(math.Hypot(box.maxX-box.minX, box.maxY-box.minY), 1e-12)
+}
+
+type bounds64 struct{ minX, minY, maxX, maxY float64 }
+
+func (v *verifier) bounds() bounds64 {
+	b := bounds64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, ring := range v.rings {
+		for _, p := range ring {
+			b.minX, b.minY = math.Min(b.minX, p.x), math.Min(b.minY, p.y)
+			b.maxX, b.maxY = math.Max(b.maxX, p.x), math.Max(b.maxY, p.y)
+		}
+	}
+	return b
+}
+
+// corners returns the corners of triangle t in the projection plane.
+func (v *verifier) corners(t int) [3]vec2 {
+	ax, ay, bx, by, cx, cy := v.idx.corners(t)
+	return [3]vec2{{float64(ax), float64(ay)}, {float64(bx), float64(by)}, {float64(cx), float64(cy)}}
+}
+
+func centroid(p [3]vec2) vec2 {
+	return vec2{(p[0].x + p[1].x + p[2].x) / 3, (p[0].y + p[1].y + p[2].y) / 3}
+}
+
+// orientation checks that all triangles are counter-clockwise.
+func (v *verifier) orientation() {
+	for t := 0; t < v.idx.Len(); t++ {
+		p := v.corners(t)
+		if area := p[1].sub(p[0]).cross(p[2].sub(p[0])) / 2; area < 0 {
+			c := centroid(p)
+			if !v.report(ViolationOrientation, c.x, c.y, "triangle %d is clockwise (area %g)", t, area) {
+				return
+			}
+		}
+	}
+}
+
+// overlaps checks every pair of triangles with overlapping bounding boxes.
+func (v *verifier) overlaps() {
+	for t := 0; t < v.idx.Len(); t++ {
+		b := v.idx.triangleBox(t)
+		for _, u := range v.idx.QueryRect(b.minX, b.minY, b.maxX, b.maxY) {
+			if u <= t {
+				continue
+			}
+			p, q := v.corners(t), v.corners(u)
+			if trianglesOverlap(p, q, v.eps) {
+				c := centroid(p)
+				if !v.report(ViolationOverlap, c.x, c.y, "triangles %d and %d overlap", t, u) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// trianglesOverlap reports whether the interiors of two triangles overlap by
+// more than eps, using the separating axis theorem on the edge normals.
+func trianglesOverlap(p, q [3]vec2, eps float64) bool {
+	for _, tri := range [][3]vec2{p, q} {
+		for k := 0; k < 3; k++ {
+			d := tri[(k+1)%3].sub(tri[k])
+			l := d.length()
+			if l == 0 {
+				continue
+			}
+			n := d.perp().scale(1 / l)
+			minP, maxP := projectTriangle(p, n)
+			minQ, maxQ := projectTriangle(q, n)
+			if maxP <= minQ+eps || maxQ <= minP+eps {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func projectTriangle(p [3]vec2, n vec2) (float64, float64) {
+	a, b, c := p[0].dot(n), p[1].dot(n), p[2].dot(n)
+	return math.Min(a, math.Min(b, c)), math.Max(a, math.Max(b, c))
+}
+
+// winding returns the winding number of the input contours around p.
+func (v *verifier) winding(p vec2) int {
+	w := 0
+	for _, ring := range v.rings {
+		w += ringWinding(ring, p)
+	}
+	return w
+}
+
+// selected reports whether the winding rule selects winding number w.
+func selected(rule WindingRule, w int) bool {
+	switch rule {
+	case WindingOdd:
+		return w%2 != 0
+	case WindingNonZero:
+		return w != 0
+	case WindingPositive:
+		return w > 0
+	case WindingNegative:
+		return w < 0
+	case WindingAbsGeqTwo:
+		return w >= 2 || w <= -2
+	}
+	return false
+}
+
+// nearEdge reports whether p lies within eps of an input edge.
+func (v *verifier) nearEdge(p vec2, eps float64) bool {
+	pp := [3]float64{p.x, p.y, 0}
+	for _, ring := range v.rings {
+		for i := range ring {
+			a, b := ring[i], ring[(i+1)%len(ring)]
+			if segmentDistance(pp, [3]float64{a.x, a.y, 0}, [3]float64{b.x, b.y, 0}) < eps {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// coverage compares the output with the winding rule on a sample grid and
+// compares the output area with the area estimated from the samples.
+func (v *verifier) coverage(samples int) {
+	box := v.bounds()
+	if box.minX > box.maxX {
+		if v.idx.Len() > 0 {
+			v.report(ViolationArea, 0, 0, "output has %d triangles but there is no input", v.idx.Len())
+		}
+		return
+	}
+	w, h := box.maxX-box.minX, box.maxY-box.minY
+	cellW, cellH := w/float64(samples), h/float64(samples)
+	skip := 1e-3 * math.Max(w, h)
+
+	inside, counted := 0, 0
+	for j := 0; j < samples; j++ {
+		for i := 0; i < samples; i++ {
+			p := vec2{box.minX + (float64(i)+0.5)*cellW, box.minY + (float64(j)+0.5)*cellH}
+			want := selected(v.rule, v.winding(p))
+			if want {
+				inside++
+			}
+			counted++
+			if v.nearEdge(p, skip) {
+				continue
+			}
+			_, got := v.idx.Locate(float32(p.x), float32(p.y))
+			if got == want {
+				continue
+			}
+			msg := "covered by the output but not selected by the winding rule"
+			if want {
+				msg = "selected by the winding rule but not covered by the output"
+			}
+			if !v.report(ViolationCoverage, p.x, p.y, "%s (winding number %d)", msg, v.winding(p)) {
+				return
+			}
+		}
+	}
+
+	// Every cell straddling the boundary may be miscounted, which bounds the
+	// error of the estimate by the boundary length times the cell size.
+	perimeter := 0.0
+	for _, ring := range v.rings {
+		for i := range ring {
+			perimeter += ring[(i+1)%len(ring)].sub(ring[i]).length()
+		}
+	}
+	estimate := float64(inside) / float64(counted) * w * h
+	tolerance := perimeter*math.Max(cellW, cellH) + v.eps
+	area := 0.0
+	for t := 0; t < v.idx.Len(); t++ {
+		p := v.corners(t)
+		area += math.Abs(p[1].sub(p[0]).cross(p[2].sub(p[0]))) / 2
+	}
+	if math.Abs(area-estimate) > tolerance {
+		v.report(ViolationArea, box.minX+w/2, box.minY+h/2, "output area %g differs from the sampled area %g by more than %g", area, estimate, tolerance)
+	}
+}
@@ -0,0 +1,122 @@
+package tess
+
+import (
+	"errors"
+	"testing"
+)
+
+func tessellateContours(t *testing.T, size int, contours [][]float32, rule WindingRule) *Result {
+	t.Helper()
+	tess := NewTessellator()
+	defer tess.Delete()
+	for _, c := range contours {
+		if err := tess.AddContour(size, c); err != nil {
+			t.Fatalf("AddContour failed: %v", err)
+		}
+	}
+	r, err := tess.TessellateResult(rule, ElementPolygons, 3, size, nil)
+	if err != nil {
+		t.Fatalf("TessellateResult failed: %v", err)
+	}
+	return r
+}
+
+func violationKinds(err error) map[ViolationKind]int {
+	kinds := make(map[ViolationKind]int)
+	var verr *VerifyError
+	if errors.As(err, &verr) {
+		for _, v := range verr.Violations {
+			kinds[v.Kind]++
+		}
+	}
+	return kinds
+}
+
+// TestVerifyValid tests that libtess2 output passes verification for every winding rule
+func TestVerifyValid(t *testing.T) {
+	a := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+	b := []float32{2, 2, 6, 2, 6, 6, 2, 6}
+	cw := []float32{1, 1, 1, 5, 5, 5, 5, 1}
+	star := []float32{0, 3, 6, 3, 1, 0, 3, 5, 5, 0}
+
+	tests := []struct {
+		name     string
+		contours [][]float32
+	}{
+		{"overlap", [][]float32{a, b}},
+		{"mixed orientation", [][]float32{a, b, cw}},
+		{"clockwise", [][]float32{cw}},
+		{"star", [][]float32{star}},
+	}
+	rules := []WindingRule{WindingOdd, WindingNonZero, WindingPositive, WindingNegative, WindingAbsGeqTwo}
+
+	for _, tt := range tests {
+		for _, rule := range rules {
+			t.Run(tt.name+"/"+rule.String(), func(t *testing.T) {
+				r := tessellateContours(t, 2, tt.contours, rule)
+				if err := Verify(tt.contours, rule, r, nil); err != nil {
+					t.Errorf("Verify failed: %v", err)
+				}
+			})
+		}
+	}
+
+	// 3D input in a tilted plane.
+	tilted := []float32{0, 0, 0, 4, 0, 4, 4, 4, 4, 0, 4, 0}
+	hole := []float32{1, 1, 1, 1, 3, 1, 3, 3, 3, 3, 1, 3}
+	r := tessellateContours(t, 3, [][]float32{tilted, hole}, WindingOdd)
+	if err := Verify([][]float32{tilted, hole}, WindingOdd, r, nil); err != nil {
+		t.Errorf("Verify failed for 3D input: %v", err)
+	}
+}
+
+// TestVerifyViolations tests that corrupted results are reported
+func TestVerifyViolations(t *testing.T) {
+	square := []float32{0, 0, 4, 0, 4, 4, 0, 4}
+	hole := []float32{1, 1, 3, 1, 3, 3, 1, 3}
+	contours := [][]float32{square, hole}
+
+	fresh := func() *Result { return tessellateContours(t, 2, contours, WindingOdd) }
+
+	// Wrong winding rule: the hole is filled under NonZero.
+	if kinds := violationKinds(Verify(contours, WindingNonZero, fresh(), &VerifyOptions{MaxViolations: 10000})); kinds[ViolationCoverage] == 0 || kinds[ViolationArea] == 0 {
+		t.Errorf("Expected coverage and area violations, got %v", kinds)
+	}
+
+	// A missing triangle leaves a gap.
+	r := fresh()
+	r.Indices = r.Indices[3:]
+	if kinds := violationKinds(Verify(contours, WindingOdd, r, nil)); kinds[ViolationCoverage] == 0 {
+		t.Errorf("Expected coverage violations, got %v", kinds)
+	}
+
+	// A duplicated triangle overlaps itself.
+	r = fresh()
+	r.Indices = append(r.Indices, r.Indices[:3]...)
+	if kinds := violationKinds(Verify(contours, WindingOdd, r, nil)); kinds[ViolationOverlap] != 1 || kinds[ViolationArea] != 1 {
+		t.Errorf("Expected one overlap and one area violation, got %v", kinds)
+	}
+
+	// A flipped triangle.
+	r = fresh()
+	r.Indices[1], r.Indices[2] = r.Indices[2], r.Indices[1]
+	kinds := violationKinds(Verify(contours, WindingOdd, r, nil))
+	if kinds[ViolationOrientation] != 1 || len(kinds) != 1 {
+		t.Errorf("Expected one orientation violation, got %v", kinds)
+	}
+
+	err := Verify(contours, WindingNonZero, fresh(), &VerifyOptions{MaxViolations: 3})
+	var verr *VerifyError
+	if !errors.As(err, &verr) || len(verr.Violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %v", err)
+	}
+	for _, v := range verr.Violations {
+		if v.X < 1 || v.X > 3 || v.Y < 1 || v.Y > 3 {
+			t.Errorf("Expected violation inside the hole, got (%v, %v)", v.X, v.Y)
+		}
+	}
+
+	if err := Verify([][]float32{{0, 0, 1}}, WindingOdd, fresh(), nil); err == nil || errors.As(err, &verr) {
+		t.Errorf("Expected a plain error for invalid input, got %v", err)
+	}
+}